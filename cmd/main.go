@@ -1,11 +1,14 @@
 package main
 
 import (
+	"context"
 	"embed"
 	"encoding/json"
+	"math/rand"
 	"net"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/awslabs/aws-lambda-go-api-proxy/httpadapter"
@@ -63,6 +66,12 @@ func main() {
 	manifest.AppType = cfg.App.Type
 	mattermost.AddRoutes(r, &manifest, staticAssets, cfg.App.Secret, cfg.Debug)
 
+	// the calls are verified once for every gameday route, including the
+	// ones added when the database is configured
+	runtime := gameday.NewRuntime()
+	api := r.NewRoute().Subrouter()
+	api.Use(gameday.VerifyCalls(cfg.App), runtime.RememberCalls)
+
 	if !cfg.Database.IsEmpty() {
 		store, err := store.New(cfg.Database, logger)
 		if err != nil {
@@ -79,15 +88,16 @@ func main() {
 		}
 
 		gamedayRepo := gameday.NewRepository(store)
-		gamedaySvc := gameday.NewService(gamedayRepo)
-		gameday.AddRoutes(r, gamedaySvc, logger)
+		gamedaySvc := gameday.NewService(gamedayRepo, cfg.App.RootURL, rand.NewSource(time.Now().UnixNano()), logger)
+		gameday.AddRoutes(api, gamedaySvc, logger)
+		runtime.SetService(gamedaySvc)
 	} else {
 		//Configure Routes
-		r.HandleFunc("/api/v1/configure/form", gameday.HandleConfigureForm(logger))
-		r.HandleFunc("/api/v1/configure/submit", gameday.HandleConfigure(r, logger))
+		api.HandleFunc("/api/v1/configure/form", gameday.HandleConfigureForm(logger))
+		api.HandleFunc("/api/v1/configure/submit", gameday.HandleConfigure(api, runtime, logger))
 	}
 
-	startApp(cfg, r)
+	startApp(cfg, r, runtime)
 }
 
 func logRequest(next http.Handler) http.Handler {
@@ -99,7 +109,7 @@ func logRequest(next http.Handler) http.Handler {
 	})
 }
 
-func startApp(cfg config.Options, r *mux.Router) {
+func startApp(cfg config.Options, r *mux.Router, runtime *gameday.Runtime) {
 	if cfg.App.Type == apps.AppTypeHTTP {
 		httpListener, err := net.Listen("tcp", cfg.ListenAddress)
 		if err != nil {
@@ -113,6 +123,21 @@ func startApp(cfg config.Options, r *mux.Router) {
 		}, func(error) {
 			httpListener.Close()
 		})
+		// the nominees are chased and the members synced between the calls
+		// only when the app keeps running, the schedulers wait until the
+		// database is configured, even when it is configured at runtime
+		ackCtx, cancelAck := context.WithCancel(context.Background())
+		g.Add(func() error {
+			return gameday.RunAcknowledgementScheduler(ackCtx, runtime, logger)
+		}, func(error) {
+			cancelAck()
+		})
+		syncCtx, cancelSync := context.WithCancel(context.Background())
+		g.Add(func() error {
+			return gameday.RunMembershipSyncScheduler(syncCtx, runtime, logger)
+		}, func(error) {
+			cancelSync()
+		})
 
 		logger.WithError(g.Run()).Error("exit")
 		return
@@ -16,6 +16,10 @@ type App struct {
 	Type    apps.AppType
 	RootURL string `mapstructure:"root_url"`
 	Secret  string
+	// MattermostSiteURL the server the app calls back, never taken from the calls
+	MattermostSiteURL string `mapstructure:"mattermost_site_url"`
+	// BotAccessToken the token of the bot, the one of the verified calls when empty
+	BotAccessToken string `mapstructure:"bot_access_token"`
 }
 
 // Options config to set to run the app.
@@ -50,6 +54,9 @@ func init() {
 		"app.root_url": "http://localhost:3000",
 		"app.secret":   "secretkey",
 
+		"app.mattermost_site_url": "http://localhost:8065",
+		"app.bot_access_token":    "",
+
 		// database
 		"db.rds.secret_name":   nil, // to be supported
 		"db.idle_conns":        2,
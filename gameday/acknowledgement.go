@@ -0,0 +1,177 @@
+package gameday
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/apps/mmclient"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// EscalateToAdmin alerts the owner of the team when a nominee
+	// doesn't acknowledge the role in time
+	EscalateToAdmin = "admin"
+	// EscalateRenominate draws another member when a nominee doesn't
+	// acknowledge the role in time
+	EscalateRenominate = "renominate"
+)
+
+// acknowledgementCheckInterval how often the nominees who didn't
+// acknowledge their role are checked
+const acknowledgementCheckInterval = 5 * time.Minute
+
+// ackAction what is done for a nominee who didn't acknowledge the role
+type ackAction int
+
+const (
+	ackWait ackAction = iota
+	ackRemind
+	ackEscalate
+)
+
+// nextAckAction returns whether the nominee should be reminded or escalated
+// at the given unix time, the nominees nominated after the escalation
+// deadline are only reminded
+func nextAckAction(team Team, gameday Gameday, nominee GamedayNominee, now int64) ackAction {
+	if nominee.AcknowledgedAt > 0 || nominee.EscalatedAt > 0 || gameday.ScheduledAt <= now {
+		return ackWait
+	}
+	nominatedAt := nominee.nominatedAt()
+	deadline := gameday.ScheduledAt - team.AckEscalateBefore*int64(time.Hour/time.Second)
+	if now >= deadline && nominatedAt < deadline {
+		return ackEscalate
+	}
+	if nominee.RemindedAt == 0 && now >= nominatedAt+team.AckRemindAfter*int64(time.Hour/time.Second) {
+		return ackRemind
+	}
+	return ackWait
+}
+
+// isAckEscalation returns true when the escalation is known
+func isAckEscalation(escalation string) bool {
+	return escalation == EscalateToAdmin || escalation == EscalateRenominate
+}
+
+// ackEscalationNames the comma separated escalations
+func ackEscalationNames() string {
+	return strings.Join([]string{EscalateToAdmin, EscalateRenominate}, ", ")
+}
+
+// Acknowledge responsible to record that the acting user acknowledged
+// the roles they are nominated for on the gameday
+func (s *Service) Acknowledge(ctx *apps.Context, gamedayID string) (Gameday, []GamedayNominee, error) {
+	gameday, nominees, err := s.getActiveGameday(gamedayID)
+	if err != nil {
+		return Gameday{}, nil, err
+	}
+	var acknowledged []GamedayNominee
+	for _, n := range nominees {
+		if n.UserID != ctx.ActingUserID {
+			continue
+		}
+		if n.AcknowledgedAt == 0 {
+			if err := s.repo.AcknowledgeNominee(n.ID); err != nil {
+				return Gameday{}, nil, errors.Wrapf(err, "failed to acknowledge the nomination for GamedayID: %s", gamedayID)
+			}
+		}
+		acknowledged = append(acknowledged, n)
+	}
+	if len(acknowledged) == 0 {
+		return Gameday{}, nil, errors.Errorf("you aren't nominated for gameday: %s", gameday.Title)
+	}
+	return gameday, acknowledged, nil
+}
+
+// CheckAcknowledgements responsible to remind the nominees of the scheduled
+// gamedays who didn't acknowledge their role and to escalate when the
+// gameday gets close
+func (s *Service) CheckAcknowledgements(ctx *apps.Context, now time.Time) error {
+	gamedays, err := s.repo.ListGamedaysByState([]string{string(GamedayScheduledState)})
+	if err != nil {
+		return errors.Wrap(err, "failed to get scheduled gamedays in repository")
+	}
+	for _, g := range gamedays {
+		if g.ScheduledAt <= now.Unix() {
+			continue
+		}
+		gameday, nominees, err := s.GetGameday(g.ID)
+		if err != nil {
+			return err
+		}
+		for _, n := range nominees {
+			switch nextAckAction(gameday.Team, gameday, n, now.Unix()) {
+			case ackRemind:
+				if err := s.remindNominee(ctx, gameday, n); err != nil {
+					return err
+				}
+			case ackEscalate:
+				if err := s.escalateNominee(ctx, gameday, n); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// remindNominee sends the acknowledgement prompt to the nominee again
+func (s *Service) remindNominee(ctx *apps.Context, gameday Gameday, nominee GamedayNominee) error {
+	if err := s.repo.MarkNomineeReminded(nominee.ID); err != nil {
+		return errors.Wrapf(err, "failed to remind the nominee for GamedayID: %s", gameday.ID)
+	}
+	msg := fmt.Sprintf("Reminder: you are the **%s** for gameday: _**%s**_ scheduled at: _**%s**_, please acknowledge your role", getNomineeRole(nominee), gameday.Title, formatLocalTime(gameday.ScheduledAt, s.timezoneOf(gameday.TeamID, nominee.UserID)))
+	_, _ = mmclient.AsBot(ctx).DMPost(nominee.UserID, newAcknowledgementPost(ctx.AppID, gameday, msg))
+	return nil
+}
+
+// escalateNominee draws another member or alerts the admins of the team
+// when the nominee didn't acknowledge the role in time
+func (s *Service) escalateNominee(ctx *apps.Context, gameday Gameday, nominee GamedayNominee) error {
+	if err := s.repo.MarkNomineeEscalated(nominee.ID); err != nil {
+		return errors.Wrapf(err, "failed to escalate the nominee for GamedayID: %s", gameday.ID)
+	}
+	adminIDs, err := s.listTeamAdminIDs(gameday.Team)
+	if err != nil {
+		return err
+	}
+	role := getNomineeRole(nominee)
+	msg := fmt.Sprintf("@%s didn't acknowledge being the **%s** for gameday: _**%s**_ scheduled at: _**%s**_. You may want to re-nominate.", nominee.Label, role, gameday.Title, time.Unix(gameday.ScheduledAt, 0).Format(timeLayout))
+	if gameday.Team.AckEscalation == EscalateRenominate {
+		// the admins of the team are alerted when nobody else can be drawn
+		replacement, err := s.Renominate(ctx, gameday.ID, nominee.Role, nominee.UserID)
+		if err == nil {
+			msg = fmt.Sprintf("@%s didn't acknowledge being the **%s** for gameday: _**%s**_, @%s was nominated instead", nominee.Label, role, gameday.Title, replacement.Label)
+		}
+	}
+	for _, id := range adminIDs {
+		mmclient.AsBot(ctx).DM(id, msg)
+	}
+	return nil
+}
+
+// RunAcknowledgementScheduler checks the acknowledgements of the nominees
+// periodically until the context is done
+func RunAcknowledgementScheduler(ctx context.Context, rt *Runtime, logger logrus.FieldLogger) error {
+	ticker := time.NewTicker(acknowledgementCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-ticker.C:
+			svc, botCtx := rt.scheduled()
+			if svc == nil {
+				logger.Debug("skipping the acknowledgement checks until the app is configured and receives a call")
+				continue
+			}
+			if err := svc.CheckAcknowledgements(botCtx, now); err != nil {
+				logger.WithError(err).Error("failed to check the acknowledgements of the nominees")
+			}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package gameday
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+)
+
+func TestNextAckAction(t *testing.T) {
+	const hour = int64(3600)
+	team := Team{AckRemindAfter: 24, AckEscalateBefore: 12}
+	gameday := Gameday{ScheduledAt: 100 * hour}
+	// nominated at hour 10, in milliseconds
+	nominee := GamedayNominee{CreatedAt: 10 * hour * 1000}
+
+	tests := []struct {
+		name    string
+		nominee GamedayNominee
+		now     int64
+		want    ackAction
+	}{
+		{"before the reminder", nominee, 33 * hour, ackWait},
+		{"reminder", nominee, 34 * hour, ackRemind},
+		{"already reminded", GamedayNominee{CreatedAt: nominee.CreatedAt, RemindedAt: 34 * hour}, 50 * hour, ackWait},
+		{"escalation", GamedayNominee{CreatedAt: nominee.CreatedAt, RemindedAt: 34 * hour}, 88 * hour, ackEscalate},
+		{"acknowledged", GamedayNominee{CreatedAt: nominee.CreatedAt, AcknowledgedAt: 11 * hour}, 88 * hour, ackWait},
+		{"already escalated", GamedayNominee{CreatedAt: nominee.CreatedAt, EscalatedAt: 88 * hour}, 90 * hour, ackWait},
+		{"renominated after the deadline", GamedayNominee{CreatedAt: nominee.CreatedAt, UpdatedAt: 89 * hour * 1000}, 90 * hour, ackWait},
+		{"gameday started", nominee, 100 * hour, ackWait},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextAckAction(team, gameday, tt.nominee, tt.now); got != tt.want {
+				t.Errorf("wrong action: got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscalateNominee(t *testing.T) {
+	svc := newTestService(t)
+	server, recipients := newDirectMessagesRecorder(t)
+	teamID, gamedayID := newTestTeam(t, svc, "alice")
+	for _, admin := range []string{"bob", "owner"} {
+		if err := svc.repo.CreateTeamAdmin(TeamAdmin{TeamID: teamID, UserID: admin, Label: admin}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gameday, nominees, err := svc.GetGameday(gamedayID)
+	if err != nil || len(nominees) != 1 {
+		t.Fatal(err, nominees)
+	}
+
+	ctx := &apps.Context{MattermostSiteURL: server.URL}
+	ctx.BotAccessToken = "bot-token"
+	if err := svc.escalateNominee(ctx, gameday, nominees[0]); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := recipients(), []string{"owner", "bob"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the owner and the admins to be alerted once, got %v want %v", got, want)
+	}
+}
@@ -3,8 +3,13 @@ package gameday
 import (
 	"errors"
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
+	// the timezones of the member profiles don't depend on the host
+	_ "time/tzdata"
+	"unicode"
 )
 
 // ConfigureDTO the data transfer object for
@@ -50,18 +55,328 @@ func (c MemberDTO) Validate() error {
 }
 
 // CreateTeamDTO the data transfer object for
-// creating a new team with a member
+// creating a new team with optional members
 type CreateTeamDTO struct {
-	Name   string
-	Member MemberDTO
+	Name    string `json:"name"`
+	Members string `json:"members"`
 }
 
 // Validate check if the DTO has the required values
 func (c CreateTeamDTO) Validate() error {
-	if c.Name == "" {
+	if strings.TrimSpace(c.Name) == "" {
 		return errors.New("failed: missing required field name")
 	}
-	return c.Member.Validate()
+	return nil
+}
+
+// AddMembersDTO the data transfer object for
+// adding several members to a team
+type AddMembersDTO struct {
+	Team    LookupDTO `json:"team"`
+	Members string    `json:"members"`
+}
+
+// Validate check if the DTO has the required values
+func (a AddMembersDTO) Validate() error {
+	if a.Team.Value == "" {
+		return errors.New("failed: missing required field team")
+	}
+	if len(parseUsernames(a.Members)) == 0 {
+		return errors.New("failed: missing required field members")
+	}
+	return nil
+}
+
+// parseUsernames splits the usernames separated by spaces or commas,
+// the leading @ is dropped and the duplicates are removed
+func parseUsernames(s string) []string {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+	seen := make(map[string]bool)
+	var usernames []string
+	for _, f := range fields {
+		username := strings.ToLower(strings.TrimPrefix(f, "@"))
+		if username == "" || seen[username] {
+			continue
+		}
+		seen[username] = true
+		usernames = append(usernames, username)
+	}
+	return usernames
+}
+
+// TeamMemberDTO the data transfer object for
+// removing a member of a team
+type TeamMemberDTO struct {
+	Team   LookupDTO `json:"team"`
+	Member MemberDTO `json:"member"`
+}
+
+// Validate check if the DTO has the required values
+func (t TeamMemberDTO) Validate() error {
+	if t.Team.Value == "" {
+		return errors.New("failed: missing required field team")
+	}
+	return t.Member.Validate()
+}
+
+// RenameTeamDTO the data transfer object for
+// renaming a team
+type RenameTeamDTO struct {
+	Team LookupDTO `json:"team"`
+	Name string    `json:"name"`
+}
+
+// Validate check if the DTO has the required values
+func (r RenameTeamDTO) Validate() error {
+	if r.Team.Value == "" {
+		return errors.New("failed: missing required field team")
+	}
+	if strings.TrimSpace(r.Name) == "" {
+		return errors.New("failed: missing required field name")
+	}
+	return nil
+}
+
+// DeleteTeamDTO the data transfer object for deleting a
+// team, forced when the team has scheduled gamedays
+type DeleteTeamDTO struct {
+	Team  LookupDTO `json:"team"`
+	Force bool      `json:"force"`
+}
+
+// Validate check if the DTO has the required values
+func (d DeleteTeamDTO) Validate() error {
+	if d.Team.Value == "" {
+		return errors.New("failed: missing required field team")
+	}
+	return nil
+}
+
+// ListTeamsDTO the data transfer object for
+// listing the teams, only the named team when set
+type ListTeamsDTO struct {
+	Name string `json:"name"`
+}
+
+// ShowTeamDTO the data transfer object for
+// showing the details of a team
+type ShowTeamDTO struct {
+	Name string `json:"name"`
+}
+
+// Validate check if the DTO has the required values
+func (s ShowTeamDTO) Validate() error {
+	if strings.TrimSpace(s.Name) == "" {
+		return errors.New("failed: missing required field name")
+	}
+	return nil
+}
+
+// SyncTeamDTO the data transfer object for
+// syncing the members of a team
+type SyncTeamDTO struct {
+	Name string `json:"name"`
+}
+
+// Validate check if the DTO has the required values
+func (s SyncTeamDTO) Validate() error {
+	if strings.TrimSpace(s.Name) == "" {
+		return errors.New("failed: missing required field name")
+	}
+	return nil
+}
+
+// LinkTeamDTO the data transfer object for linking a team
+// to the channel, group or Mattermost team its members are synced from
+type LinkTeamDTO struct {
+	Team           LookupDTO `json:"team"`
+	Channel        LookupDTO `json:"channel"`
+	Group          string    `json:"group"`
+	MattermostTeam string    `json:"mattermost_team"`
+}
+
+// Validate check if the DTO has the required values
+func (l LinkTeamDTO) Validate() error {
+	if l.Team.Value == "" {
+		return errors.New("failed: missing required field team")
+	}
+	sources := 0
+	for _, s := range []string{l.Channel.Value, strings.TrimSpace(l.Group), strings.TrimSpace(l.MattermostTeam)} {
+		if s != "" {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return errors.New("failed: set exactly one of the fields channel, group or mattermost_team")
+	}
+	return nil
+}
+
+// UnlinkTeamDTO the data transfer object for
+// stopping the sync of a team
+type UnlinkTeamDTO struct {
+	Team LookupDTO `json:"team"`
+}
+
+// Validate check if the DTO has the required values
+func (u UnlinkTeamDTO) Validate() error {
+	if u.Team.Value == "" {
+		return errors.New("failed: missing required field team")
+	}
+	return nil
+}
+
+// ConfigureTeamDTO the data transfer object for
+// the settings of a team
+type ConfigureTeamDTO struct {
+	Team           LookupDTO `json:"team"`
+	Strategy       LookupDTO `json:"strategy"`
+	RemindAfter    string    `json:"remind_after"`
+	EscalateBefore string    `json:"escalate_before"`
+	Escalation     LookupDTO `json:"escalation"`
+}
+
+// Validate check if the DTO has the required values, at least
+// one of the settings is required
+func (c ConfigureTeamDTO) Validate() error {
+	if c.Team.Value == "" {
+		return errors.New("failed: missing required field team")
+	}
+	if c.Strategy.Value == "" && c.RemindAfter == "" && c.EscalateBefore == "" && c.Escalation.Value == "" {
+		return errors.New("failed: missing required field strategy")
+	}
+	return nil
+}
+
+// parseHours returns the hours of a setting, the current
+// hours when the setting isn't provided
+func parseHours(name, hours string, current int64) (int64, error) {
+	if hours == "" {
+		return current, nil
+	}
+	result, err := strconv.ParseInt(hours, 10, 64)
+	if err != nil || result < 0 {
+		return 0, fmt.Errorf("failed: `%s` should be a number of hours", name)
+	}
+	return result, nil
+}
+
+// MemberAttributesDTO the data transfer object for the
+// experience level and the skills of a team member
+type MemberAttributesDTO struct {
+	Team   LookupDTO `json:"team"`
+	Member MemberDTO `json:"member"`
+	Level  LookupDTO `json:"level"`
+	Skills string    `json:"skills"`
+}
+
+// Validate check if the DTO has the required values
+func (m MemberAttributesDTO) Validate() error {
+	if m.Team.Value == "" {
+		return errors.New("failed: missing required field team")
+	}
+	if err := m.Member.Validate(); err != nil {
+		return err
+	}
+	if m.Level.Value == "" && m.Skills == "" {
+		return errors.New("failed: missing field `level` or `skills`")
+	}
+	if m.Level.Value != "" && ExperienceLevel(m.Level.Value).rank() < 0 {
+		return fmt.Errorf("failed: unknown level `%s`, expected one of: %s", m.Level.Value, levelNames())
+	}
+	return nil
+}
+
+// ProfileDTO the data transfer object for the profile of the
+// acting user, the fields which aren't set are kept
+type ProfileDTO struct {
+	Timezone      string    `json:"timezone"`
+	Notifications LookupDTO `json:"notifications"`
+}
+
+// Validate check if the DTO has valid values
+func (p ProfileDTO) Validate() error {
+	if tz := strings.TrimSpace(p.Timezone); tz != "" {
+		if _, err := time.LoadLocation(tz); err != nil {
+			return fmt.Errorf("failed: unknown timezone `%s`, expected an IANA timezone like Europe/Paris", tz)
+		}
+	}
+	if p.Notifications.Value != "" && !isNotificationPreference(p.Notifications.Value) {
+		return fmt.Errorf("failed: unknown notifications `%s`, expected one of: %s", p.Notifications.Value, notificationPreferenceNames())
+	}
+	return nil
+}
+
+// apply returns the profile updated with the fields which are set
+func (p ProfileDTO) apply(profile MemberProfile) MemberProfile {
+	if tz := strings.TrimSpace(p.Timezone); tz != "" {
+		profile.Timezone = tz
+	}
+	if p.Notifications.Value != "" {
+		profile.Notifications = NotificationPreference(p.Notifications.Value)
+	}
+	return profile
+}
+
+// RoleDTO the data transfer object for
+// adding or removing a role of a team
+type RoleDTO struct {
+	Team  LookupDTO `json:"team"`
+	Name  string    `json:"name"`
+	Label string    `json:"label"`
+	Count string    `json:"count"`
+	Rules string    `json:"rules"`
+}
+
+// Validate check if the DTO has the required values, the count
+// and the rules are only checked when the role is added
+func (r RoleDTO) Validate(add bool) error {
+	if r.Team.Value == "" {
+		return errors.New("failed: missing required field team")
+	}
+	if err := validateRoleName(r.Name); err != nil {
+		return err
+	}
+	if !add {
+		return nil
+	}
+	if _, err := r.ParseCount(); err != nil {
+		return err
+	}
+	if _, err := parseRoleRules(r.Rules); err != nil {
+		return fmt.Errorf("failed: %s", err)
+	}
+	return nil
+}
+
+// ParseCount returns the number of members to nominate
+// for the role, one when it isn't provided
+func (r RoleDTO) ParseCount() (int, error) {
+	if r.Count == "" {
+		return 1, nil
+	}
+	count, err := strconv.Atoi(r.Count)
+	if err != nil || count <= 0 {
+		return 0, errors.New("failed: `count` should be a positive number")
+	}
+	return count, nil
+}
+
+// roleNamePattern the names of the roles are used in the comma
+// separated roles of the gamedays and templates
+var roleNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// validateRoleName checks the role name is a lowercase identifier
+func validateRoleName(name string) error {
+	if name == "" {
+		return errors.New("failed: missing required field `role`")
+	}
+	if !roleNamePattern.MatchString(name) {
+		return fmt.Errorf("failed: invalid role `%s`, expected up to 32 lowercase letters, digits, `-` or `_`", name)
+	}
+	return nil
 }
 
 // LookupTeamDTO lookup label value data transfer
@@ -77,7 +392,14 @@ type GamedayDTO struct {
 	Name        string
 	Team        LookupDTO
 	ScheduledAt ScheduledAtTime `json:"schedule_at"`
+	Template    LookupDTO       `json:"template"`
+	DryRun      bool            `json:"dry_run"`
 	State       GamedayState
+	Reason      string
+	Scenarios   string `json:"-"`
+	Checklist   string `json:"-"`
+	Duration    int64  `json:"-"`
+	Roles       string `json:"-"`
 }
 
 // Validate check if the DTO has the required values
@@ -123,8 +445,311 @@ func (ct *ScheduledAtTime) String() string {
 	return fmt.Sprintf("%q", t.Format(timeLayout))
 }
 
+// TemplateDTO the data transfer object for
+// creating or editing a gameday template
+type TemplateDTO struct {
+	Name      string    `json:"name"`
+	Title     string    `json:"title"`
+	Team      LookupDTO `json:"team"`
+	Scenarios string    `json:"scenarios"`
+	Checklist string    `json:"checklist"`
+	Duration  string    `json:"duration"`
+	Roles     string    `json:"roles"`
+}
+
+// Validate check if the DTO has the required values, the
+// title and the team are only required on creation
+func (t TemplateDTO) Validate(create bool) error {
+	if t.Name == "" {
+		return errors.New("failed: missing required field name")
+	}
+	if create && t.Title == "" {
+		return errors.New("failed: missing required field title")
+	}
+	if create && t.Team.Value == "" {
+		return errors.New("failed: missing required field team ID")
+	}
+	if _, err := t.ParseDuration(); err != nil {
+		return err
+	}
+	if strings.TrimSpace(t.Roles) != "" {
+		for _, role := range parseRoles(t.Roles) {
+			if err := validateRoleName(string(role)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ParseDuration returns the duration of the gameday in minutes
+// or zero when it isn't provided
+func (t TemplateDTO) ParseDuration() (int64, error) {
+	if t.Duration == "" {
+		return 0, nil
+	}
+	duration, err := strconv.ParseInt(t.Duration, 10, 64)
+	if err != nil || duration <= 0 {
+		return 0, errors.New("failed: `duration` should be a positive number of minutes")
+	}
+	return duration, nil
+}
+
+// CloneGamedayDTO the data transfer object for
+// cloning an existing gameday
+type CloneGamedayDTO struct {
+	ID          LookupDTO       `json:"id"`
+	ScheduledAt ScheduledAtTime `json:"schedule_at"`
+}
+
+// Validate check if the DTO has the required values
+func (c CloneGamedayDTO) Validate() error {
+	if c.ID.Value == "" {
+		return errors.New("failed: missing required field `id`")
+	}
+	if time.Time(c.ScheduledAt).IsZero() {
+		return errors.New("failed: missing required field scheduled_at")
+	}
+	return nil
+}
+
+// RescheduleDTO the data transfer object for
+// moving a gameday to another time
+type RescheduleDTO struct {
+	ID          LookupDTO       `json:"id"`
+	ScheduledAt ScheduledAtTime `json:"schedule_at"`
+}
+
+// Validate check if the DTO has the required values
+func (r RescheduleDTO) Validate() error {
+	if r.ID.Value == "" {
+		return errors.New("failed: missing required field `id`")
+	}
+	if time.Time(r.ScheduledAt).IsZero() {
+		return errors.New("failed: missing required field scheduled_at")
+	}
+	return nil
+}
+
+// AwayDTO the data transfer object for
+// a period when the user is away
+type AwayDTO struct {
+	From ScheduledAtTime `json:"from"`
+	To   ScheduledAtTime `json:"to"`
+}
+
+// Validate check if the DTO has the required values
+func (a AwayDTO) Validate() error {
+	if time.Time(a.From).IsZero() {
+		return errors.New("failed: missing required field from")
+	}
+	if time.Time(a.To).IsZero() {
+		return errors.New("failed: missing required field to")
+	}
+	return nil
+}
+
+// ExemptionDTO the data transfer object for
+// taking a team member out of the rotation
+type ExemptionDTO struct {
+	Team   LookupDTO       `json:"team"`
+	Member MemberDTO       `json:"member"`
+	Reason string          `json:"reason"`
+	Until  ScheduledAtTime `json:"until"`
+}
+
+// Validate check if the DTO has the required values, the
+// reason and the expiry are only required when it is added
+func (e ExemptionDTO) Validate(add bool) error {
+	if e.Team.Value == "" {
+		return errors.New("failed: missing required field team")
+	}
+	if err := e.Member.Validate(); err != nil {
+		return err
+	}
+	if !add {
+		return nil
+	}
+	if e.Reason == "" {
+		return errors.New("failed: missing required field reason")
+	}
+	if time.Time(e.Until).IsZero() {
+		return errors.New("failed: missing required field until")
+	}
+	return nil
+}
+
+// ImportDTO the data transfer object for
+// importing teams, members and gamedays
+type ImportDTO struct {
+	Format  LookupDTO `json:"format"`
+	Content string    `json:"content"`
+	DryRun  bool      `json:"dry_run"`
+}
+
+// Validate check if the DTO has the required values
+func (i ImportDTO) Validate() error {
+	if i.Format.Value == "" {
+		return errors.New("failed: missing required field format")
+	}
+	if strings.TrimSpace(i.Content) == "" {
+		return errors.New("failed: missing required field content")
+	}
+	return nil
+}
+
+// CalendarLinkDTO the data transfer object for
+// the calendar link of a team or of the acting user
+type CalendarLinkDTO struct {
+	Team LookupDTO `json:"team"`
+}
+
+// permission the permission to change the link, the link of a team is
+// changed by its admins
+func (c CalendarLinkDTO) permission() Permission {
+	if c.Team.Value != "" {
+		return PermissionTeamAdmin
+	}
+	return PermissionUser
+}
+
+// StatsDTO the data transfer object for
+// the statistics of a team
+type StatsDTO struct {
+	Team LookupDTO `json:"team"`
+}
+
+// Validate check if the DTO has the required values
+func (s StatsDTO) Validate() error {
+	if s.Team.Value == "" {
+		return errors.New("failed: missing required field team ID")
+	}
+	return nil
+}
+
+// SubscriptionDTO the data transfer object for
+// subscribing a channel to the gameday events of a team
+type SubscriptionDTO struct {
+	Team   LookupDTO `json:"team"`
+	Events string    `json:"events"`
+}
+
+// Validate check if the DTO has the required values
+func (s SubscriptionDTO) Validate() error {
+	if s.Team.Value == "" {
+		return errors.New("failed: missing required field team ID")
+	}
+	for _, event := range s.ParseEvents() {
+		if !isGamedayEvent(event) {
+			return fmt.Errorf("failed: unsupported event `%s`", event)
+		}
+	}
+	return nil
+}
+
+// ParseEvents returns the comma separated events or every
+// event when none is provided
+func (s SubscriptionDTO) ParseEvents() []string {
+	var events []string
+	for _, e := range strings.Split(s.Events, ",") {
+		if e = strings.ToLower(strings.TrimSpace(e)); e != "" {
+			events = append(events, e)
+		}
+	}
+	if len(events) > 0 {
+		return events
+	}
+	for _, e := range gamedayEvents {
+		events = append(events, string(e))
+	}
+	return events
+}
+
+func isGamedayEvent(event string) bool {
+	for _, e := range gamedayEvents {
+		if string(e) == event {
+			return true
+		}
+	}
+	return false
+}
+
+// UnsubscribeDTO the data transfer object for
+// unsubscribing a channel from a team
+type UnsubscribeDTO struct {
+	Team LookupDTO `json:"team"`
+}
+
+// Validate check if the DTO has the required values
+func (u UnsubscribeDTO) Validate() error {
+	if u.Team.Value == "" {
+		return errors.New("failed: missing required field team ID")
+	}
+	return nil
+}
+
+// AttendanceDTO the data transfer object for
+// taking the attendance of a member
+type AttendanceDTO struct {
+	ID     LookupDTO `json:"id"`
+	Member MemberDTO `json:"member"`
+}
+
+// Validate check if the DTO has the required values
+func (a AttendanceDTO) Validate() error {
+	if a.ID.Value == "" {
+		return errors.New("failed: missing required field `id`")
+	}
+	return a.Member.Validate()
+}
+
+// RenominateDTO the data transfer object for
+// drawing a new member for a role of a gameday, the member
+// is only needed when several members hold the role
+type RenominateDTO struct {
+	ID     LookupDTO `json:"id"`
+	Role   LookupDTO `json:"role"`
+	Member MemberDTO `json:"member"`
+}
+
+// Validate check if the DTO has the required values
+func (r RenominateDTO) Validate() error {
+	if r.ID.Value == "" {
+		return errors.New("failed: missing required field `id`")
+	}
+	return validateNomineeRole(r.Role.Value)
+}
+
+// SwapNomineeDTO the data transfer object for
+// replacing the nominee of a role with a volunteer, the member
+// is only needed when several members hold the role
+type SwapNomineeDTO struct {
+	ID     LookupDTO `json:"id"`
+	Role   LookupDTO `json:"role"`
+	Member MemberDTO `json:"member"`
+	With   MemberDTO `json:"with"`
+}
+
+// Validate check if the DTO has the required values
+func (s SwapNomineeDTO) Validate() error {
+	if s.ID.Value == "" {
+		return errors.New("failed: missing required field `id`")
+	}
+	if err := validateNomineeRole(s.Role.Value); err != nil {
+		return err
+	}
+	return s.With.Validate()
+}
+
+// validateNomineeRole checks the role is a valid role name, the
+// gameday decides if it is one of the nominated roles
+func validateNomineeRole(role string) error {
+	return validateRoleName(role)
+}
+
 // UpdateGameDayStateDTO the data transfer object for
 // to update the state
 type UpdateGameDayStateDTO struct {
-	ID LookupDTO `json:"id"`
+	ID     LookupDTO `json:"id"`
+	Reason string    `json:"reason"`
 }
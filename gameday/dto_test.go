@@ -0,0 +1,52 @@
+package gameday
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTeamSlug(t *testing.T) {
+	tests := map[string]string{
+		"SRE":               "sre",
+		"  Data  Platform ": "data-platform",
+		"sre":               "sre",
+	}
+	for name, want := range tests {
+		if got := teamSlug(name); got != want {
+			t.Errorf("wrong slug for %q: got %q want %q", name, got, want)
+		}
+	}
+}
+
+func TestParseUsernames(t *testing.T) {
+	got := parseUsernames("@alice, bob @Alice\tcarol,,")
+	want := []string{"alice", "bob", "carol"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong usernames: got %v want %v", got, want)
+	}
+	if got := parseUsernames(" , @ "); len(got) != 0 {
+		t.Errorf("expected no usernames, got %v", got)
+	}
+}
+
+func TestProfileDTO(t *testing.T) {
+	profile := MemberProfile{Timezone: "UTC", Notifications: NotifyAll}
+	dto := ProfileDTO{Timezone: " Europe/Paris ", Notifications: LookupDTO{Value: "nominations"}}
+	if err := dto.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := dto.apply(profile)
+	want := MemberProfile{Timezone: "Europe/Paris", Notifications: NotifyNominations}
+	if got != want {
+		t.Errorf("wrong profile: got %+v want %+v", got, want)
+	}
+
+	for _, dto := range []ProfileDTO{
+		{Timezone: "Mars/Olympus"},
+		{Notifications: LookupDTO{Value: "never"}},
+	} {
+		if err := dto.Validate(); err == nil {
+			t.Errorf("expected an error for %+v", dto)
+		}
+	}
+}
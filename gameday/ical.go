@@ -0,0 +1,247 @@
+package gameday
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// calendarProductID the product identifier of the iCalendar feeds
+const calendarProductID = "-//Mattermost//Chaos Engine//EN"
+
+// calendarTimeLayout the UTC date time format of iCalendar
+const calendarTimeLayout = "20060102T150405Z"
+
+// calendarLocalTimeLayout the floating or TZID relative date time format of iCalendar
+const calendarLocalTimeLayout = "20060102T150405"
+
+// calendarDateLayout the date format of iCalendar
+const calendarDateLayout = "20060102"
+
+// calendarLineLength the max length of a content line before it is folded
+const calendarLineLength = 75
+
+// calendarStatus maps the state of a gameday to the status of its event
+var calendarStatus = map[GamedayState]string{
+	GamedayScheduledState:  "CONFIRMED",
+	GamedayInProgressState: "CONFIRMED",
+	GamedayCompletedState:  "CONFIRMED",
+	GamedayCancelledState:  "CANCELLED",
+}
+
+// writeCalendar writes the gamedays as the events of an iCalendar feed
+func writeCalendar(w io.Writer, name string, gamedays []Gameday, now time.Time) error {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:" + calendarProductID,
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:" + escapeCalendarText(name),
+	}
+	for _, g := range gamedays {
+		start := time.Unix(g.ScheduledAt, 0)
+		modified := g.UpdatedAt
+		if modified == 0 {
+			modified = g.CreatedAt
+		}
+
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			fmt.Sprintf("UID:%s@chaos-engine", g.ID),
+			"DTSTAMP:"+now.UTC().Format(calendarTimeLayout),
+			"DTSTART:"+start.UTC().Format(calendarTimeLayout),
+			"DTEND:"+time.Unix(g.endsAt(), 0).UTC().Format(calendarTimeLayout),
+			"LAST-MODIFIED:"+time.Unix(0, modified*int64(time.Millisecond)).UTC().Format(calendarTimeLayout),
+			fmt.Sprintf("SEQUENCE:%d", g.Sequence),
+			"SUMMARY:"+escapeCalendarText(g.Title),
+			"DESCRIPTION:"+escapeCalendarText(getCalendarDescription(g)),
+			"CATEGORIES:"+escapeCalendarText(g.Team.Name),
+			"STATUS:"+calendarStatus[g.State],
+			"END:VEVENT",
+		)
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		if _, err := io.WriteString(w, foldCalendarLine(line)+"\r\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getCalendarDescription the description of the event of a gameday
+func getCalendarDescription(gameday Gameday) string {
+	txt := fmt.Sprintf("Team: %s\nState: %s", gameday.Team.Name, gameday.State)
+	if gameday.Reason != "" {
+		txt += fmt.Sprintf("\nReason: %s", gameday.Reason)
+	}
+	if gameday.Scenarios != "" {
+		txt += fmt.Sprintf("\nScenarios: %s", gameday.Scenarios)
+	}
+	return txt
+}
+
+// escapeCalendarText escapes the special characters of a text value
+func escapeCalendarText(txt string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(txt)
+}
+
+// foldCalendarLine splits the lines longer than 75 octets, the next lines
+// start with a space as the specification requires
+func foldCalendarLine(line string) string {
+	var b strings.Builder
+	length := 0
+	for _, r := range line {
+		size := len(string(r))
+		if length+size > calendarLineLength {
+			b.WriteString("\r\n ")
+			length = 1
+		}
+		b.WriteRune(r)
+		length += size
+	}
+	return b.String()
+}
+
+// calendarProperty a content line of an iCalendar document
+type calendarProperty struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// readCalendar reads the events of an iCalendar document as import records,
+// the team is read from the categories and the members from the attendees
+func readCalendar(content string, report *ImportReport) []importRecord {
+	var records []importRecord
+	var record *importRecord
+	for _, line := range unfoldCalendarLines(content) {
+		prop, ok := parseCalendarProperty(line.Text)
+		if !ok {
+			report.addError(line.Number, "invalid content line")
+			continue
+		}
+		switch {
+		case prop.Name == "BEGIN" && strings.EqualFold(prop.Value, "VEVENT"):
+			record = &importRecord{Line: line.Number}
+		case record == nil:
+			continue
+		case prop.Name == "END" && strings.EqualFold(prop.Value, "VEVENT"):
+			records = append(records, *record)
+			record = nil
+		case prop.Name == "SUMMARY":
+			record.Title = unescapeCalendarText(prop.Value)
+		case prop.Name == "CATEGORIES":
+			record.Team = strings.TrimSpace(strings.SplitN(unescapeCalendarText(prop.Value), ",", 2)[0])
+		case prop.Name == "ATTENDEE":
+			if member := strings.TrimPrefix(prop.Params["CN"], "@"); member != "" {
+				record.Members = append(record.Members, member)
+			}
+		case prop.Name == "DTSTART":
+			scheduledAt, err := parseCalendarTime(prop)
+			if err != nil {
+				report.addError(line.Number, "invalid DTSTART %q", prop.Value)
+				continue
+			}
+			record.ScheduledAt = scheduledAt
+		}
+	}
+	return records
+}
+
+// calendarLine an unfolded content line with the number of its first line
+type calendarLine struct {
+	Number int
+	Text   string
+}
+
+// unfoldCalendarLines joins the lines which were folded, the next lines
+// start with a space or a tab
+func unfoldCalendarLines(content string) []calendarLine {
+	var lines []calendarLine
+	for i, text := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		if (strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1].Text += text[1:]
+			continue
+		}
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		lines = append(lines, calendarLine{Number: i + 1, Text: text})
+	}
+	return lines
+}
+
+// parseCalendarProperty parses a content line, `NAME;PARAM=VALUE:VALUE`,
+// the quoted parameter values may contain the separators
+func parseCalendarProperty(line string) (calendarProperty, bool) {
+	var parts []string
+	quoted := false
+	start := 0
+	for i, r := range line {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case quoted:
+		case r == ';':
+			parts = append(parts, line[start:i])
+			start = i + 1
+		case r == ':':
+			parts = append(parts, line[start:i])
+			if parts[0] == "" {
+				return calendarProperty{}, false
+			}
+			prop := calendarProperty{
+				Name:   strings.ToUpper(parts[0]),
+				Params: map[string]string{},
+				Value:  line[i+1:],
+			}
+			for _, param := range parts[1:] {
+				kv := strings.SplitN(param, "=", 2)
+				if len(kv) == 2 {
+					prop.Params[strings.ToUpper(kv[0])] = strings.Trim(kv[1], `"`)
+				}
+			}
+			return prop, true
+		}
+	}
+	return calendarProperty{}, false
+}
+
+// parseCalendarTime parses a date or date time value, UTC is used for the
+// floating times and for the unknown time zones
+func parseCalendarTime(prop calendarProperty) (time.Time, error) {
+	if prop.Params["VALUE"] == "DATE" || len(prop.Value) == len(calendarDateLayout) {
+		return time.Parse(calendarDateLayout, prop.Value)
+	}
+	if strings.HasSuffix(prop.Value, "Z") {
+		return time.Parse(calendarTimeLayout, prop.Value)
+	}
+	loc := time.UTC
+	if tzid := prop.Params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	return time.ParseInLocation(calendarLocalTimeLayout, prop.Value, loc)
+}
+
+// unescapeCalendarText reverts escapeCalendarText
+func unescapeCalendarText(txt string) string {
+	return strings.NewReplacer(
+		`\\`, `\`,
+		`\;`, ";",
+		`\,`, ",",
+		`\n`, "\n",
+		`\N`, "\n",
+	).Replace(txt)
+}
@@ -0,0 +1,205 @@
+package gameday
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-plugin-apps/utils/md"
+)
+
+// ImportFormat the format of the content to import
+type ImportFormat string
+
+const (
+	// ImportCSVFormat rows of team, member username, gameday title and date
+	ImportCSVFormat ImportFormat = "csv"
+	// ImportCalendarFormat iCalendar events, the team is read from the categories
+	// and the members from the attendees
+	ImportCalendarFormat ImportFormat = "ics"
+)
+
+// importDateLayouts the layouts accepted for the dates of the CSV rows
+var importDateLayouts = []string{timeLayout, "2006-01-02 15:04", "2006-01-02"}
+
+// importRecord a line of the imported content
+type importRecord struct {
+	Line        int
+	Team        string
+	Members     []string
+	Title       string
+	ScheduledAt time.Time
+}
+
+// ImportError the validation error of a line of the imported content
+type ImportError struct {
+	Line    int
+	Message string
+}
+
+// ImportMember a member which is added to a team by the import
+type ImportMember struct {
+	Team     string
+	Username string
+	UserID   string
+	// slug the identity of the team
+	slug string
+}
+
+// ImportGameday a gameday which is created by the import
+type ImportGameday struct {
+	Line        int
+	Team        string
+	Title       string
+	ScheduledAt time.Time
+	// slug the identity of the team
+	slug string
+}
+
+// ImportReport what the import creates, or would create on a dry run
+type ImportReport struct {
+	DryRun   bool
+	Teams    []string
+	Members  []ImportMember
+	Gamedays []ImportGameday
+	Skipped  int
+	Errors   []ImportError
+}
+
+// addError records the validation error of the line
+func (r *ImportReport) addError(line int, format string, args ...interface{}) {
+	r.Errors = append(r.Errors, ImportError{Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+// validate checks the required values of the record
+func (r importRecord) validate() error {
+	if r.Team == "" {
+		return fmt.Errorf("missing team")
+	}
+	if r.Title == "" && len(r.Members) == 0 {
+		return fmt.Errorf("missing member or gameday title")
+	}
+	if r.Title != "" && r.ScheduledAt.IsZero() {
+		return fmt.Errorf("missing date of gameday %q", r.Title)
+	}
+	return nil
+}
+
+// parseImport reads the records of the content in the given format, the
+// lines which can't be parsed are reported as errors
+func parseImport(format ImportFormat, content string, report *ImportReport) ([]importRecord, error) {
+	switch format {
+	case ImportCSVFormat:
+		return parseCSVImport(content, report)
+	case ImportCalendarFormat:
+		return readCalendar(content, report), nil
+	default:
+		return nil, fmt.Errorf("unsupported import format %q", format)
+	}
+}
+
+// parseCSVImport reads the rows of team, member username, gameday title and
+// date, the header row is optional
+func parseCSVImport(content string, report *ImportReport) ([]importRecord, error) {
+	reader := csv.NewReader(strings.NewReader(content))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var records []importRecord
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if parseErr, ok := err.(*csv.ParseError); ok {
+				report.addError(parseErr.StartLine, "%s", parseErr.Err)
+				continue
+			}
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		if len(records) == 0 && line == 1 && strings.EqualFold(strings.TrimSpace(row[0]), "team") {
+			continue
+		}
+		if len(row) != 4 {
+			report.addError(line, "expected 4 columns: team, member, title, date but got %d", len(row))
+			continue
+		}
+
+		record := importRecord{
+			Line:  line,
+			Team:  strings.TrimSpace(row[0]),
+			Title: strings.TrimSpace(row[2]),
+		}
+		if member := strings.TrimPrefix(strings.TrimSpace(row[1]), "@"); member != "" {
+			record.Members = []string{member}
+		}
+		if date := strings.TrimSpace(row[3]); date != "" {
+			scheduledAt, err := parseImportDate(date)
+			if err != nil {
+				report.addError(line, "invalid date %q, expected format: %s", date, timeLayout)
+				continue
+			}
+			record.ScheduledAt = scheduledAt
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// parseImportDate parses the date with the first layout that matches
+func parseImportDate(date string) (time.Time, error) {
+	var err error
+	for _, layout := range importDateLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, date); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// getImportMarkdown returns the markdown report of the import
+func getImportMarkdown(report ImportReport) md.MD {
+	var txt string
+	switch {
+	case report.DryRun:
+		txt = "#### Import dry run, nothing was created\n"
+	case len(report.Errors) > 0:
+		txt = "#### Import failed, nothing was created\n"
+	default:
+		txt = "#### Import completed\n"
+	}
+
+	if len(report.Errors) > 0 {
+		txt += "\n**Errors:**\n"
+		for _, e := range report.Errors {
+			txt += fmt.Sprintf("- line %d: %s\n", e.Line, e.Message)
+		}
+	}
+	if len(report.Teams) > 0 {
+		txt += "\n**Teams:**\n"
+		for _, t := range report.Teams {
+			txt += fmt.Sprintf("- %s\n", t)
+		}
+	}
+	if len(report.Members) > 0 {
+		txt += "\n**Members:**\n"
+		for _, m := range report.Members {
+			txt += fmt.Sprintf("- @%s to %s\n", m.Username, m.Team)
+		}
+	}
+	if len(report.Gamedays) > 0 {
+		txt += "\n**Gamedays:**\n"
+		for _, g := range report.Gamedays {
+			txt += fmt.Sprintf("- %s for %s at %s\n", g.Title, g.Team, g.ScheduledAt.Format(timeLayout))
+		}
+	}
+	if report.Skipped > 0 {
+		txt += fmt.Sprintf("\n%d line(s) skipped, already existing.\n", report.Skipped)
+	}
+	return md.MD(txt)
+}
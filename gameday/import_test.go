@@ -0,0 +1,130 @@
+package gameday
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+)
+
+func TestImportTeamSlugs(t *testing.T) {
+	svc := newTestService(t)
+	server := newTestMattermost(t)
+	// the 0.16.0 migration suffixed the slug of the teams named alike
+	sre, err := svc.repo.CreateTeam("sre", "owner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	suffixed, err := svc.repo.CreateTeam("ops", "owner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.repo.(*Repository).store.DB.Exec("UPDATE team SET name = 'SRE', slug = 'sre-2' WHERE id = ?", suffixed); err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []TeamMember{{TeamID: sre, UserID: "username/bob", Label: "bob"}, {TeamID: suffixed, UserID: "username/alice", Label: "alice"}} {
+		if err := svc.repo.CreateMember(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx := &apps.Context{ActingUserID: "owner", MattermostSiteURL: server.URL}
+	ctx.BotAccessToken = "bot-token"
+	report, err := svc.Import(ctx, ImportDTO{Format: LookupDTO{Value: string(ImportCSVFormat)}, Content: "sre,alice,,\nsre-2,alice,,\nsre-2,bob,,"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Errors) > 0 || len(report.Teams) > 0 || report.Skipped != 1 || len(report.Members) != 2 {
+		t.Fatalf("wrong import report: %+v", report)
+	}
+
+	teams := map[string]string{}
+	for _, m := range report.Members {
+		teams[m.Username] = m.Team
+	}
+	if teams["alice"] != "sre" || teams["bob"] != "SRE" {
+		t.Errorf("expected the members to join the teams by slug, got %v", teams)
+	}
+	for teamID, want := range map[string]int{sre: 2, suffixed: 2} {
+		members, err := svc.repo.ListTeams(teamID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(members) != want {
+			t.Errorf("expected %d members in team %s, got %+v", want, teamID, members)
+		}
+	}
+}
+
+func TestImportIsAtomic(t *testing.T) {
+	svc := newTestService(t)
+	server := newTestMattermost(t)
+	sre, err := svc.repo.CreateTeam("sre", "owner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, userID := range []string{"username/alice", "username/bob"} {
+		if err := svc.repo.CreateMember(TeamMember{TeamID: sre, UserID: userID, Label: userID}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the invalid rules fail the nomination once the members are imported
+	for _, role := range []TeamRole{{TeamID: sre, Name: MasterOfDisasterRole, Label: "Master of Disaster", Count: 1, Rules: "bogus"}, {TeamID: sre, Name: OnCallRole, Label: "On-Call", Count: 1}} {
+		if err := svc.repo.SaveTeamRole(role); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx := &apps.Context{ActingUserID: "owner", MattermostSiteURL: server.URL}
+	ctx.BotAccessToken = "bot-token"
+	if _, err := svc.Import(ctx, ImportDTO{Format: LookupDTO{Value: string(ImportCSVFormat)}, Content: "ops,alice,,\nsre,carol,Failover,2030-01-02"}); err == nil {
+		t.Fatal("expected the import to fail")
+	}
+
+	if team, err := svc.repo.GetTeam("ops"); err != nil || team != nil {
+		t.Errorf("expected the imported team to be rolled back, got %+v (%v)", team, err)
+	}
+	if member, err := svc.repo.GetMember(sre, "username/carol"); err != nil || member != nil {
+		t.Errorf("expected the imported member to be rolled back, got %+v (%v)", member, err)
+	}
+}
+
+func TestImportKnowsTheTeamsWithoutMembers(t *testing.T) {
+	svc := newTestService(t)
+	server := newTestMattermost(t)
+	if _, err := svc.repo.CreateTeam("sre", "owner"); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := &apps.Context{ActingUserID: "owner", MattermostSiteURL: server.URL}
+	ctx.BotAccessToken = "bot-token"
+	report, err := svc.Import(ctx, ImportDTO{Format: LookupDTO{Value: string(ImportCSVFormat)}, Content: "sre,alice,,", DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Errors) > 0 || len(report.Teams) > 0 || len(report.Members) != 1 {
+		t.Errorf("expected the team without members to be known, got %+v", report)
+	}
+}
+
+func TestImportWhenTheUsersCantBeFetched(t *testing.T) {
+	svc := newTestService(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/api/v4/users/username/") {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"message": "unavailable"}`))
+			return
+		}
+		mattermostHandler()(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	ctx := &apps.Context{ActingUserID: "owner", MattermostSiteURL: server.URL}
+	ctx.BotAccessToken = "bot-token"
+	report, err := svc.Import(ctx, ImportDTO{Format: LookupDTO{Value: string(ImportCSVFormat)}, Content: "sre,alice,,"})
+	if err == nil {
+		t.Errorf("expected the import to fail rather than report unknown users, got %+v", report)
+	}
+}
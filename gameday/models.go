@@ -11,10 +11,26 @@ import (
 // Team describes the team and the members included on
 // this gameday
 type Team struct {
-	ID        string `db:"id"`
-	Name      string `db:"name"`
-	CreatedAt int64  `db:"created_at"`
-	UpdatedAt int64  `db:"updated_at"`
+	ID                 string `db:"id"`
+	Name               string `db:"name"`
+	Slug               string `db:"slug"`
+	OwnerID            string `db:"owner_id"`
+	NominationStrategy string `db:"nomination_strategy"`
+	AckRemindAfter     int64  `db:"ack_remind_after"`
+	AckEscalateBefore  int64  `db:"ack_escalate_before"`
+	AckEscalation      string `db:"ack_escalation"`
+	SyncSource         string `db:"sync_source"`
+	SyncSourceID       string `db:"sync_source_id"`
+	SyncSourceName     string `db:"sync_source_name"`
+	SyncedAt           int64  `db:"synced_at"`
+	CreatedAt          int64  `db:"created_at"`
+	UpdatedAt          int64  `db:"updated_at"`
+}
+
+// teamSlug the identity of a team, its name in lowercase
+// with the whitespaces replaced by dashes
+func teamSlug(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), "-")
 }
 
 func (t Team) toLookupTeamDTO() LookupDTO {
@@ -27,13 +43,152 @@ func (t Team) toLookupTeamDTO() LookupDTO {
 // Member describes the team and the members included on
 // this gameday
 type TeamMember struct {
-	ID        string `db:"id"`
-	TeamID    string `db:"team_id"`
-	UserID    string `db:"user_id"`
-	Label     string `db:"label"`
-	CreatedAt int64  `db:"created_at"`
-	UpdatedAt int64  `db:"updated_at"`
-	Team      `db:"team"`
+	ID            string                 `db:"id"`
+	TeamID        string                 `db:"team_id"`
+	UserID        string                 `db:"user_id"`
+	Label         string                 `db:"label"`
+	Level         ExperienceLevel        `db:"level"`
+	Skills        string                 `db:"skills"`
+	Timezone      string                 `db:"timezone"`
+	Notifications NotificationPreference `db:"notifications"`
+	CreatedAt     int64                  `db:"created_at"`
+	UpdatedAt     int64                  `db:"updated_at"`
+	Team          `db:"team"`
+}
+
+// localTime formats the unix time in the timezone of the member
+func (m TeamMember) localTime(unix int64) string {
+	return formatLocalTime(unix, m.Timezone)
+}
+
+// formatLocalTime formats the unix time in the timezone, the server
+// timezone is used when the timezone is unset or unknown
+func formatLocalTime(unix int64, timezone string) string {
+	t := time.Unix(unix, 0)
+	if timezone == "" {
+		return t.Format(timeLayout)
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return t.Format(timeLayout)
+	}
+	return t.In(location).Format(timeLayout + " MST")
+}
+
+// NotificationPreference the direct messages a member wants to receive
+type NotificationPreference string
+
+const (
+	// NotifyAll every message about the gamedays of the team
+	NotifyAll NotificationPreference = "all"
+	// NotifyNominations only the messages about the gamedays where the member is nominated
+	NotifyNominations NotificationPreference = "nominations"
+)
+
+// notificationPreferences the preferences a member can choose from
+var notificationPreferences = []NotificationPreference{NotifyAll, NotifyNominations}
+
+func isNotificationPreference(name string) bool {
+	for _, p := range notificationPreferences {
+		if string(p) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// notificationPreferenceNames the comma separated notification preferences
+func notificationPreferenceNames() string {
+	var names []string
+	for _, p := range notificationPreferences {
+		names = append(names, string(p))
+	}
+	return strings.Join(names, ", ")
+}
+
+// wantsAnnouncements returns true when the member receives the messages
+// about the gamedays even when they aren't nominated
+func (m TeamMember) wantsAnnouncements() bool {
+	return m.Notifications != NotifyNominations
+}
+
+// MemberProfile the attributes a user shares across the teams they are
+// member of, the level and the skills are set by the admins of each team
+type MemberProfile struct {
+	Timezone      string
+	Notifications NotificationPreference
+}
+
+// profile returns the profile attributes of the member
+func (m TeamMember) profile() MemberProfile {
+	return MemberProfile{Timezone: m.Timezone, Notifications: m.Notifications}
+}
+
+// getProfileMarkdown markdown for the profile of a user and the teams they are member of
+func getProfileMarkdown(members []TeamMember) md.MD {
+	if len(members) == 0 {
+		return md.MD("You aren't a member of any team")
+	}
+	p := members[0].profile()
+	timezone := p.Timezone
+	if timezone == "" {
+		timezone = "server time"
+	}
+	txt := fmt.Sprintf("#### Profile: @%s\n", members[0].Label)
+	txt += fmt.Sprintf("**Timezone:** %s\n", timezone)
+	txt += fmt.Sprintf("**Notifications:** %s\n", p.Notifications)
+	txt += "**Teams:**\n"
+	for _, m := range members {
+		txt += fmt.Sprintf("- %s: level %s, skills: %s\n", m.Team.Name, m.Level, m.Skills)
+	}
+	return md.MD(txt)
+}
+
+// hasSkill checks if the skill is one of the comma separated skills of the member
+func (m TeamMember) hasSkill(skill string) bool {
+	for _, s := range parseSkills(m.Skills) {
+		if s == strings.ToLower(skill) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExperienceLevel the experience of a member, the members
+// without a level are the least experienced
+type ExperienceLevel string
+
+const (
+	// JuniorLevel a member who is learning the gamedays
+	JuniorLevel ExperienceLevel = "junior"
+	// IntermediateLevel a member who already practiced the gamedays
+	IntermediateLevel ExperienceLevel = "intermediate"
+	// SeniorLevel a member who can lead the gamedays
+	SeniorLevel ExperienceLevel = "senior"
+)
+
+// experienceLevels the levels from the least to the most experienced
+var experienceLevels = []ExperienceLevel{JuniorLevel, IntermediateLevel, SeniorLevel}
+
+// rank returns the position of the level, -1 when the level isn't set
+func (l ExperienceLevel) rank() int {
+	for i, level := range experienceLevels {
+		if level == l {
+			return i
+		}
+	}
+	return -1
+}
+
+// parseSkills returns the lowercase comma separated skills
+func parseSkills(skills string) []string {
+	var results []string
+	for _, s := range strings.Split(skills, ",") {
+		if s = strings.ToLower(strings.TrimSpace(s)); s != "" {
+			results = append(results, s)
+		}
+	}
+	return results
 }
 
 // GamedayState the state of a gameday
@@ -50,6 +205,77 @@ const (
 	GamedayCompletedState GamedayState = "completed"
 )
 
+// GamedayEvent the events of a gameday which channels
+// can subscribe to
+type GamedayEvent string
+
+const (
+	// GamedayCreatedEvent when a gameday is scheduled
+	GamedayCreatedEvent GamedayEvent = "created"
+	// GamedayStartedEvent when a gameday starts
+	GamedayStartedEvent GamedayEvent = "started"
+	// GamedayCompletedEvent when a gameday is completed
+	GamedayCompletedEvent GamedayEvent = "completed"
+	// GamedayCancelledEvent when a gameday is cancelled
+	GamedayCancelledEvent GamedayEvent = "cancelled"
+)
+
+// gamedayEvents all the events channels can subscribe to
+var gamedayEvents = []GamedayEvent{
+	GamedayCreatedEvent,
+	GamedayStartedEvent,
+	GamedayCompletedEvent,
+	GamedayCancelledEvent,
+}
+
+// stateEvents the event which is published when a gameday
+// moves to the state
+var stateEvents = map[GamedayState]GamedayEvent{
+	GamedayScheduledState:  GamedayCreatedEvent,
+	GamedayInProgressState: GamedayStartedEvent,
+	GamedayCompletedState:  GamedayCompletedEvent,
+	GamedayCancelledState:  GamedayCancelledEvent,
+}
+
+// stateTransitions the states a gameday can move to from each state,
+// the cancelled and the completed gamedays stay as they are
+var stateTransitions = map[GamedayState][]GamedayState{
+	GamedayScheduledState:  {GamedayInProgressState, GamedayCancelledState},
+	GamedayInProgressState: {GamedayCompletedState, GamedayCancelledState},
+}
+
+// canBecome returns true when a gameday can move from the state to next
+func (s GamedayState) canBecome(next GamedayState) bool {
+	for _, state := range stateTransitions[s] {
+		if state == next {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscription describes a channel which receives the
+// gameday events of a team
+type Subscription struct {
+	ID        string `db:"id"`
+	TeamID    string `db:"team_id"`
+	ChannelID string `db:"channel_id"`
+	Events    string `db:"events"`
+	CreatedAt int64  `db:"created_at"`
+	UpdatedAt int64  `db:"updated_at"`
+	Team      `db:"team"`
+}
+
+// HasEvent checks if the channel is subscribed to the event
+func (s Subscription) HasEvent(event GamedayEvent) bool {
+	for _, e := range strings.Split(s.Events, ",") {
+		if GamedayEvent(e) == event {
+			return true
+		}
+	}
+	return false
+}
+
 // Gameday describes the team and the member included
 // on this gameday. Different teams can set different
 // gamedays
@@ -59,11 +285,30 @@ type Gameday struct {
 	TeamID      string       `db:"team_id"`
 	ScheduledAt int64        `db:"scheduled_at"`
 	State       GamedayState `db:"state"`
+	ChannelID   string       `db:"channel_id"`
+	PostID      string       `db:"post_id"`
+	Reason      string       `db:"reason"`
+	Scenarios   string       `db:"scenarios"`
+	Checklist   string       `db:"checklist"`
+	Duration    int64        `db:"duration"`
+	Roles       string       `db:"roles"`
+	Sequence    int64        `db:"sequence"`
+	Seed        int64        `db:"nomination_seed"`
+	Snapshot    string       `db:"nomination_snapshot"`
 	CreatedAt   int64        `db:"created_at"`
 	UpdatedAt   int64        `db:"updated_at"`
 	Team        `db:"team"`
 }
 
+// endsAt returns the unix time when the gameday ends
+func (g Gameday) endsAt() int64 {
+	duration := g.Duration
+	if duration == 0 {
+		duration = defaultDuration
+	}
+	return g.ScheduledAt + duration*60
+}
+
 func (g Gameday) toGameDayDTO() GamedayDTO {
 	return GamedayDTO{
 		Name: g.Title,
@@ -73,6 +318,11 @@ func (g Gameday) toGameDayDTO() GamedayDTO {
 		},
 		State:       g.State,
 		ScheduledAt: ScheduledAtTime(time.Unix(g.ScheduledAt, 0)),
+		Reason:      g.Reason,
+		Scenarios:   g.Scenarios,
+		Checklist:   g.Checklist,
+		Duration:    g.Duration,
+		Roles:       g.Roles,
 	}
 }
 
@@ -89,46 +339,573 @@ func (g Gameday) toLookupGamedayDTO() LookupDTO {
 	}
 }
 
-// GamedayNominee the nominess for gamedays about
-// Master of Disaster
-// On Call
-type GamedayNominee struct {
-	ID                 string `db:"id"`
-	GamedayID          string `db:"gameday_id"`
-	MemberID           string `db:"member_id"`
-	UserID             string `db:"user_id"`
-	IsMasterOfDisaster bool   `db:"is_mod"`
-	IsOnCall           bool   `db:"is_on_call"`
-	CreatedAt          int64  `db:"created_at"`
-	UpdatedAt          int64  `db:"updated_at"`
-	Gameday            `db:"gameday"`
+// NomineeRole the role a team member is nominated for
+type NomineeRole string
+
+const (
+	// MasterOfDisasterRole the member who runs the gameday
+	MasterOfDisasterRole NomineeRole = "mod"
+	// OnCallRole the member who responds to the disasters
+	OnCallRole NomineeRole = "oncall"
+)
+
+// defaultRoles the roles which are nominated when a gameday
+// doesn't specify them
+const defaultRoles = "mod,oncall"
+
+// TeamRole a role the team fills on its gamedays, the count is the number
+// of members nominated for it and the rules restrict who is eligible
+type TeamRole struct {
+	ID        string      `db:"id"`
+	TeamID    string      `db:"team_id"`
+	Name      NomineeRole `db:"name"`
+	Label     string      `db:"label"`
+	Count     int         `db:"count"`
+	Rules     string      `db:"rules"`
+	CreatedAt int64       `db:"created_at"`
+	UpdatedAt int64       `db:"updated_at"`
 }
 
-// getMarkdown for team members
-func getMarkdown(members []TeamMember) md.MD {
-	if len(members) == 0 {
-		return md.MD("There aren't any available GameDays")
+// defaultTeamRoles the roles of a new team
+var defaultTeamRoles = []TeamRole{
+	{Name: MasterOfDisasterRole, Label: "Master of Disaster", Count: 1},
+	{Name: OnCallRole, Label: "On-Call", Count: 1},
+}
+
+// getTeamMarkdown markdown with the settings, the members and the roles of a team
+func getTeamMarkdown(team Team, members []TeamMember, roles []TeamRole) md.MD {
+	txt := fmt.Sprintf("#### Team: %s\n", team.Name)
+	if team.OwnerID != "" {
+		txt += fmt.Sprintf("**Owner ID:** %s\n", team.OwnerID)
 	}
-	txt := "| Team | Members | \n"
-	txt += "| :-- |:-- |\n"
+	txt += fmt.Sprintf("**Nomination Strategy:** %s\n", getNominationStrategy(team.NominationStrategy).Name())
+	txt += fmt.Sprintf("**Acknowledgements:** reminded after %d hour(s), escalated to %s %d hour(s) before the gameday\n", team.AckRemindAfter, team.AckEscalation, team.AckEscalateBefore)
+	if team.SyncSource != "" {
+		txt += fmt.Sprintf("**Synced from:** %s %s", team.SyncSource, team.SyncSourceName)
+		if team.SyncedAt > 0 {
+			txt += fmt.Sprintf(", last synced at %s", time.Unix(0, team.SyncedAt*int64(time.Millisecond)).Format(timeLayout))
+		}
+		txt += "\n"
+	}
+	var names []string
+	for _, r := range roles {
+		names = append(names, fmt.Sprintf("%s (%d)", r.Label, r.Count))
+	}
+	txt += fmt.Sprintf("**Roles:** %s\n", strings.Join(names, ", "))
 
-	var team string
-	var users []string
+	if len(members) == 0 {
+		return md.MD(txt + "\nThe team doesn't have any members\n")
+	}
+	txt += "\n| Member | Level | Skills | Timezone | Joined |\n"
+	txt += "| :-- |:-- |:-- |:-- |:-- |\n"
 	for _, m := range members {
-		team = m.Team.Name
-		users = append(users, fmt.Sprintf("@%s", m.Label))
+		txt += fmt.Sprintf("|@%s|%s|%s|%s|%s|\n", m.Label, m.Level, m.Skills, m.Timezone, time.Unix(0, m.CreatedAt*int64(time.Millisecond)).Format(timeLayout))
 	}
-	txt += fmt.Sprintf("|%s|%s|\n", team, strings.Join(users[:], ","))
+	return md.MD(txt)
+}
+
+// getRolesMarkdown markdown for the roles of a team
+func getRolesMarkdown(team string, roles []TeamRole) md.MD {
+	if len(roles) == 0 {
+		return md.MD(fmt.Sprintf("Team %s doesn't have any roles", team))
+	}
+	txt := fmt.Sprintf("#### Roles of team: %s\n", team)
+	txt += "| Name | Label | Count | Rules |\n"
+	txt += "| :-- |:-- |:-- |:-- |\n"
+	for _, r := range roles {
+		txt += fmt.Sprintf("|%s|%s|%d|%s|\n", r.Name, r.Label, r.Count, r.Rules)
+	}
+	return md.MD(txt)
+}
+
+// defaultDuration the duration of a gameday in minutes when
+// it isn't specified
+const defaultDuration = 60
+
+// parseRoles returns the comma separated roles
+func parseRoles(roles string) []NomineeRole {
+	if strings.TrimSpace(roles) == "" {
+		roles = defaultRoles
+	}
+	var results []NomineeRole
+	for _, r := range strings.Split(roles, ",") {
+		if r = strings.ToLower(strings.TrimSpace(r)); r != "" {
+			results = append(results, NomineeRole(r))
+		}
+	}
+	return results
+}
+
+// hasRole checks if the role is included in the comma separated roles
+func hasRole(roles string, role NomineeRole) bool {
+	for _, r := range parseRoles(roles) {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// GamedayTemplate describes a reusable gameday configuration
+// for exercises which are repeated
+type GamedayTemplate struct {
+	ID        string `db:"id"`
+	Name      string `db:"name"`
+	Title     string `db:"title"`
+	TeamID    string `db:"team_id"`
+	Scenarios string `db:"scenarios"`
+	Checklist string `db:"checklist"`
+	Duration  int64  `db:"duration"`
+	Roles     string `db:"roles"`
+	CreatedAt int64  `db:"created_at"`
+	UpdatedAt int64  `db:"updated_at"`
+	Team      `db:"team"`
+}
+
+func (t GamedayTemplate) toLookupTemplateDTO() LookupDTO {
+	return LookupDTO{
+		Label: t.Name,
+		Value: t.Name,
+	}
+}
+
+// toGamedayDTO creates the gameday for the given time by expanding
+// the `{team}` and `{date}` placeholders of the title
+func (t GamedayTemplate) toGamedayDTO(scheduledAt ScheduledAtTime) GamedayDTO {
+	title := strings.NewReplacer(
+		"{team}", t.Team.Name,
+		"{date}", time.Time(scheduledAt).Format("2006-01-02"),
+	).Replace(t.Title)
+
+	return GamedayDTO{
+		Name: title,
+		Team: LookupDTO{
+			Label: t.Team.Name,
+			Value: t.TeamID,
+		},
+		ScheduledAt: scheduledAt,
+		Scenarios:   t.Scenarios,
+		Checklist:   t.Checklist,
+		Duration:    t.Duration,
+		Roles:       t.Roles,
+	}
+}
+
+// CalendarToken the secret token of the iCalendar feed of a team
+// or of a Mattermost user
+type CalendarToken struct {
+	ID        string `db:"id"`
+	Token     string `db:"token"`
+	TeamID    string `db:"team_id"`
+	UserID    string `db:"user_id"`
+	CreatedAt int64  `db:"created_at"`
+}
+
+// GamedayNominee the nominess for gamedays, the role is one of
+// the roles of the team like the Master of Disaster or On Call
+type GamedayNominee struct {
+	ID        string      `db:"id"`
+	GamedayID string      `db:"gameday_id"`
+	MemberID  string      `db:"member_id"`
+	UserID    string      `db:"user_id"`
+	Label     string      `db:"label"`
+	Role      NomineeRole `db:"role"`
+	RoleLabel string      `db:"role_label"`
+	Strategy  string      `db:"strategy"`
+	Reason    string      `db:"reason"`
+	// AcknowledgedAt, RemindedAt and EscalatedAt are unix timestamps in
+	// milliseconds, 0 until the nominee acknowledges, is reminded or escalated
+	AcknowledgedAt int64 `db:"acknowledged_at"`
+	RemindedAt     int64 `db:"reminded_at"`
+	EscalatedAt    int64 `db:"escalated_at"`
+	CreatedAt      int64 `db:"created_at"`
+	UpdatedAt      int64 `db:"updated_at"`
+	Gameday        `db:"gameday"`
+}
+
+// removedMemberLabel the label of the nominees whose member was removed
+// from the team
+const removedMemberLabel = "removed-member"
+
+// nominatedAt returns the unix time in seconds when the member was
+// nominated, which is when the nominee was last replaced if it was
+func (n GamedayNominee) nominatedAt() int64 {
+	at := n.CreatedAt
+	if n.UpdatedAt > at {
+		at = n.UpdatedAt
+	}
+	return at / int64(time.Second/time.Millisecond)
+}
+
+// ackState describes whether the nominee acknowledged the role
+func (n GamedayNominee) ackState() string {
+	switch {
+	case n.AcknowledgedAt > 0:
+		return "acknowledged"
+	case n.EscalatedAt > 0:
+		return "escalated"
+	case n.RemindedAt > 0:
+		return "reminded"
+	}
+	return "pending"
+}
+
+// GamedayHistoryAction the change which is logged in the history of a gameday
+type GamedayHistoryAction string
+
+const (
+	// RenominatedAction when a new member is drawn for a role
+	RenominatedAction GamedayHistoryAction = "renominated"
+	// SwappedAction when a volunteer replaces a nominee
+	SwappedAction GamedayHistoryAction = "swapped"
+	// RescheduledAction when the gameday is moved to another time
+	RescheduledAction GamedayHistoryAction = "rescheduled"
+)
+
+// MemberAway a period when the user can't be nominated,
+// the start and end are unix timestamps in seconds
+type MemberAway struct {
+	ID        string `db:"id"`
+	UserID    string `db:"user_id"`
+	StartsAt  int64  `db:"starts_at"`
+	EndsAt    int64  `db:"ends_at"`
+	CreatedAt int64  `db:"created_at"`
+}
+
+// getAwayMarkdown markdown for the away periods of a user
+func getAwayMarkdown(aways []MemberAway) md.MD {
+	txt := "You are away:\n"
+	for _, a := range aways {
+		txt += fmt.Sprintf("- from %s to %s\n", time.Unix(a.StartsAt, 0).Format(timeLayout), time.Unix(a.EndsAt, 0).Format(timeLayout))
+	}
+	return md.MD(txt)
+}
+
+// MemberExemption a period when the team member is out of the rotation,
+// the expiry is a unix timestamp in seconds
+type MemberExemption struct {
+	ID        string `db:"id"`
+	MemberID  string `db:"member_id"`
+	UserID    string `db:"user_id"`
+	Label     string `db:"label"`
+	Reason    string `db:"reason"`
+	ExpiresAt int64  `db:"expires_at"`
+	CreatedBy string `db:"created_by"`
+	CreatedAt int64  `db:"created_at"`
+}
+
+// getExemptionsMarkdown markdown for the exemptions of a team
+func getExemptionsMarkdown(team string, exemptions []MemberExemption) md.MD {
+	if len(exemptions) == 0 {
+		return md.MD(fmt.Sprintf("Every member of team %s is in the rotation", team))
+	}
+	txt := fmt.Sprintf("#### Exemptions of team: %s\n", team)
+	txt += "| Member | Reason | Until |\n"
+	txt += "| :-- |:-- |:-- |\n"
+	for _, e := range exemptions {
+		txt += fmt.Sprintf("|@%s|%s|%s|\n", e.Label, e.Reason, time.Unix(e.ExpiresAt, 0).Format(timeLayout))
+	}
+	return md.MD(txt)
+}
+
+// TeamAdmin a user who administers a team besides its owner
+type TeamAdmin struct {
+	ID        string `db:"id"`
+	TeamID    string `db:"team_id"`
+	UserID    string `db:"user_id"`
+	Label     string `db:"label"`
+	CreatedBy string `db:"created_by"`
+	CreatedAt int64  `db:"created_at"`
+}
+
+// MembershipReport the users added to a team and the users who
+// already were members
+type MembershipReport struct {
+	Team     Team
+	Added    []string
+	Existing []string
+}
+
+// getMembershipMarkdown markdown for the members added to a team
+func getMembershipMarkdown(report MembershipReport, created bool) md.MD {
+	txt := ""
+	if created {
+		txt += fmt.Sprintf("Team **%s** was created\n", report.Team.Name)
+	}
+	if len(report.Added) > 0 {
+		txt += fmt.Sprintf("Added to team **%s**: @%s\n", report.Team.Name, strings.Join(report.Added, ", @"))
+	}
+	if len(report.Existing) > 0 {
+		txt += fmt.Sprintf("Already members of team **%s**: @%s\n", report.Team.Name, strings.Join(report.Existing, ", @"))
+	}
+	return md.MD(txt)
+}
+
+// GamedayHistory a change of a gameday made by a user
+type GamedayHistory struct {
+	ID        string               `db:"id"`
+	GamedayID string               `db:"gameday_id"`
+	UserID    string               `db:"user_id"`
+	Action    GamedayHistoryAction `db:"action"`
+	Message   string               `db:"message"`
+	CreatedAt int64                `db:"created_at"`
+}
+
+// RSVPResponse the response of a team member when
+// invited to a gameday
+type RSVPResponse string
+
+const (
+	// RSVPAttending when the member will attend the gameday
+	RSVPAttending RSVPResponse = "attending"
+	// RSVPDeclined when the member can't attend the gameday
+	RSVPDeclined RSVPResponse = "declined"
+)
+
+// GamedayRSVP the response of a team member to a gameday
+// invitation and whether the member attended it
+type GamedayRSVP struct {
+	ID        string       `db:"id"`
+	GamedayID string       `db:"gameday_id"`
+	MemberID  string       `db:"member_id"`
+	UserID    string       `db:"user_id"`
+	Label     string       `db:"label"`
+	Response  RSVPResponse `db:"response"`
+	Attended  bool         `db:"attended"`
+	CreatedAt int64        `db:"created_at"`
+	UpdatedAt int64        `db:"updated_at"`
+}
+
+// TeamSummary a team with its members, admins and the gamedays
+// around now
+type TeamSummary struct {
+	Team        Team
+	Members     []TeamMember
+	Admins      []string
+	LastGameday *Gameday
+	NextGameday *Gameday
+}
+
+// lastAndNextGameday returns the latest gameday which took place and the
+// earliest scheduled one, the gamedays are ordered by schedule
+func lastAndNextGameday(gamedays []Gameday) (*Gameday, *Gameday) {
+	var last, next *Gameday
+	for i := range gamedays {
+		g := &gamedays[i]
+		switch g.State {
+		case GamedayCompletedState, GamedayInProgressState:
+			if last == nil || g.ScheduledAt >= last.ScheduledAt {
+				last = g
+			}
+		case GamedayScheduledState:
+			if next == nil || g.ScheduledAt < next.ScheduledAt {
+				next = g
+			}
+		}
+	}
+	return last, next
+}
+
+// getGamedayCell the title and the schedule of the gameday or a dash
+func getGamedayCell(g *Gameday) string {
+	if g == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%s (%s)", g.Title, time.Unix(g.ScheduledAt, 0).Format(timeLayout))
+}
+
+// getUsersCell the mentions of the users or a dash
+func getUsersCell(usernames []string) string {
+	if len(usernames) == 0 {
+		return "-"
+	}
+	return "@" + strings.Join(usernames, ", @")
+}
+
+// getTeamsMarkdown markdown with a row per team
+func getTeamsMarkdown(summaries []TeamSummary) md.MD {
+	if len(summaries) == 0 {
+		return md.MD("There aren't any teams")
+	}
+	txt := "| Team | Members | Admins | Last Gameday | Next Gameday |\n"
+	txt += "| :-- |:-- |:-- |:-- |:-- |\n"
+	for _, s := range summaries {
+		txt += fmt.Sprintf("|%s|%d|%s|%s|%s|\n", s.Team.Name, len(s.Members), getUsersCell(s.Admins), getGamedayCell(s.LastGameday), getGamedayCell(s.NextGameday))
+	}
+	return md.MD(txt)
+}
+
+// getTeamSummaryMarkdown markdown with the details of a team
+func getTeamSummaryMarkdown(s TeamSummary) md.MD {
+	var members []string
+	for _, m := range s.Members {
+		members = append(members, m.Label)
+	}
+	txt := fmt.Sprintf("#### Team: %s\n", s.Team.Name)
+	txt += fmt.Sprintf("**Admins:** %s\n", getUsersCell(s.Admins))
+	txt += fmt.Sprintf("**Last Gameday:** %s\n", getGamedayCell(s.LastGameday))
+	txt += fmt.Sprintf("**Next Gameday:** %s\n", getGamedayCell(s.NextGameday))
+	txt += fmt.Sprintf("**Members (%d):** %s\n", len(members), getUsersCell(members))
 	return md.MD(txt)
 }
 
 // getGameDaysMarkdown makrodnw for the game days
 func getGameDaysMarkdown(gamedays []GamedayDTO) md.MD {
-	txt := "| Title | Team | Scheduled At | State |\n"
-	txt += "| :-- |:-- |:-- |\n"
+	txt := "| Title | Team | Scheduled At | State | Reason |\n"
+	txt += "| :-- |:-- |:-- |:-- |:-- |\n"
 
 	for _, g := range gamedays {
-		txt += fmt.Sprintf("|%s|%s|%s|%s|\n", g.Name, g.Team.Label, g.ScheduledAt.String(), g.State)
+		txt += fmt.Sprintf("|%s|%s|%s|%s|%s|\n", g.Name, g.Team.Label, g.ScheduledAt.String(), g.State, g.Reason)
+	}
+	return md.MD(txt)
+}
+
+// getGamedayMarkdown markdown with the details of a gameday
+// and the attendance of the team members
+func getGamedayMarkdown(gameday Gameday, nominees []GamedayNominee, rsvps []GamedayRSVP, history []GamedayHistory) md.MD {
+	txt := fmt.Sprintf("#### %s\n%s", gameday.Title, getGamedayDescription(gameday, nominees))
+	txt += getAttendanceMarkdown(rsvps)
+	var reasons []string
+	for _, n := range nominees {
+		reason := fmt.Sprintf("- %s @%s (%s)", getNomineeRole(n), n.Label, n.ackState())
+		if n.Reason != "" {
+			reason += ": " + n.Reason
+		}
+		reasons = append(reasons, reason+"\n")
+	}
+	if len(reasons) > 0 {
+		txt += "\n**Nominations:**\n" + strings.Join(reasons, "")
+	}
+	if len(history) > 0 {
+		txt += "\n**History:**\n"
+		for _, h := range history {
+			txt += fmt.Sprintf("- %s: %s\n", time.Unix(0, h.CreatedAt*int64(time.Millisecond)).Format(timeLayout), h.Message)
+		}
+	}
+	return md.MD(txt)
+}
+
+// getAttendanceMarkdown markdown with the RSVP responses and the
+// attendance of the team members
+func getAttendanceMarkdown(rsvps []GamedayRSVP) string {
+	var attending, declined, attended []string
+	for _, r := range rsvps {
+		switch r.Response {
+		case RSVPAttending:
+			attending = append(attending, fmt.Sprintf("@%s", r.Label))
+		case RSVPDeclined:
+			declined = append(declined, fmt.Sprintf("@%s", r.Label))
+		}
+		if r.Attended {
+			attended = append(attended, fmt.Sprintf("@%s", r.Label))
+		}
+	}
+	txt := fmt.Sprintf("**Attending:** %s\n", strings.Join(attending, ", "))
+	txt += fmt.Sprintf("**Can't attend:** %s\n", strings.Join(declined, ", "))
+	txt += fmt.Sprintf("**Attended:** %s\n", strings.Join(attended, ", "))
+	return txt
+}
+
+// getGamedayEventMessage the message posted to the subscribed channels
+func getGamedayEventMessage(gameday Gameday, event GamedayEvent) string {
+	scheduledAt := time.Unix(gameday.ScheduledAt, 0).Format(timeLayout)
+	switch event {
+	case GamedayCreatedEvent:
+		return fmt.Sprintf("Gameday **%s** for team **%s** is scheduled for %s", gameday.Title, gameday.Team.Name, scheduledAt)
+	case GamedayStartedEvent:
+		return fmt.Sprintf("Gameday **%s** for team **%s** just started", gameday.Title, gameday.Team.Name)
+	case GamedayCompletedEvent:
+		return fmt.Sprintf("Gameday **%s** for team **%s** just completed%s", gameday.Title, gameday.Team.Name, getReasonMessage(gameday))
+	case GamedayCancelledEvent:
+		return fmt.Sprintf("Gameday **%s** for team **%s** scheduled for %s was cancelled%s", gameday.Title, gameday.Team.Name, scheduledAt, getReasonMessage(gameday))
+	}
+	return fmt.Sprintf("Gameday **%s** for team **%s**: %s", gameday.Title, gameday.Team.Name, event)
+}
+
+// getVerificationMarkdown returns the markdown with the nominees of the
+// gameday and the nominees of the replay
+func getVerificationMarkdown(v NominationVerification) md.MD {
+	nomineesLabel := func(nominees []GamedayNominee) string {
+		var labels []string
+		for _, n := range nominees {
+			labels = append(labels, fmt.Sprintf("%s: @%s", getNomineeRole(n), n.Label))
+		}
+		return strings.Join(labels, ", ")
+	}
+
+	txt := fmt.Sprintf("#### Nominees of gameday: %s\n", v.Gameday.Title)
+	txt += fmt.Sprintf("**Seed:** %d\n", v.Gameday.Seed)
+	txt += fmt.Sprintf("**Strategy:** %s\n", v.Strategy)
+	txt += fmt.Sprintf("**Nominated:** %s\n", nomineesLabel(v.Nominees))
+	txt += fmt.Sprintf("**Replayed:** %s\n", nomineesLabel(v.Replayed))
+	if v.IsFair() {
+		txt += "\n:white_check_mark: The replay nominated the same members, the nomination was fair.\n"
+	} else {
+		txt += "\n:warning: The replay nominated different members, the nominees were changed after the nomination.\n"
+	}
+	return md.MD(txt)
+}
+
+// getPreviewMarkdown returns the markdown with the candidates, the members
+// left out and the nominees a gameday would have
+func getPreviewMarkdown(p NominationPreview) md.MD {
+	var candidates []string
+	for _, m := range p.Candidates {
+		candidates = append(candidates, "@"+m.Label)
+	}
+
+	txt := fmt.Sprintf("#### Preview of gameday: %s\n", p.Gameday.Title)
+	txt += fmt.Sprintf("**Team:** %s\n", p.Gameday.Team.Name)
+	txt += fmt.Sprintf("**Scheduled At:** %s\n", time.Unix(p.Gameday.ScheduledAt, 0).Format(timeLayout))
+	txt += fmt.Sprintf("**Strategy:** %s\n", getNominationStrategy(p.Gameday.Team.NominationStrategy).Name())
+	txt += fmt.Sprintf("**Candidates:** %s\n", strings.Join(candidates, ", "))
+	if len(p.Exclusions) > 0 {
+		txt += "**Left out:**\n"
+		for _, e := range p.Exclusions {
+			txt += fmt.Sprintf("- %s\n", e)
+		}
+	}
+	txt += "**Would nominate:**\n"
+	for _, n := range p.Nominees {
+		txt += fmt.Sprintf("- %s: @%s, %s\n", getNomineeRole(n), n.Label, n.Reason)
+	}
+	txt += "\nNothing was saved and nobody was notified, the random picks may differ when the gameday is created.\n"
+	return md.MD(txt)
+}
+
+// getTemplatesMarkdown markdown for the gameday templates
+func getTemplatesMarkdown(templates []GamedayTemplate) md.MD {
+	if len(templates) == 0 {
+		return md.MD("There aren't any gameday templates")
+	}
+	txt := "| Name | Title | Team | Duration | Roles | Scenarios | Checklist |\n"
+	txt += "| :-- |:-- |:-- |:-- |:-- |:-- |:-- |\n"
+
+	for _, t := range templates {
+		txt += fmt.Sprintf("|%s|%s|%s|%dm|%s|%s|%s|\n", t.Name, t.Title, t.Team.Name, t.Duration, t.Roles, t.Scenarios, t.Checklist)
+	}
+	return md.MD(txt)
+}
+
+// getReasonMessage the reason why a gameday was cancelled or the summary
+// of a completed gameday to append to messages
+func getReasonMessage(gameday Gameday) string {
+	if gameday.Reason == "" {
+		return ""
+	}
+	return fmt.Sprintf(": _%s_", gameday.Reason)
+}
+
+// getSubscriptionsMarkdown markdown for the subscriptions of a channel
+func getSubscriptionsMarkdown(subscriptions []Subscription) md.MD {
+	if len(subscriptions) == 0 {
+		return md.MD("This channel isn't subscribed to any team")
+	}
+	txt := "| Team | Events |\n"
+	txt += "| :-- |:-- |\n"
+
+	for _, s := range subscriptions {
+		txt += fmt.Sprintf("|%s|%s|\n", s.Team.Name, strings.ReplaceAll(s.Events, ",", ", "))
 	}
 	return md.MD(txt)
 }
@@ -0,0 +1,82 @@
+package gameday
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLastAndNextGameday(t *testing.T) {
+	gamedays := []Gameday{
+		{ID: "g1", ScheduledAt: 100, State: GamedayCompletedState},
+		{ID: "g2", ScheduledAt: 200, State: GamedayCompletedState},
+		{ID: "g3", ScheduledAt: 300, State: GamedayCancelledState},
+		{ID: "g4", ScheduledAt: 400, State: GamedayScheduledState},
+		{ID: "g5", ScheduledAt: 500, State: GamedayScheduledState},
+	}
+
+	last, next := lastAndNextGameday(gamedays)
+	if last == nil || last.ID != "g2" {
+		t.Errorf("wrong last gameday: got %v want g2", last)
+	}
+	if next == nil || next.ID != "g4" {
+		t.Errorf("wrong next gameday: got %v want g4", next)
+	}
+
+	last, next = lastAndNextGameday(nil)
+	if last != nil || next != nil {
+		t.Errorf("expected no gamedays, got %v and %v", last, next)
+	}
+}
+
+func TestGetTeamsMarkdown(t *testing.T) {
+	summaries := []TeamSummary{
+		{Team: Team{Name: "sre"}, Members: []TeamMember{{Label: "alice"}, {Label: "bob"}}, Admins: []string{"alice"}},
+		{Team: Team{Name: "dba"}, Members: []TeamMember{{Label: "carol"}}},
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(getTeamsMarkdown(summaries))), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected a row per team, got %q", lines)
+	}
+	if lines[2] != "|sre|2|@alice|-|-|" || lines[3] != "|dba|1|-|-|-|" {
+		t.Errorf("wrong rows: got %q", lines[2:])
+	}
+}
+
+func TestFormatLocalTime(t *testing.T) {
+	// 2021-06-01 10:00:00 UTC
+	at := int64(1622541600)
+	if got := formatLocalTime(at, "Europe/Paris"); got != "2021-06-01 12:00:00 CEST" {
+		t.Errorf("wrong local time: got %s", got)
+	}
+	if got, want := formatLocalTime(at, ""), time.Unix(at, 0).Format(timeLayout); got != want {
+		t.Errorf("expected the server time without timezone: got %s want %s", got, want)
+	}
+	if got, want := formatLocalTime(at, "Mars/Olympus"), time.Unix(at, 0).Format(timeLayout); got != want {
+		t.Errorf("expected the server time for an unknown timezone: got %s want %s", got, want)
+	}
+}
+
+func TestGamedayStateCanBecome(t *testing.T) {
+	tests := []struct {
+		from, to GamedayState
+		want     bool
+	}{
+		{GamedayScheduledState, GamedayInProgressState, true},
+		{GamedayScheduledState, GamedayCancelledState, true},
+		{GamedayScheduledState, GamedayCompletedState, false},
+		{GamedayInProgressState, GamedayCompletedState, true},
+		{GamedayInProgressState, GamedayCancelledState, true},
+		{GamedayInProgressState, GamedayScheduledState, false},
+		{GamedayCancelledState, GamedayCompletedState, false},
+		{GamedayCancelledState, GamedayInProgressState, false},
+		{GamedayCompletedState, GamedayCancelledState, false},
+		{GamedayCompletedState, GamedayCompletedState, false},
+	}
+	for _, tt := range tests {
+		if got := tt.from.canBecome(tt.to); got != tt.want {
+			t.Errorf("%s to %s: got %v want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
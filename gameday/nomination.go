@@ -0,0 +1,565 @@
+package gameday
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrTeamTooSmall when the team doesn't have enough members to fill
+// every role of a gameday with distinct members
+var ErrTeamTooSmall = errors.New("team is too small")
+
+// NominationStrategy picks the member to nominate for a role of a gameday
+// based on the nominations of the team history
+type NominationStrategy interface {
+	// Name the name of the strategy which is recorded on the nominations
+	Name() string
+	// Nominate returns the member to nominate, nil when there are no members
+	Nominate(members []TeamMember, nominations []GamedayNominee, role NomineeRole, rnd *rand.Rand) *TeamMember
+}
+
+const (
+	// RandomStrategy picks any member
+	RandomStrategy = "random"
+	// RoundRobinStrategy picks the members in the order they joined the team
+	RoundRobinStrategy = "round-robin"
+	// LeastRecentStrategy picks the member who served the role least recently
+	LeastRecentStrategy = "least-recent"
+	// WeightedStrategy picks randomly with the less experienced members weighted more
+	WeightedStrategy = "weighted"
+)
+
+// defaultNominationStrategy the strategy of the teams which didn't choose one
+const defaultNominationStrategy = LeastRecentStrategy
+
+// nominationStrategies the available strategies by name
+var nominationStrategies = map[string]NominationStrategy{
+	RandomStrategy:      randomStrategy{},
+	RoundRobinStrategy:  roundRobinStrategy{},
+	LeastRecentStrategy: leastRecentStrategy{},
+	WeightedStrategy:    weightedStrategy{},
+}
+
+// getNominationStrategy returns the strategy by name, the default
+// strategy when the name is unknown
+func getNominationStrategy(name string) NominationStrategy {
+	if strategy, ok := nominationStrategies[strings.ToLower(name)]; ok {
+		return strategy
+	}
+	return nominationStrategies[defaultNominationStrategy]
+}
+
+// isNominationStrategy returns true when the strategy exists
+func isNominationStrategy(name string) bool {
+	_, ok := nominationStrategies[strings.ToLower(name)]
+	return ok
+}
+
+// nominationStrategyNames the sorted names of the available strategies
+func nominationStrategyNames() []string {
+	var names []string
+	for name := range nominationStrategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NominationVerification the nominees of a gameday and the nominees
+// replayed with its seed
+type NominationVerification struct {
+	Gameday  Gameday
+	Strategy string
+	Nominees []GamedayNominee
+	Replayed []GamedayNominee
+}
+
+// IsFair returns true when the replay nominated the same members
+// for the same roles
+func (v NominationVerification) IsFair() bool {
+	if len(v.Nominees) != len(v.Replayed) {
+		return false
+	}
+	for _, r := range v.Replayed {
+		found := false
+		for _, n := range v.Nominees {
+			if n.MemberID == r.MemberID && n.Role == r.Role {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// nominationSnapshot the input of the nomination of a gameday, it's recorded
+// when the gameday is created so the nomination can be replayed as it ran
+// whatever happens to the team afterwards
+type nominationSnapshot struct {
+	Strategy    string               `json:"strategy"`
+	Roles       []snapshotRole       `json:"roles"`
+	Candidates  []snapshotMember     `json:"candidates"`
+	Nominations []snapshotNomination `json:"nominations"`
+}
+
+// snapshotRole a role to fill with the rules in force
+type snapshotRole struct {
+	Name  NomineeRole `json:"name"`
+	Label string      `json:"label"`
+	Rules string      `json:"rules"`
+}
+
+// snapshotMember a member who could be nominated with the profile the
+// strategies and the rules look at
+type snapshotMember struct {
+	ID        string          `json:"id"`
+	UserID    string          `json:"user_id"`
+	Label     string          `json:"label"`
+	Level     ExperienceLevel `json:"level"`
+	Skills    string          `json:"skills"`
+	Timezone  string          `json:"timezone"`
+	CreatedAt int64           `json:"created_at"`
+}
+
+// snapshotNomination a prior nomination of the team
+type snapshotNomination struct {
+	GamedayID   string      `json:"gameday_id"`
+	MemberID    string      `json:"member_id"`
+	Role        NomineeRole `json:"role"`
+	ScheduledAt int64       `json:"scheduled_at"`
+}
+
+// newNominationSnapshot records the input of the nomination
+func newNominationSnapshot(strategy NominationStrategy, candidates []TeamMember, nominations []GamedayNominee, roles []TeamRole) nominationSnapshot {
+	snapshot := nominationSnapshot{Strategy: strategy.Name()}
+	for _, r := range roles {
+		snapshot.Roles = append(snapshot.Roles, snapshotRole{Name: r.Name, Label: r.Label, Rules: r.Rules})
+	}
+	for _, m := range candidates {
+		snapshot.Candidates = append(snapshot.Candidates, snapshotMember{
+			ID:        m.ID,
+			UserID:    m.UserID,
+			Label:     m.Label,
+			Level:     m.Level,
+			Skills:    m.Skills,
+			Timezone:  m.Timezone,
+			CreatedAt: m.CreatedAt,
+		})
+	}
+	for _, n := range nominations {
+		snapshot.Nominations = append(snapshot.Nominations, snapshotNomination{
+			GamedayID:   n.GamedayID,
+			MemberID:    n.MemberID,
+			Role:        n.Role,
+			ScheduledAt: n.Gameday.ScheduledAt,
+		})
+	}
+	return snapshot
+}
+
+// parseNominationSnapshot returns the snapshot recorded on the gameday
+func parseNominationSnapshot(value string) (nominationSnapshot, error) {
+	var snapshot nominationSnapshot
+	if err := json.Unmarshal([]byte(value), &snapshot); err != nil {
+		return snapshot, errors.Wrap(err, "invalid nomination snapshot")
+	}
+	return snapshot, nil
+}
+
+// String returns the snapshot as it's recorded on the gameday
+func (s nominationSnapshot) String() string {
+	value, _ := json.Marshal(s)
+	return string(value)
+}
+
+// replay runs the nomination again on the recorded input with the seed
+func (s nominationSnapshot) replay(seed int64) ([]GamedayNominee, error) {
+	var candidates []TeamMember
+	for _, m := range s.Candidates {
+		candidates = append(candidates, TeamMember{
+			ID:        m.ID,
+			UserID:    m.UserID,
+			Label:     m.Label,
+			Level:     m.Level,
+			Skills:    m.Skills,
+			Timezone:  m.Timezone,
+			CreatedAt: m.CreatedAt,
+		})
+	}
+	var nominations []GamedayNominee
+	for _, n := range s.Nominations {
+		nomination := GamedayNominee{GamedayID: n.GamedayID, MemberID: n.MemberID, Role: n.Role}
+		nomination.Gameday.ID = n.GamedayID
+		nomination.Gameday.ScheduledAt = n.ScheduledAt
+		nominations = append(nominations, nomination)
+	}
+	var roles []TeamRole
+	for _, r := range s.Roles {
+		roles = append(roles, TeamRole{Name: r.Name, Label: r.Label, Rules: r.Rules})
+	}
+	return nominateRoles(getNominationStrategy(s.Strategy), candidates, nominations, roles, rand.New(rand.NewSource(seed)))
+}
+
+// NominationPreview the nominations of a gameday which isn't created
+// yet, with the candidates and the members left out
+type NominationPreview struct {
+	Gameday    Gameday
+	Candidates []TeamMember
+	Exclusions []string
+	Nominees   []GamedayNominee
+}
+
+// nominationsAt returns the nominations which counted at the given time, the
+// gamedays created later and the ones cancelled before are left out
+func nominationsAt(nominations []GamedayNominee, at int64) []GamedayNominee {
+	var results []GamedayNominee
+	for _, n := range nominations {
+		if n.Gameday.CreatedAt >= at {
+			continue
+		}
+		if n.Gameday.State == GamedayCancelledState && n.Gameday.UpdatedAt < at {
+			continue
+		}
+		results = append(results, n)
+	}
+	return results
+}
+
+// membersAt returns the members who joined the team by the given time,
+// sorted by join date so the nominations can be replayed
+func membersAt(members []TeamMember, at int64) []TeamMember {
+	var results []TeamMember
+	for _, m := range members {
+		if m.CreatedAt <= at {
+			results = append(results, m)
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].CreatedAt != results[j].CreatedAt {
+			return results[i].CreatedAt < results[j].CreatedAt
+		}
+		return results[i].ID < results[j].ID
+	})
+	return results
+}
+
+// availableMembers returns the members who aren't away, the away periods
+// registered after the given time are ignored so the nominations can be replayed
+func availableMembers(members []TeamMember, aways []MemberAway, at int64) []TeamMember {
+	away := map[string]bool{}
+	for _, a := range aways {
+		if a.CreatedAt < at {
+			away[a.UserID] = true
+		}
+	}
+	var results []TeamMember
+	for _, m := range members {
+		if !away[m.UserID] {
+			results = append(results, m)
+		}
+	}
+	return results
+}
+
+// unexemptedMembers returns the members who aren't exempted, the exemptions
+// granted after the given time are ignored so the nominations can be replayed
+func unexemptedMembers(members []TeamMember, exemptions []MemberExemption, at int64) []TeamMember {
+	exempted := map[string]bool{}
+	for _, e := range exemptions {
+		if e.CreatedAt < at {
+			exempted[e.MemberID] = true
+		}
+	}
+	var results []TeamMember
+	for _, m := range members {
+		if !exempted[m.ID] {
+			results = append(results, m)
+		}
+	}
+	return results
+}
+
+// unavailableReasons explains why the members who are away or exempted
+// at the given time can't be nominated
+func unavailableReasons(members []TeamMember, aways []MemberAway, exemptions []MemberExemption, at int64) []string {
+	var reasons []string
+	for _, m := range members {
+		for _, a := range aways {
+			if a.UserID == m.UserID && a.CreatedAt < at {
+				reasons = append(reasons, fmt.Sprintf("@%s is away until %s", m.Label, time.Unix(a.EndsAt, 0).Format(timeLayout)))
+				break
+			}
+		}
+		for _, e := range exemptions {
+			if e.MemberID == m.ID && e.CreatedAt < at {
+				reasons = append(reasons, fmt.Sprintf("@%s is exempted until %s: %s", m.Label, time.Unix(e.ExpiresAt, 0).Format(timeLayout), e.Reason))
+				break
+			}
+		}
+	}
+	return reasons
+}
+
+// nominateRoles picks a distinct member for every role of the gameday, a role
+// is listed once per member to nominate and only the members who match its
+// rules are eligible, the reason of each pick is recorded on the nominee
+func nominateRoles(strategy NominationStrategy, members []TeamMember, nominations []GamedayNominee, roles []TeamRole, rnd *rand.Rand) ([]GamedayNominee, error) {
+	if len(members) < len(roles) {
+		return nil, errors.Wrapf(ErrTeamTooSmall, "%d member(s) can't fill %d distinct role(s)", len(members), len(roles))
+	}
+
+	available := make([]TeamMember, len(members))
+	copy(available, members)
+	var nominees []GamedayNominee
+	for _, role := range roles {
+		rules, err := parseRoleRules(role.Rules)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid rules of the role %s", role.Name)
+		}
+		eligible, explanations := eligibleMembers(available, nominations, role.Name, rules)
+		m := strategy.Nominate(eligible, nominations, role.Name, rnd)
+		if m == nil {
+			return nil, errors.Wrapf(ErrTeamTooSmall, "no eligible member left for the role %s", role.Name)
+		}
+		member := *m
+		nominees = append(nominees, GamedayNominee{
+			MemberID:  member.ID,
+			UserID:    member.UserID,
+			Label:     member.Label,
+			Role:      role.Name,
+			RoleLabel: role.Label,
+			Strategy:  strategy.Name(),
+			Reason:    nominationReason(strategy, eligible, explanations),
+		})
+
+		var rest []TeamMember
+		for _, a := range available {
+			if a.ID != member.ID {
+				rest = append(rest, a)
+			}
+		}
+		available = rest
+	}
+	return nominees, nil
+}
+
+// expandRoles returns the comma separated roles of a gameday with each
+// role repeated by its count, all the roles of the team when none is given
+func expandRoles(teamRoles []TeamRole, roles string) (string, error) {
+	var names []NomineeRole
+	if strings.TrimSpace(roles) == "" {
+		for _, r := range teamRoles {
+			names = append(names, r.Name)
+		}
+	} else {
+		names = parseRoles(roles)
+	}
+
+	var expanded []string
+	for _, name := range names {
+		role := findTeamRole(teamRoles, name)
+		if role == nil {
+			return "", errors.Errorf("unknown role %s", name)
+		}
+		for i := 0; i < role.Count; i++ {
+			expanded = append(expanded, string(role.Name))
+		}
+	}
+	if len(expanded) == 0 {
+		return "", errors.New("there aren't any roles to nominate")
+	}
+	return strings.Join(expanded, ","), nil
+}
+
+// gamedayRoles returns a team role for each of the comma separated roles of
+// a gameday, the roles removed from the team since then keep their name
+func gamedayRoles(teamRoles []TeamRole, roles string) []TeamRole {
+	var results []TeamRole
+	for _, name := range parseRoles(roles) {
+		if role := findTeamRole(teamRoles, name); role != nil {
+			results = append(results, *role)
+			continue
+		}
+		if role := findTeamRole(defaultTeamRoles, name); role != nil {
+			results = append(results, *role)
+			continue
+		}
+		results = append(results, TeamRole{Name: name, Label: string(name), Count: 1})
+	}
+	return results
+}
+
+// sortNominees orders the nominees like the comma separated roles of the gameday
+func sortNominees(roles string, nominees []GamedayNominee) {
+	position := map[NomineeRole]int{}
+	for i, role := range parseRoles(roles) {
+		if _, ok := position[role]; !ok {
+			position[role] = i
+		}
+	}
+	sort.SliceStable(nominees, func(i, j int) bool {
+		pi, ok := position[nominees[i].Role]
+		if !ok {
+			pi = len(position)
+		}
+		pj, ok := position[nominees[j].Role]
+		if !ok {
+			pj = len(position)
+		}
+		return pi < pj
+	})
+}
+
+// findTeamRole returns the role by name
+func findTeamRole(roles []TeamRole, name NomineeRole) *TeamRole {
+	for i, r := range roles {
+		if r.Name == name {
+			return &roles[i]
+		}
+	}
+	return nil
+}
+
+// randomStrategy picks any member with the same probability
+type randomStrategy struct{}
+
+func (randomStrategy) Name() string { return RandomStrategy }
+
+func (randomStrategy) Nominate(members []TeamMember, _ []GamedayNominee, _ NomineeRole, rnd *rand.Rand) *TeamMember {
+	if len(members) == 0 {
+		return nil
+	}
+	return &members[rnd.Intn(len(members))]
+}
+
+// roundRobinStrategy picks the member who joined the team after the
+// latest nominee of the role
+type roundRobinStrategy struct{}
+
+func (roundRobinStrategy) Name() string { return RoundRobinStrategy }
+
+func (roundRobinStrategy) Nominate(members []TeamMember, nominations []GamedayNominee, role NomineeRole, _ *rand.Rand) *TeamMember {
+	if len(members) == 0 {
+		return nil
+	}
+	ordered := make([]TeamMember, len(members))
+	copy(ordered, members)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if ordered[i].CreatedAt != ordered[j].CreatedAt {
+			return ordered[i].CreatedAt < ordered[j].CreatedAt
+		}
+		return ordered[i].ID < ordered[j].ID
+	})
+
+	var latest string
+	var latestAt int64
+	for memberID, servedAt := range lastNominations(nominations, role) {
+		if latest == "" || servedAt > latestAt || (servedAt == latestAt && memberID > latest) {
+			latest, latestAt = memberID, servedAt
+		}
+	}
+	for i, m := range ordered {
+		if m.ID == latest {
+			return &ordered[(i+1)%len(ordered)]
+		}
+	}
+	return &ordered[0]
+}
+
+// leastRecentStrategy picks the member who served the role least recently
+type leastRecentStrategy struct{}
+
+func (leastRecentStrategy) Name() string { return LeastRecentStrategy }
+
+func (leastRecentStrategy) Nominate(members []TeamMember, nominations []GamedayNominee, role NomineeRole, rnd *rand.Rand) *TeamMember {
+	return pickLeastRecentMember(members, lastNominations(nominations, role), rnd)
+}
+
+// weightedStrategy picks randomly, the weight of a member is the inverse
+// of the nominations they had for any role so the less experienced
+// members practice more often
+type weightedStrategy struct{}
+
+func (weightedStrategy) Name() string { return WeightedStrategy }
+
+func (weightedStrategy) Nominate(members []TeamMember, nominations []GamedayNominee, _ NomineeRole, rnd *rand.Rand) *TeamMember {
+	if len(members) == 0 {
+		return nil
+	}
+	experience := map[string]int{}
+	for _, n := range nominations {
+		experience[n.MemberID]++
+	}
+
+	weights := make([]float64, len(members))
+	var total float64
+	for i, m := range members {
+		weights[i] = 1 / float64(1+experience[m.ID])
+		total += weights[i]
+	}
+	r := rnd.Float64() * total
+	for i := range members {
+		if r < weights[i] {
+			return &members[i]
+		}
+		r -= weights[i]
+	}
+	return &members[len(members)-1]
+}
+
+// nominatedFor returns true when the nominee served the role
+func nominatedFor(nominee GamedayNominee, role NomineeRole) bool {
+	return nominee.Role == role
+}
+
+// lastNominations returns the schedule of the latest gameday each member
+// was nominated for the role, the members who never served are missing
+func lastNominations(nominations []GamedayNominee, role NomineeRole) map[string]int64 {
+	last := map[string]int64{}
+	for _, n := range nominations {
+		if !nominatedFor(n, role) {
+			continue
+		}
+		if servedAt, ok := last[n.MemberID]; !ok || n.Gameday.ScheduledAt > servedAt {
+			last[n.MemberID] = n.Gameday.ScheduledAt
+		}
+	}
+	return last
+}
+
+// pickLeastRecentMember picks the member who served least recently, the
+// members who never served first, the ties are broken randomly
+func pickLeastRecentMember(members []TeamMember, last map[string]int64, rnd *rand.Rand) *TeamMember {
+	var candidates []TeamMember
+	var oldest int64
+	neverServed := false
+	for _, m := range members {
+		servedAt, served := last[m.ID]
+		switch {
+		case !served && !neverServed:
+			neverServed = true
+			candidates = []TeamMember{m}
+		case !served:
+			candidates = append(candidates, m)
+		case neverServed:
+		case len(candidates) == 0 || servedAt < oldest:
+			oldest = servedAt
+			candidates = []TeamMember{m}
+		case servedAt == oldest:
+			candidates = append(candidates, m)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	return &candidates[rnd.Intn(len(candidates))]
+}
@@ -0,0 +1,593 @@
+package gameday
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func newNomination(memberID string, scheduledAt int64, role NomineeRole) GamedayNominee {
+	return GamedayNominee{
+		MemberID: memberID,
+		Role:     role,
+		Gameday:  Gameday{ScheduledAt: scheduledAt},
+	}
+}
+
+func TestLastNominations(t *testing.T) {
+	nominations := []GamedayNominee{
+		newNomination("alice", 100, MasterOfDisasterRole),
+		newNomination("alice", 300, MasterOfDisasterRole),
+		newNomination("alice", 200, MasterOfDisasterRole),
+		newNomination("bob", 400, OnCallRole),
+	}
+
+	mods := lastNominations(nominations, MasterOfDisasterRole)
+	if len(mods) != 1 || mods["alice"] != 300 {
+		t.Errorf("wrong MoD nominations: got %v want map[alice:300]", mods)
+	}
+	oncalls := lastNominations(nominations, OnCallRole)
+	if len(oncalls) != 1 || oncalls["bob"] != 400 {
+		t.Errorf("wrong On-Call nominations: got %v want map[bob:400]", oncalls)
+	}
+}
+
+func TestLeastRecentStrategy(t *testing.T) {
+	members := []TeamMember{{ID: "alice"}, {ID: "bob"}, {ID: "carol"}}
+
+	tests := []struct {
+		name        string
+		members     []TeamMember
+		nominations []GamedayNominee
+		role        NomineeRole
+		want        []string
+	}{
+		{
+			name:    "no members",
+			members: nil,
+			role:    MasterOfDisasterRole,
+			want:    nil,
+		},
+		{
+			name:    "no history picks any member",
+			members: members,
+			role:    MasterOfDisasterRole,
+			want:    []string{"alice", "bob", "carol"},
+		},
+		{
+			name:    "never served first",
+			members: members,
+			nominations: []GamedayNominee{
+				newNomination("alice", 100, MasterOfDisasterRole),
+				newNomination("bob", 200, MasterOfDisasterRole),
+			},
+			role: MasterOfDisasterRole,
+			want: []string{"carol"},
+		},
+		{
+			name:    "least recently served",
+			members: members,
+			nominations: []GamedayNominee{
+				newNomination("alice", 300, MasterOfDisasterRole),
+				newNomination("bob", 100, MasterOfDisasterRole),
+				newNomination("carol", 200, MasterOfDisasterRole),
+				newNomination("bob", 50, MasterOfDisasterRole),
+			},
+			role: MasterOfDisasterRole,
+			want: []string{"bob"},
+		},
+		{
+			name:    "latest nomination counts",
+			members: members,
+			nominations: []GamedayNominee{
+				newNomination("alice", 100, MasterOfDisasterRole),
+				newNomination("alice", 400, MasterOfDisasterRole),
+				newNomination("bob", 300, MasterOfDisasterRole),
+				newNomination("carol", 200, MasterOfDisasterRole),
+			},
+			role: MasterOfDisasterRole,
+			want: []string{"carol"},
+		},
+		{
+			name:    "ties are broken randomly",
+			members: members,
+			nominations: []GamedayNominee{
+				newNomination("alice", 100, OnCallRole),
+				newNomination("bob", 100, OnCallRole),
+				newNomination("carol", 200, OnCallRole),
+			},
+			role: OnCallRole,
+			want: []string{"alice", "bob"},
+		},
+		{
+			name:    "roles are rotated separately",
+			members: members,
+			nominations: []GamedayNominee{
+				newNomination("alice", 100, MasterOfDisasterRole),
+				newNomination("bob", 200, MasterOfDisasterRole),
+				newNomination("carol", 300, MasterOfDisasterRole),
+				newNomination("alice", 300, OnCallRole),
+				newNomination("bob", 300, OnCallRole),
+			},
+			role: OnCallRole,
+			want: []string{"carol"},
+		},
+		{
+			name:    "former members are ignored",
+			members: members[1:],
+			nominations: []GamedayNominee{
+				newNomination("bob", 200, MasterOfDisasterRole),
+				newNomination("carol", 300, MasterOfDisasterRole),
+			},
+			role: MasterOfDisasterRole,
+			want: []string{"bob"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rnd := rand.New(rand.NewSource(1))
+			picked := map[string]bool{}
+			for i := 0; i < 50; i++ {
+				m := leastRecentStrategy{}.Nominate(tt.members, tt.nominations, tt.role, rnd)
+				if m == nil {
+					picked[""] = true
+					continue
+				}
+				picked[m.ID] = true
+			}
+
+			if tt.want == nil {
+				if len(picked) != 1 || !picked[""] {
+					t.Errorf("expected no member, got %v", picked)
+				}
+				return
+			}
+			if len(picked) != len(tt.want) {
+				t.Errorf("wrong picked members: got %v want %v", picked, tt.want)
+			}
+			for _, id := range tt.want {
+				if !picked[id] {
+					t.Errorf("member %s was never picked: got %v", id, picked)
+				}
+			}
+		})
+	}
+}
+
+func TestLeastRecentStrategyRotation(t *testing.T) {
+	members := []TeamMember{{ID: "alice"}, {ID: "bob"}, {ID: "carol"}}
+	rnd := rand.New(rand.NewSource(1))
+
+	var nominations []GamedayNominee
+	served := map[string]int{}
+	for i := int64(1); i <= 9; i++ {
+		m := leastRecentStrategy{}.Nominate(members, nominations, MasterOfDisasterRole, rnd)
+		served[m.ID]++
+		nominations = append(nominations, newNomination(m.ID, i, MasterOfDisasterRole))
+	}
+	for _, m := range members {
+		if served[m.ID] != 3 {
+			t.Errorf("unfair rotation: got %v want 3 nominations each", served)
+		}
+	}
+}
+
+func TestGetNominationStrategy(t *testing.T) {
+	for _, name := range nominationStrategyNames() {
+		if got := getNominationStrategy(name).Name(); got != name {
+			t.Errorf("wrong strategy: got %s want %s", got, name)
+		}
+	}
+	if got := getNominationStrategy("").Name(); got != defaultNominationStrategy {
+		t.Errorf("wrong default strategy: got %s want %s", got, defaultNominationStrategy)
+	}
+}
+
+func TestRandomStrategy(t *testing.T) {
+	members := []TeamMember{{ID: "alice"}, {ID: "bob"}, {ID: "carol"}}
+	nominations := []GamedayNominee{newNomination("alice", 100, MasterOfDisasterRole)}
+	rnd := rand.New(rand.NewSource(1))
+
+	if m := (randomStrategy{}).Nominate(nil, nil, MasterOfDisasterRole, rnd); m != nil {
+		t.Errorf("expected no member, got %s", m.ID)
+	}
+	picked := map[string]bool{}
+	for i := 0; i < 50; i++ {
+		picked[randomStrategy{}.Nominate(members, nominations, MasterOfDisasterRole, rnd).ID] = true
+	}
+	if len(picked) != len(members) {
+		t.Errorf("every member should be picked: got %v", picked)
+	}
+}
+
+func TestRoundRobinStrategy(t *testing.T) {
+	members := []TeamMember{{ID: "carol", CreatedAt: 3}, {ID: "alice", CreatedAt: 1}, {ID: "bob", CreatedAt: 2}}
+
+	var nominations []GamedayNominee
+	var got []string
+	for i := int64(1); i <= 4; i++ {
+		m := roundRobinStrategy{}.Nominate(members, nominations, OnCallRole, nil)
+		got = append(got, m.ID)
+		nominations = append(nominations, newNomination(m.ID, i, OnCallRole))
+		nominations = append(nominations, newNomination("alice", i, MasterOfDisasterRole))
+	}
+	want := []string{"alice", "bob", "carol", "alice"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("wrong rotation: got %v want %v", got, want)
+			break
+		}
+	}
+
+	former := []GamedayNominee{newNomination("dave", 10, OnCallRole)}
+	if m := (roundRobinStrategy{}).Nominate(members, former, OnCallRole, nil); m.ID != "alice" {
+		t.Errorf("expected the first member after a former member, got %s", m.ID)
+	}
+}
+
+func TestWeightedStrategy(t *testing.T) {
+	members := []TeamMember{{ID: "alice"}, {ID: "bob"}}
+	var nominations []GamedayNominee
+	for i := int64(1); i <= 9; i++ {
+		nominations = append(nominations, newNomination("alice", i, OnCallRole))
+	}
+	rnd := rand.New(rand.NewSource(1))
+
+	picked := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		picked[weightedStrategy{}.Nominate(members, nominations, MasterOfDisasterRole, rnd).ID]++
+	}
+	if picked["bob"] <= picked["alice"]*5 {
+		t.Errorf("the less experienced member should be weighted more: got %v", picked)
+	}
+	if picked["alice"] == 0 {
+		t.Errorf("the experienced member should still be picked: got %v", picked)
+	}
+}
+
+func TestNominateRoles(t *testing.T) {
+	members := []TeamMember{{ID: "alice", UserID: "u1"}, {ID: "bob", UserID: "u2"}}
+	roles := gamedayRoles(defaultTeamRoles, defaultRoles)
+	rnd := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 50; i++ {
+		nominees, err := nominateRoles(randomStrategy{}, members, nil, roles, rnd)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(nominees) != 2 {
+			t.Fatalf("wrong nominees: got %d want 2", len(nominees))
+		}
+		if nominees[0].MemberID == nominees[1].MemberID {
+			t.Fatalf("the same member %s was nominated for both roles", nominees[0].MemberID)
+		}
+		if nominees[0].Role != MasterOfDisasterRole || nominees[1].Role != OnCallRole || nominees[1].RoleLabel != "On-Call" {
+			t.Fatalf("wrong roles: got %+v", nominees)
+		}
+		if nominees[0].Strategy != RandomStrategy {
+			t.Fatalf("wrong strategy: got %s want %s", nominees[0].Strategy, RandomStrategy)
+		}
+	}
+
+	nominees, err := nominateRoles(randomStrategy{}, members[:1], nil, roles[1:], rnd)
+	if err != nil || len(nominees) != 1 || nominees[0].Role != OnCallRole {
+		t.Errorf("a single role should be filled by a single member: got %+v, %v", nominees, err)
+	}
+
+	for _, team := range [][]TeamMember{nil, members[:1]} {
+		if _, err := nominateRoles(randomStrategy{}, team, nil, roles, rnd); errors.Cause(err) != ErrTeamTooSmall {
+			t.Errorf("expected ErrTeamTooSmall for %d member(s), got %v", len(team), err)
+		}
+	}
+}
+
+func TestExpandRoles(t *testing.T) {
+	teamRoles := []TeamRole{
+		{Name: MasterOfDisasterRole, Label: "Master of Disaster", Count: 1},
+		{Name: "scribe", Label: "Scribe", Count: 2},
+	}
+
+	tests := []struct {
+		name  string
+		roles string
+		want  string
+		err   bool
+	}{
+		{"all the roles of the team", "", "mod,scribe,scribe", false},
+		{"selected roles", "scribe", "scribe,scribe", false},
+		{"unknown role", "mod,oncall", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandRoles(teamRoles, tt.roles)
+			if (err != nil) != tt.err {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("wrong roles: got %q want %q", got, tt.want)
+			}
+		})
+	}
+
+	roles := gamedayRoles(teamRoles, "mod,scribe,oncall,observer")
+	if len(roles) != 4 || roles[1].Label != "Scribe" || roles[2].Label != "On-Call" || roles[3].Label != "observer" {
+		t.Errorf("wrong gameday roles: got %+v", roles)
+	}
+}
+
+func TestNominateRolesRules(t *testing.T) {
+	members := []TeamMember{{ID: "alice"}, {ID: "bob"}, {ID: "carol"}}
+	nominations := []GamedayNominee{newNomination("carol", 100, MasterOfDisasterRole)}
+	roles := []TeamRole{
+		{Name: "commander", Label: "Incident Commander", Count: 1, Rules: "served:mod"},
+		{Name: "scribe", Label: "Scribe", Count: 1},
+		{Name: "scribe", Label: "Scribe", Count: 1},
+	}
+
+	for seed := int64(1); seed <= 20; seed++ {
+		nominees, err := nominateRoles(randomStrategy{}, members, nominations, roles, rand.New(rand.NewSource(seed)))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if nominees[0].MemberID != "carol" {
+			t.Fatalf("only carol served as mod: got %s", nominees[0].MemberID)
+		}
+		if nominees[1].Role != "scribe" || nominees[2].Role != "scribe" || nominees[1].MemberID == nominees[2].MemberID {
+			t.Fatalf("wrong scribes: got %+v", nominees[1:])
+		}
+	}
+
+	if _, err := nominateRoles(randomStrategy{}, members, nil, roles, rand.New(rand.NewSource(1))); errors.Cause(err) != ErrTeamTooSmall {
+		t.Errorf("expected ErrTeamTooSmall without eligible member, got %v", err)
+	}
+	if _, err := parseRoleRules("foo:bar"); err == nil {
+		t.Error("expected an error for an unknown rule")
+	}
+}
+
+func TestRoleRules(t *testing.T) {
+	members := []TeamMember{
+		{ID: "alice", Label: "alice", Level: SeniorLevel, Skills: "kubernetes,postgres", Timezone: "Europe/Paris"},
+		{ID: "bob", Label: "bob", Level: JuniorLevel, Skills: "kubernetes", Timezone: "America/New_York"},
+		{ID: "carol", Label: "carol"},
+	}
+	nominations := []GamedayNominee{
+		{MemberID: "alice", GamedayID: "g1", Role: OnCallRole, Gameday: Gameday{ScheduledAt: 100}},
+		{MemberID: "bob", GamedayID: "g2", Role: OnCallRole, Gameday: Gameday{ScheduledAt: 200}},
+		{MemberID: "carol", GamedayID: "g3", Role: MasterOfDisasterRole, Gameday: Gameday{ScheduledAt: 300}},
+	}
+
+	tests := []struct {
+		rules        string
+		want         []string
+		explanations int
+	}{
+		{"", []string{"alice", "bob", "carol"}, 0},
+		{"level:senior", []string{"alice"}, 1},
+		{"level:junior", []string{"alice", "bob"}, 1},
+		{"max-level:junior", []string{"bob", "carol"}, 1},
+		{"skill:Postgres", []string{"alice"}, 1},
+		{"cooldown:2", []string{"alice", "carol"}, 1},
+		{"cooldown:3", []string{"carol"}, 1},
+		{"skill:kubernetes cooldown:2", []string{"alice"}, 2},
+		{"timezone:europe", []string{"alice"}, 1},
+		{"timezone:America/New_York", []string{"bob"}, 1},
+		{"timezone:america/new", []string{}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rules, func(t *testing.T) {
+			rules, err := parseRoleRules(tt.rules)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, explanations := eligibleMembers(members, nominations, OnCallRole, rules)
+			var ids []string
+			for _, m := range got {
+				ids = append(ids, m.ID)
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("wrong eligible members: got %v want %v", ids, tt.want)
+			}
+			if len(explanations) != tt.explanations {
+				t.Errorf("wrong explanations: got %v", explanations)
+			}
+		})
+	}
+
+	for _, rules := range []string{"level:expert", "cooldown:0", "cooldown:x", "skill"} {
+		if _, err := parseRoleRules(rules); err == nil {
+			t.Errorf("expected an error for %s", rules)
+		}
+	}
+}
+
+func TestNominateRolesReason(t *testing.T) {
+	members := []TeamMember{{ID: "alice", Label: "alice", Level: SeniorLevel}, {ID: "bob", Label: "bob", Level: JuniorLevel}}
+	roles := []TeamRole{
+		{Name: MasterOfDisasterRole, Label: "Master of Disaster", Count: 1, Rules: "level:senior"},
+		{Name: OnCallRole, Label: "On-Call", Count: 1, Rules: "max-level:junior"},
+	}
+
+	nominees, err := nominateRoles(leastRecentStrategy{}, members, nil, roles, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if nominees[0].MemberID != "alice" || nominees[1].MemberID != "bob" {
+		t.Fatalf("wrong nominees: got %+v", nominees)
+	}
+	if want := "least-recent pick among 1 eligible member(s); level:senior excluded @bob"; nominees[0].Reason != want {
+		t.Errorf("wrong reason: got %q want %q", nominees[0].Reason, want)
+	}
+	if want := "least-recent pick among 1 eligible member(s)"; nominees[1].Reason != want {
+		t.Errorf("wrong reason: got %q want %q", nominees[1].Reason, want)
+	}
+}
+
+func TestNominationsAt(t *testing.T) {
+	nominations := []GamedayNominee{
+		{MemberID: "before", Gameday: Gameday{CreatedAt: 100, State: GamedayCompletedState}},
+		{MemberID: "after", Gameday: Gameday{CreatedAt: 300, State: GamedayScheduledState}},
+		{MemberID: "cancelled-before", Gameday: Gameday{CreatedAt: 100, UpdatedAt: 150, State: GamedayCancelledState}},
+		{MemberID: "cancelled-after", Gameday: Gameday{CreatedAt: 100, UpdatedAt: 250, State: GamedayCancelledState}},
+	}
+
+	got := nominationsAt(nominations, 200)
+	if len(got) != 2 || got[0].MemberID != "before" || got[1].MemberID != "cancelled-after" {
+		t.Errorf("wrong nominations at 200: got %+v", got)
+	}
+}
+
+func TestMembersAt(t *testing.T) {
+	members := []TeamMember{{ID: "late", CreatedAt: 300}, {ID: "bob", CreatedAt: 100}, {ID: "alice", CreatedAt: 100}, {ID: "carol", CreatedAt: 50}}
+
+	got := membersAt(members, 200)
+	want := []string{"carol", "alice", "bob"}
+	if len(got) != len(want) {
+		t.Fatalf("wrong members at 200: got %+v", got)
+	}
+	for i := range want {
+		if got[i].ID != want[i] {
+			t.Errorf("wrong members order: got %+v want %v", got, want)
+			break
+		}
+	}
+}
+
+func TestNominateRolesReplay(t *testing.T) {
+	members := []TeamMember{{ID: "alice"}, {ID: "bob"}, {ID: "carol"}, {ID: "dave"}}
+	nominations := []GamedayNominee{newNomination("alice", 100, MasterOfDisasterRole)}
+	roles := gamedayRoles(defaultTeamRoles, defaultRoles)
+
+	for _, name := range nominationStrategyNames() {
+		strategy := getNominationStrategy(name)
+		for seed := int64(1); seed <= 20; seed++ {
+			nominees, err := nominateRoles(strategy, members, nominations, roles, rand.New(rand.NewSource(seed)))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			replayed, err := nominateRoles(strategy, members, nominations, roles, rand.New(rand.NewSource(seed)))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			v := NominationVerification{Nominees: nominees, Replayed: replayed}
+			if !v.IsFair() {
+				t.Errorf("%s with seed %d replayed different nominees: got %+v want %+v", name, seed, replayed, nominees)
+			}
+		}
+	}
+
+	v := NominationVerification{
+		Nominees: []GamedayNominee{{MemberID: "alice", Role: MasterOfDisasterRole}, {MemberID: "bob", Role: OnCallRole}},
+		Replayed: []GamedayNominee{{MemberID: "bob", Role: MasterOfDisasterRole}, {MemberID: "alice", Role: OnCallRole}},
+	}
+	if v.IsFair() {
+		t.Error("swapped roles should not verify")
+	}
+}
+
+func TestNominationSnapshotReplay(t *testing.T) {
+	members := []TeamMember{
+		{ID: "alice", Label: "alice", Level: "senior", CreatedAt: 100},
+		{ID: "bob", Label: "bob", Level: "junior", CreatedAt: 200},
+		{ID: "carol", Label: "carol", Level: "senior", CreatedAt: 300},
+		{ID: "dave", Label: "dave", Level: "junior", CreatedAt: 400},
+	}
+	nominations := []GamedayNominee{newNomination("alice", 100, MasterOfDisasterRole), newNomination("bob", 100, OnCallRole)}
+	roles := []TeamRole{
+		{Name: MasterOfDisasterRole, Label: "Master of Disaster", Count: 1, Rules: "level:senior"},
+		{Name: OnCallRole, Label: "On-Call", Count: 1},
+	}
+
+	for _, name := range nominationStrategyNames() {
+		strategy := getNominationStrategy(name)
+		for seed := int64(1); seed <= 20; seed++ {
+			nominees, err := nominateRoles(strategy, members, nominations, roles, rand.New(rand.NewSource(seed)))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			snapshot, err := parseNominationSnapshot(newNominationSnapshot(strategy, members, nominations, roles).String())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			replayed, err := snapshot.replay(seed)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			v := NominationVerification{Nominees: nominees, Replayed: replayed}
+			if !v.IsFair() {
+				t.Errorf("%s with seed %d replayed different nominees: got %+v want %+v", name, seed, replayed, nominees)
+			}
+		}
+	}
+
+	if _, err := parseNominationSnapshot("not json"); err == nil {
+		t.Error("expected an error for an invalid snapshot")
+	}
+}
+
+func TestAvailableMembers(t *testing.T) {
+	members := []TeamMember{{ID: "m1", UserID: "alice"}, {ID: "m2", UserID: "bob"}, {ID: "m3", UserID: "carol"}}
+	aways := []MemberAway{
+		{UserID: "alice", CreatedAt: 100},
+		{UserID: "carol", CreatedAt: 300},
+	}
+
+	got := availableMembers(members, aways, 200)
+	if len(got) != 2 || got[0].UserID != "bob" || got[1].UserID != "carol" {
+		t.Errorf("wrong available members: got %+v", got)
+	}
+}
+
+func TestUnexemptedMembers(t *testing.T) {
+	members := []TeamMember{{ID: "m1", UserID: "alice"}, {ID: "m2", UserID: "bob"}, {ID: "m3", UserID: "carol"}}
+	exemptions := []MemberExemption{
+		{MemberID: "m2", CreatedAt: 100},
+		{MemberID: "m3", CreatedAt: 300},
+	}
+
+	got := unexemptedMembers(members, exemptions, 200)
+	if len(got) != 2 || got[0].UserID != "alice" || got[1].UserID != "carol" {
+		t.Errorf("wrong unexempted members: got %+v", got)
+	}
+}
+
+func TestUnavailableReasons(t *testing.T) {
+	members := []TeamMember{{ID: "m1", UserID: "alice", Label: "alice"}, {ID: "m2", UserID: "bob", Label: "bob"}, {ID: "m3", UserID: "carol", Label: "carol"}}
+	aways := []MemberAway{{UserID: "alice", EndsAt: 1000, CreatedAt: 100}, {UserID: "carol", EndsAt: 1000, CreatedAt: 300}}
+	exemptions := []MemberExemption{{MemberID: "m2", Reason: "parental leave", ExpiresAt: 1000, CreatedAt: 100}}
+
+	got := unavailableReasons(members, aways, exemptions, 200)
+	if len(got) != 2 || !strings.HasPrefix(got[0], "@alice is away") || !strings.HasSuffix(got[1], ": parental leave") {
+		t.Errorf("wrong reasons: got %v", got)
+	}
+}
+
+func TestNominationsOfRemovedMembers(t *testing.T) {
+	svc := newTestService(t)
+	teamID, gamedayID := newTestTeam(t, svc, "alice", "bob")
+	alice, err := svc.repo.GetMember(teamID, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.repo.DeleteMember(alice.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	nominees, err := svc.repo.ListGamedayNominees(gamedayID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nominees) != 1 || nominees[0].MemberID != alice.ID || nominees[0].UserID != "" || nominees[0].Label != removedMemberLabel {
+		t.Errorf("expected the nominee of the removed member to be kept, got %+v", nominees)
+	}
+	nominations, err := svc.repo.ListTeamNominations(teamID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nominations) != 1 || nominations[0].MemberID != alice.ID {
+		t.Errorf("expected the nomination of the removed member to be kept, got %+v", nominations)
+	}
+}
@@ -0,0 +1,211 @@
+package gameday
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/apps/mmclient"
+	"github.com/pkg/errors"
+)
+
+// Permission who is allowed to make a call, the system admins are
+// allowed to make every call
+type Permission int
+
+const (
+	// PermissionUser any Mattermost user
+	PermissionUser Permission = iota
+	// PermissionMember the members and the admins of the team
+	PermissionMember
+	// PermissionTeamAdmin the owner and the admins of the team
+	PermissionTeamAdmin
+	// PermissionSystemAdmin the system admins of Mattermost
+	PermissionSystemAdmin
+)
+
+// notPermittedError the acting user isn't allowed to make the call
+type notPermittedError struct {
+	reason string
+}
+
+func (e notPermittedError) Error() string {
+	return e.reason
+}
+
+func notPermitted(format string, args ...interface{}) error {
+	return notPermittedError{reason: fmt.Sprintf(format, args...)}
+}
+
+// IsNotPermitted returns true when the error was caused by a
+// missing permission
+func IsNotPermitted(err error) bool {
+	_, ok := errors.Cause(err).(notPermittedError)
+	return ok
+}
+
+// isSystemAdmin returns true when the acting user has the system admin
+// role in Mattermost
+func isSystemAdmin(ctx *apps.Context) bool {
+	if ctx == nil || ctx.ActingUserID == "" {
+		return false
+	}
+	user, _ := mmclient.AsBot(ctx).GetUser(ctx.ActingUserID, "")
+	return user != nil && user.IsSystemAdmin()
+}
+
+// AuthorizeSystemAdmin returns a not permitted error when the acting user
+// isn't a system admin
+func AuthorizeSystemAdmin(ctx *apps.Context) error {
+	if !isSystemAdmin(ctx) {
+		return notPermitted("only the system admins can do this")
+	}
+	return nil
+}
+
+// Authorize returns a not permitted error when the acting user doesn't
+// have the permission, the team is only required by the team permissions
+func (s *Service) Authorize(ctx *apps.Context, permission Permission, teamID string) error {
+	var team *Team
+	if permission == PermissionMember || permission == PermissionTeamAdmin {
+		var err error
+		if team, err = s.repo.GetTeamByID(teamID); err != nil {
+			return errors.Wrap(err, "failed to get team in repository")
+		}
+		if team == nil {
+			return errors.New("the team doesn't exist")
+		}
+	}
+	return s.authorize(ctx, permission, team)
+}
+
+// AuthorizeTeamName authorizes the acting user on the team with the name
+func (s *Service) AuthorizeTeamName(ctx *apps.Context, permission Permission, name string) error {
+	team, err := s.repo.GetTeam(strings.TrimSpace(name))
+	if err != nil {
+		return errors.Wrap(err, "failed to get a team in repository")
+	}
+	if team == nil {
+		return errors.Errorf("team %s doesn't exist", name)
+	}
+	return s.authorize(ctx, permission, team)
+}
+
+// AuthorizeGameday authorizes the acting user on the team of the gameday
+func (s *Service) AuthorizeGameday(ctx *apps.Context, permission Permission, gamedayID string) error {
+	gameday, err := s.repo.GetGameday(gamedayID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get gameday in repository for GamedayID: %s", gamedayID)
+	}
+	if gameday == nil {
+		return errors.Errorf("gameday %s doesn't exist", gamedayID)
+	}
+	return s.Authorize(ctx, permission, gameday.TeamID)
+}
+
+// AuthorizeTemplate authorizes the acting user on the team of the template
+func (s *Service) AuthorizeTemplate(ctx *apps.Context, permission Permission, name string) error {
+	template, err := s.repo.GetTemplate(strings.ToLower(strings.TrimSpace(name)))
+	if err != nil {
+		return errors.Wrap(err, "failed to get gameday template in repository")
+	}
+	if template == nil {
+		return errors.Errorf("gameday template %s doesn't exist", name)
+	}
+	return s.Authorize(ctx, permission, template.TeamID)
+}
+
+// AuthorizeSwap authorizes the acting user to hand over the role of the
+// nominee, the members only hand over their own nominations
+func (s *Service) AuthorizeSwap(ctx *apps.Context, dto SwapNomineeDTO) error {
+	gameday, nominees, err := s.GetGameday(dto.ID.Value)
+	if err != nil {
+		return err
+	}
+	permission := PermissionTeamAdmin
+	if ctx != nil {
+		nominee, err := selectNominee(gameday, nominees, NomineeRole(dto.Role.Value), dto.Member.UserID)
+		if err != nil {
+			return err
+		}
+		if nominee.UserID == ctx.ActingUserID {
+			permission = PermissionMember
+		}
+	}
+	return s.Authorize(ctx, permission, gameday.TeamID)
+}
+
+func (s *Service) authorize(ctx *apps.Context, permission Permission, team *Team) error {
+	if ctx == nil || ctx.ActingUserID == "" {
+		return notPermitted("the acting user is unknown")
+	}
+	switch permission {
+	case PermissionUser:
+		return nil
+	case PermissionSystemAdmin:
+		return AuthorizeSystemAdmin(ctx)
+	}
+
+	// the team is checked first so Mattermost is only asked for the roles
+	// of the users who aren't in the team
+	admin, err := s.isTeamAdmin(*team, ctx.ActingUserID)
+	if err != nil {
+		return err
+	}
+	if admin {
+		return nil
+	}
+	if permission == PermissionMember {
+		member, err := s.repo.GetMember(team.ID, ctx.ActingUserID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get team member in repository")
+		}
+		if member != nil {
+			return nil
+		}
+	}
+	if isSystemAdmin(ctx) {
+		return nil
+	}
+	if permission == PermissionTeamAdmin {
+		return notPermitted("only the admins of team %s can do this", team.Name)
+	}
+	return notPermitted("only the members of team %s can do this", team.Name)
+}
+
+// isTeamAdmin returns true when the user owns the team or was made
+// one of its admins
+func (s *Service) isTeamAdmin(team Team, userID string) (bool, error) {
+	if team.OwnerID != "" && team.OwnerID == userID {
+		return true, nil
+	}
+	admins, err := s.repo.ListTeamAdmins(team.ID)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get team admins in repository")
+	}
+	for _, a := range admins {
+		if a.UserID == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// listTeamAdminIDs returns the owner of the team followed by the users
+// who were made its admins, each user once
+func (s *Service) listTeamAdminIDs(team Team) ([]string, error) {
+	admins, err := s.repo.ListTeamAdmins(team.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get team admins in repository")
+	}
+	var ids []string
+	if team.OwnerID != "" {
+		ids = append(ids, team.OwnerID)
+	}
+	for _, a := range admins {
+		if a.UserID != team.OwnerID {
+			ids = append(ids, a.UserID)
+		}
+	}
+	return ids, nil
+}
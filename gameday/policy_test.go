@@ -0,0 +1,76 @@
+package gameday
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/pkg/errors"
+)
+
+func TestIsNotPermitted(t *testing.T) {
+	err := notPermitted("only the admins of team %s can do this", "sre")
+	if !IsNotPermitted(err) || !IsNotPermitted(errors.Wrap(err, "failed to rename team")) {
+		t.Errorf("expected a not permitted error, got %v", err)
+	}
+	if err.Error() != "only the admins of team sre can do this" {
+		t.Errorf("wrong message: %s", err)
+	}
+	if IsNotPermitted(errors.New("team sre doesn't exist")) {
+		t.Error("expected a regular error to be permitted")
+	}
+}
+
+func TestAuthorizeUser(t *testing.T) {
+	svc := &Service{}
+	if err := svc.Authorize(&apps.Context{ActingUserID: "alice"}, PermissionUser, ""); err != nil {
+		t.Errorf("expected any user to be permitted, got %v", err)
+	}
+	if err := svc.Authorize(nil, PermissionUser, ""); !IsNotPermitted(err) {
+		t.Errorf("expected an unknown user not to be permitted, got %v", err)
+	}
+	if err := svc.Authorize(&apps.Context{}, PermissionUser, ""); !IsNotPermitted(err) {
+		t.Errorf("expected a call without acting user not to be permitted, got %v", err)
+	}
+}
+
+func TestHandlersRefuseMembers(t *testing.T) {
+	svc := newTestService(t)
+	server := newTestMattermost(t, "root")
+	teamID, gamedayID := newTestTeam(t, svc, "alice", "bob")
+	team := map[string]string{"label": "sre", "value": teamID}
+	gameday := map[string]string{"value": gamedayID}
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		values  map[string]interface{}
+	}{
+		{"create", handleCreateGameday(svc, logger), map[string]interface{}{"name": "DB failover", "team": team, "schedule_at": "2030-01-01 10:00:00"}},
+		{"cancel", handleCancelGameDay(svc, logger), map[string]interface{}{"id": gameday, "reason": "no reason"}},
+		{"configure team", handleConfigureTeam(svc, logger), map[string]interface{}{"team": team, "strategy": map[string]string{"value": RandomStrategy}}},
+		{"configure app", HandleConfigure(mux.NewRouter(), NewRuntime(), logger), map[string]interface{}{"scheme": "sqlite3", "url": "sqlite3://refused.db"}},
+		{"import", handleImport(svc, logger), map[string]interface{}{"format": map[string]string{"value": "csv"}, "content": "sre,bob,Chaos,2030-01-01 10:00:00"}},
+		{"swap", handleSwapNominee(svc, logger), map[string]interface{}{"id": gameday, "role": map[string]string{"value": string(OnCallRole)}, "with": map[string]string{"label": "bob", "value": "bob"}}},
+		{"exemption", handleAddExemption(svc, logger), map[string]interface{}{"team": team, "member": map[string]string{"label": "bob", "value": "bob"}, "reason": "on leave", "until": "2030-01-01 10:00:00"}},
+		{"calendar rotate", handleCalendarRotate(svc, logger), map[string]interface{}{"team": team}},
+		{"calendar revoke", handleCalendarRevoke(svc, logger), map[string]interface{}{"team": team}},
+		{"team delete", handleDeleteTeam(svc, logger), map[string]interface{}{"team": team, "force": true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := callHandler(t, tt.handler, server, "bob", tt.values)
+			if resp.Type != apps.CallResponseTypeError || !strings.HasPrefix(resp.ErrorText, "Not permitted") {
+				t.Errorf("expected a member not to be permitted, got %+v", resp)
+			}
+		})
+	}
+
+	// the system admins are permitted to run the same calls
+	if resp := callHandler(t, handleDeleteTeam(svc, logger), server, "root", map[string]interface{}{"team": team, "force": true}); resp.Type != apps.CallResponseTypeOK {
+		t.Errorf("expected a system admin to delete the team, got %+v", resp)
+	}
+}
@@ -0,0 +1,157 @@
+package gameday
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-server/v5/model"
+)
+
+// gamedayActionState the state sent back by the action buttons
+// of a gameday post
+type gamedayActionState struct {
+	ID       string       `json:"id"`
+	Response RSVPResponse `json:"response,omitempty"`
+}
+
+// newGamedayPost creates the interactive post for a gameday with the
+// actions which are available for its current state
+func newGamedayPost(appID apps.AppID, gameday Gameday, nominees []GamedayNominee) *model.Post {
+	post := &model.Post{
+		ChannelId: gameday.ChannelID,
+	}
+	post.AddProp(apps.PropAppBindings, []*apps.Binding{
+		{
+			AppID:       appID,
+			Location:    "gameday",
+			Label:       fmt.Sprintf("Gameday: %s", gameday.Title),
+			Description: getGamedayDescription(gameday, nominees),
+			Bindings:    gamedayActions(gameday),
+		},
+	})
+	return post
+}
+
+// gamedayActions returns the buttons which are valid for the state
+// of the gameday
+func gamedayActions(gameday Gameday) []*apps.Binding {
+	newAction := func(action, label string) *apps.Binding {
+		return &apps.Binding{
+			Location: apps.Location(action),
+			Label:    label,
+			Call: &apps.Call{
+				Path:  fmt.Sprintf("/api/v1/gamedays/%s", action),
+				State: gamedayActionState{ID: gameday.ID},
+			},
+		}
+	}
+
+	var actions []*apps.Binding
+	switch gameday.State {
+	case GamedayScheduledState:
+		actions = append(actions, newAction("start", "Start"), newAction("cancel", "Cancel"))
+	case GamedayInProgressState:
+		actions = append(actions, newAction("complete", "Complete"), newAction("cancel", "Cancel"))
+	}
+	return append(actions, newAction("show", "Show"))
+}
+
+// getGamedayDescription markdown with the details of a gameday
+func getGamedayDescription(gameday Gameday, nominees []GamedayNominee) string {
+	var roles []string
+	labels := map[string][]string{}
+	var strategy string
+	for _, n := range nominees {
+		if n.Strategy != "" {
+			strategy = n.Strategy
+		}
+		role := getNomineeRole(n)
+		if _, ok := labels[role]; !ok {
+			roles = append(roles, role)
+		}
+		labels[role] = append(labels[role], fmt.Sprintf("@%s", n.Label))
+	}
+
+	txt := fmt.Sprintf("**Team:** %s\n", gameday.Team.Name)
+	txt += fmt.Sprintf("**Scheduled At:** %s\n", time.Unix(gameday.ScheduledAt, 0).Format(timeLayout))
+	txt += fmt.Sprintf("**Duration:** %d minutes\n", gameday.Duration)
+	txt += fmt.Sprintf("**State:** %s\n", gameday.State)
+	if gameday.Reason != "" {
+		txt += fmt.Sprintf("**Reason:** %s\n", gameday.Reason)
+	}
+	for _, role := range roles {
+		txt += fmt.Sprintf("**%s:** %s\n", role, strings.Join(labels[role], ", "))
+	}
+	if strategy != "" {
+		txt += fmt.Sprintf("**Nomination Strategy:** %s\n", strategy)
+	}
+	if gameday.Scenarios != "" {
+		txt += fmt.Sprintf("**Scenarios:** %s\n", gameday.Scenarios)
+	}
+	if gameday.Checklist != "" {
+		txt += fmt.Sprintf("**Checklist:** %s\n", gameday.Checklist)
+	}
+	return txt
+}
+
+// newAcknowledgementPost creates the message sent to a nominee with
+// the button to acknowledge the role
+func newAcknowledgementPost(appID apps.AppID, gameday Gameday, message string) *model.Post {
+	post := &model.Post{
+		Message: message,
+	}
+	post.AddProp(apps.PropAppBindings, []*apps.Binding{
+		{
+			AppID:       appID,
+			Location:    "acknowledge",
+			Label:       "Please acknowledge your role",
+			Description: fmt.Sprintf("Team: **%s**", gameday.Team.Name),
+			Bindings: []*apps.Binding{
+				{
+					Location: "acknowledge",
+					Label:    "Acknowledge",
+					Call: &apps.Call{
+						Path:  "/api/v1/gamedays/acknowledge",
+						State: gamedayActionState{ID: gameday.ID},
+					},
+				},
+			},
+		},
+	})
+	return post
+}
+
+// newRSVPPost creates the invitation sent to the team members with
+// the buttons to respond whether they attend the gameday, the time
+// is shown in the timezone of the member
+func newRSVPPost(appID apps.AppID, gameday Gameday, timezone string) *model.Post {
+	newResponse := func(response RSVPResponse, label string) *apps.Binding {
+		return &apps.Binding{
+			Location: apps.Location(response),
+			Label:    label,
+			Call: &apps.Call{
+				Path:  "/api/v1/gamedays/rsvp",
+				State: gamedayActionState{ID: gameday.ID, Response: response},
+			},
+		}
+	}
+
+	post := &model.Post{
+		Message: fmt.Sprintf("Gameday: **%s** is scheduled for %s", strings.ToUpper(gameday.Title), formatLocalTime(gameday.ScheduledAt, timezone)),
+	}
+	post.AddProp(apps.PropAppBindings, []*apps.Binding{
+		{
+			AppID:       appID,
+			Location:    "rsvp",
+			Label:       "Will you attend?",
+			Description: fmt.Sprintf("Team: **%s**", gameday.Team.Name),
+			Bindings: []*apps.Binding{
+				newResponse(RSVPAttending, "Attending"),
+				newResponse(RSVPDeclined, "Can't attend"),
+			},
+		},
+	})
+	return post
+}
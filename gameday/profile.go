@@ -0,0 +1,96 @@
+package gameday
+
+import (
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/apps/mmclient"
+	"github.com/pkg/errors"
+)
+
+// newMember returns the member of the team for the user, the profile is
+// copied from the other teams of the user and the timezone defaults to
+// the one of their Mattermost account, the level and the skills are left
+// to the admins of the team
+func (s *Service) newMember(ctx *apps.Context, teamID, userID, label string) (TeamMember, error) {
+	member := TeamMember{TeamID: teamID, UserID: userID, Label: label, Notifications: NotifyAll}
+	members, err := s.repo.ListUserMembers(userID)
+	if err != nil {
+		return member, errors.Wrap(err, "failed to get the memberships in repository")
+	}
+	if len(members) > 0 {
+		p := members[0].profile()
+		member.Timezone, member.Notifications = p.Timezone, p.Notifications
+	}
+	if member.Timezone == "" {
+		member.Timezone = mattermostTimezone(ctx, userID)
+	}
+	return member, nil
+}
+
+// mattermostTimezone returns the timezone the user chose in Mattermost,
+// empty when it's unknown
+func mattermostTimezone(ctx *apps.Context, userID string) string {
+	if ctx == nil {
+		return ""
+	}
+	user, _ := mmclient.AsBot(ctx).GetUser(userID, "")
+	if user == nil {
+		return ""
+	}
+	return user.GetPreferredTimezone()
+}
+
+// createMember adds the user to the team with their profile
+func (s *Service) createMember(ctx *apps.Context, teamID, userID, label string) error {
+	member, err := s.newMember(ctx, teamID, userID, label)
+	if err != nil {
+		return err
+	}
+	return s.repo.CreateMember(member)
+}
+
+// memberTimezones returns the timezone of every member by user
+func memberTimezones(members []TeamMember) map[string]string {
+	timezones := make(map[string]string)
+	for _, m := range members {
+		timezones[m.UserID] = m.Timezone
+	}
+	return timezones
+}
+
+// timezoneOf returns the timezone of the member of the team, empty when
+// the user isn't a member anymore
+func (s *Service) timezoneOf(teamID, userID string) string {
+	member, _ := s.repo.GetMember(teamID, userID)
+	if member == nil {
+		return ""
+	}
+	return member.Timezone
+}
+
+// Profile responsible to show the profile of the acting user and to update
+// it in every team they are member of, the fields which aren't set are kept
+func (s *Service) Profile(ctx *apps.Context, dto ProfileDTO) ([]TeamMember, error) {
+	members, err := s.repo.ListUserMembers(ctx.ActingUserID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the memberships in repository")
+	}
+	if len(members) == 0 {
+		return nil, errors.New("you aren't a member of any team, ask a team admin to add you")
+	}
+	profile := dto.apply(members[0].profile())
+	if profile.Timezone == "" {
+		// the members added before the profiles get the timezone of Mattermost
+		profile.Timezone = mattermostTimezone(ctx, ctx.ActingUserID)
+	}
+	if profile == members[0].profile() {
+		return members, nil
+	}
+
+	if err := s.repo.UpdateMemberProfile(ctx.ActingUserID, profile); err != nil {
+		return nil, errors.Wrap(err, "failed to update the profile in repository")
+	}
+	for i := range members {
+		members[i].Timezone, members[i].Notifications = profile.Timezone, profile.Notifications
+	}
+	return members, nil
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
 	"github.com/mattermost/mattermost-app-chaosengine/store"
 	"github.com/pkg/errors"
 )
@@ -16,10 +17,21 @@ const gamedayTableName = "gameday"
 const teamTableName = "team"
 const memberTableName = "team_member"
 const nomineeTableName = "gameday_nominee"
+const subscriptionTableName = "subscription"
+const rsvpTableName = "gameday_rsvp"
+const templateTableName = "gameday_template"
+const calendarTokenTableName = "calendar_token"
+const historyTableName = "gameday_history"
+const awayTableName = "member_away"
+const roleTableName = "team_role"
+const exemptionTableName = "member_exemption"
+const adminTableName = "team_admin"
 
 // Repository stores a gameday
 type Repository struct {
 	store *store.SQL
+	// tx the transaction the repository is bound to, nil outside of Transaction
+	tx *sqlx.Tx
 }
 
 // GamedayRepository contract interface for gameday
@@ -27,15 +39,70 @@ type Repository struct {
 type GamedayRepository interface {
 	ListGamedays() ([]Gameday, error)
 	ListGamedaysByState(states []string) ([]Gameday, error)
+	ListGamedaysByTeam(teamID string) ([]Gameday, error)
+	ListGamedaysByUser(userID string) ([]Gameday, error)
+	GetGameday(id string) (*Gameday, error)
 	CreateGameday(gameday Gameday) (string, error)
-	UpdateGamedayState(gamedayID string, state GamedayState) error
-	CreateTeam(name string) (string, error)
-	CreateMember(teamID, userID, label string) error
+	CreateGamedayWithNominees(gameday Gameday, nominees []GamedayNominee) (string, error)
+	UpdateGamedayState(gamedayID string, state GamedayState, reason string) error
+	UpdateNomineeMember(nomineeID, memberID, reason string) error
+	AcknowledgeNominee(nomineeID string) error
+	MarkNomineeReminded(nomineeID string) error
+	MarkNomineeEscalated(nomineeID string) error
+	CreateHistory(history GamedayHistory) error
+	ListGamedayHistory(gamedayID string) ([]GamedayHistory, error)
+	UpdateGamedaySchedule(gamedayID string, scheduledAt int64) error
+	CreateAway(away MemberAway) error
+	ListAwayByUser(userID string, after int64) ([]MemberAway, error)
+	ListAwayBetween(from, to int64) ([]MemberAway, error)
+	CreateExemption(exemption MemberExemption) error
+	ListTeamExemptions(teamID string, after int64) ([]MemberExemption, error)
+	DeleteExemptions(memberID string) error
+	UpdateGamedayPost(gamedayID, channelID, postID string) error
+	CreateTeam(name, ownerID string) (string, error)
+	CreateMember(member TeamMember) error
+	GetMember(teamID, userID string) (*TeamMember, error)
+	ListUserMembers(userID string) ([]TeamMember, error)
+	UpdateMemberAttributes(memberID string, level ExperienceLevel, skills string) error
+	UpdateMemberProfile(userID string, profile MemberProfile) error
 	CreateNominee(nominee GamedayNominee) (string, error)
 	ListGamedayNominees(gamedayID string) ([]GamedayNominee, error)
+	ListTeamNominations(teamID string) ([]GamedayNominee, error)
 	ListTeams(id string) ([]TeamMember, error)
 	GetTeam(name string) (*Team, error)
+	GetTeamByID(id string) (*Team, error)
+	UpdateTeamStrategy(id, strategy string) error
+	RenameTeam(id, name string) error
+	DeleteTeam(id string) error
+	DeleteMember(memberID string) error
+	DeleteNominee(nomineeID string) error
+	UpdateTeamAcknowledgement(id string, remindAfter, escalateBefore int64, escalation string) error
+	UpdateTeamSync(id, source, sourceID, sourceName string) error
+	MarkTeamSynced(id string) error
+	ListAllTeams() ([]Team, error)
+	CreateTeamAdmin(admin TeamAdmin) error
+	ListTeamAdmins(teamID string) ([]TeamAdmin, error)
+	DeleteTeamAdmin(teamID, userID string) error
+	ListTeamRoles(teamID string) ([]TeamRole, error)
+	SaveTeamRole(role TeamRole) error
+	DeleteTeamRole(teamID string, name NomineeRole) error
 	GetTeams() ([]TeamMember, error)
+	SaveSubscription(subscription Subscription) error
+	DeleteSubscription(teamID, channelID string) error
+	ListSubscriptionsByTeam(teamID string) ([]Subscription, error)
+	ListSubscriptionsByChannel(channelID string) ([]Subscription, error)
+	SaveRSVP(gamedayID, memberID string, response RSVPResponse) error
+	MarkAttendance(gamedayID, memberID string) error
+	ListGamedayRSVPs(gamedayID string) ([]GamedayRSVP, error)
+	CreateTemplate(template GamedayTemplate) error
+	UpdateTemplate(template GamedayTemplate) error
+	GetTemplate(name string) (*GamedayTemplate, error)
+	ListTemplates() ([]GamedayTemplate, error)
+	GetCalendarToken(teamID, userID string) (*CalendarToken, error)
+	GetCalendarTokenByToken(token string) (*CalendarToken, error)
+	CreateCalendarToken(teamID, userID string) (string, error)
+	DeleteCalendarToken(teamID, userID string) error
+	Transaction(fn func(repo GamedayRepository) error) error
 }
 
 // NewRepository factory method to create repository
@@ -45,6 +112,43 @@ func NewRepository(store *store.SQL) *Repository {
 	}
 }
 
+// Transaction runs fn with a repository bound to a single transaction,
+// nothing is saved unless fn succeeds
+func (r *Repository) Transaction(fn func(repo GamedayRepository) error) error {
+	return r.transaction(func(tx *sqlx.Tx) error {
+		return fn(&Repository{store: r.store, tx: tx})
+	})
+}
+
+// transaction runs fn in the transaction the repository is bound to, or
+// in a new one which is committed when fn succeeds
+func (r *Repository) transaction(fn func(tx *sqlx.Tx) error) error {
+	if r.tx != nil {
+		return fn(r.tx)
+	}
+	tx, err := r.store.DB.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback() // nolint
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+	return nil
+}
+
+// db returns the transaction the repository is bound to or the database
+func (r *Repository) db() sqlx.Ext {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.store.DB
+}
+
 // ListGamedays returns the list of gamedays created in the app
 func (r *Repository) ListGamedays() ([]Gameday, error) {
 	sql := `SELECT
@@ -55,7 +159,7 @@ func (r *Repository) ListGamedays() ([]Gameday, error) {
 	  WHERE gameday.state IN ('scheduled', 'in_progress');`
 
 	var gamedays []Gameday
-	if err := r.store.DB.Select(&gamedays, sql); err != nil {
+	if err := sqlx.Select(r.db(), &gamedays, sql); err != nil {
 		return []Gameday{}, errors.Wrap(err, "failed to get gamedays")
 	}
 	return gamedays, nil
@@ -73,50 +177,165 @@ func (r *Repository) ListGamedaysByState(states []string) ([]Gameday, error) {
 	sql = fmt.Sprintf(sql, commaSepState)
 
 	var gamedays []Gameday
-	if err := r.store.DB.Select(&gamedays, sql); err != nil {
+	if err := sqlx.Select(r.db(), &gamedays, sql); err != nil {
+		return []Gameday{}, errors.Wrap(err, "failed to get gamedays")
+	}
+	return gamedays, nil
+}
+
+// ListGamedaysByTeam returns every gameday of the given team
+func (r *Repository) ListGamedaysByTeam(teamID string) ([]Gameday, error) {
+	return r.listGamedays(sq.Select().Where("gameday.team_id = ?", teamID))
+}
+
+// ListGamedaysByUser returns every gameday of the teams the given
+// user is member of
+func (r *Repository) ListGamedaysByUser(userID string) ([]Gameday, error) {
+	return r.listGamedays(sq.Select().
+		Join("team_member ON team_member.team_id = gameday.team_id").
+		Where("team_member.user_id = ?", userID))
+}
+
+func (r *Repository) listGamedays(q sq.SelectBuilder) ([]Gameday, error) {
+	q = q.Columns("gameday.*", `team.id "team.id"`, `team.name "team.name"`).
+		From(gamedayTableName).
+		Join("team ON gameday.team_id = team.id").
+		OrderBy("gameday.scheduled_at")
+
+	var gamedays []Gameday
+	if err := r.store.SelectBuilder(r.db(), &gamedays, q); err != nil {
 		return []Gameday{}, errors.Wrap(err, "failed to get gamedays")
 	}
 	return gamedays, nil
 }
 
+// GetGameday returns the gameday with the given ID
+func (r *Repository) GetGameday(id string) (*Gameday, error) {
+	q := sq.Select("gameday.*", `team.id "team.id"`, `team.name "team.name"`, `team.owner_id "team.owner_id"`,
+		`team.nomination_strategy "team.nomination_strategy"`, `team.ack_remind_after "team.ack_remind_after"`,
+		`team.ack_escalate_before "team.ack_escalate_before"`, `team.ack_escalation "team.ack_escalation"`).
+		From(gamedayTableName).
+		Join("team ON gameday.team_id = team.id").
+		Where("gameday.id = ?", id)
+
+	var gamedays []Gameday
+	if err := r.store.SelectBuilder(r.db(), &gamedays, q); err != nil {
+		return nil, errors.Wrap(err, "failed to get gameday")
+	}
+	if len(gamedays) == 0 {
+		return nil, nil
+	}
+	return &gamedays[0], nil
+}
+
 // CreateGameday creates a new gameday in database
 func (r *Repository) CreateGameday(gameday Gameday) (string, error) {
+	return r.insertGameday(r.db(), gameday)
+}
+
+// CreateGamedayWithNominees creates a new gameday and its nominees in a
+// single transaction, nothing is created when a nominee fails
+func (r *Repository) CreateGamedayWithNominees(gameday Gameday, nominees []GamedayNominee) (string, error) {
+	var id string
+	err := r.transaction(func(tx *sqlx.Tx) error {
+		var err error
+		if id, err = r.insertGameday(tx, gameday); err != nil {
+			return err
+		}
+		for _, nominee := range nominees {
+			nominee.GamedayID = id
+			if _, err := r.insertNominee(tx, nominee); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+func (r *Repository) insertGameday(e sqlx.Ext, gameday Gameday) (string, error) {
 	id := store.NewID()
+	createdAt := gameday.CreatedAt
+	if createdAt == 0 {
+		createdAt = time.Now().UnixNano() / int64(time.Millisecond)
+	}
 	insertsMap := map[string]interface{}{
-		"id":           id,
-		"title":        gameday.Title,
-		"team_id":      gameday.TeamID,
-		"scheduled_at": gameday.ScheduledAt,
-		"state":        GamedayScheduledState,
-		"created_at":   time.Now().UnixNano() / int64(time.Millisecond),
-		"updated_at":   0,
+		"id":                  id,
+		"title":               gameday.Title,
+		"team_id":             gameday.TeamID,
+		"scheduled_at":        gameday.ScheduledAt,
+		"state":               GamedayScheduledState,
+		"channel_id":          gameday.ChannelID,
+		"scenarios":           gameday.Scenarios,
+		"checklist":           gameday.Checklist,
+		"duration":            gameday.Duration,
+		"roles":               gameday.Roles,
+		"nomination_seed":     gameday.Seed,
+		"nomination_snapshot": gameday.Snapshot,
+		"created_at":          createdAt,
+		"updated_at":          0,
 	}
-	_, err := r.store.ExecBuilder(r.store.DB, sq.Insert(gamedayTableName).SetMap(insertsMap))
+	_, err := r.store.ExecBuilder(e, sq.Insert(gamedayTableName).SetMap(insertsMap))
 	if err != nil {
 		return "", errors.Wrap(err, "failed to create gameday")
 	}
 	return id, nil
 }
 
-// UpdateGamedayState updates a ngameday state
-func (r *Repository) UpdateGamedayState(gamedayID string, state GamedayState) error {
-	builder := sq.Update(gamedayTableName).Set("state", state).Where("ID = ?", gamedayID)
-	if _, err := r.store.ExecBuilder(r.store.DB, builder); err != nil {
+// UpdateGamedayState updates a ngameday state and the reason of the change
+func (r *Repository) UpdateGamedayState(gamedayID string, state GamedayState, reason string) error {
+	builder := sq.Update(gamedayTableName).
+		Set("state", state).
+		Set("reason", reason).
+		Set("sequence", sq.Expr("sequence + 1")).
+		Set("updated_at", time.Now().UnixNano()/int64(time.Millisecond)).
+		Where("ID = ?", gamedayID)
+	if _, err := r.store.ExecBuilder(r.db(), builder); err != nil {
 		return errors.Wrap(err, "failed to update gameday state")
 	}
 	return nil
 }
 
+// UpdateGamedaySchedule moves the gameday to another time
+func (r *Repository) UpdateGamedaySchedule(gamedayID string, scheduledAt int64) error {
+	builder := sq.Update(gamedayTableName).
+		Set("scheduled_at", scheduledAt).
+		Set("sequence", sq.Expr("sequence + 1")).
+		Set("updated_at", time.Now().UnixNano()/int64(time.Millisecond)).
+		Where(sq.Eq{"id": gamedayID})
+	if _, err := r.store.ExecBuilder(r.db(), builder); err != nil {
+		return errors.Wrap(err, "failed to update gameday schedule")
+	}
+	return nil
+}
+
+// UpdateGamedayPost stores the interactive post which displays the gameday
+func (r *Repository) UpdateGamedayPost(gamedayID, channelID, postID string) error {
+	builder := sq.Update(gamedayTableName).
+		Set("channel_id", channelID).
+		Set("post_id", postID).
+		Where("id = ?", gamedayID)
+	if _, err := r.store.ExecBuilder(r.db(), builder); err != nil {
+		return errors.Wrap(err, "failed to update gameday post")
+	}
+	return nil
+}
+
 // CreateTeam creates a new team which will be assigned to a Gameday
-func (r *Repository) CreateTeam(name string) (string, error) {
+func (r *Repository) CreateTeam(name, ownerID string) (string, error) {
 	id := store.NewID()
 	insertsMap := map[string]interface{}{
 		"id":         id,
 		"name":       name,
+		"slug":       teamSlug(name),
+		"owner_id":   ownerID,
 		"created_at": time.Now().UnixNano() / int64(time.Millisecond),
 		"updated_at": 0,
 	}
-	_, err := r.store.ExecBuilder(r.store.DB, sq.Insert(teamTableName).SetMap(insertsMap))
+	_, err := r.store.ExecBuilder(r.db(), sq.Insert(teamTableName).SetMap(insertsMap))
 	if err != nil {
 		return "", errors.Wrap(err, "failed to create a team")
 	}
@@ -136,7 +355,7 @@ func (r *Repository) ListTeams(teamID string) ([]TeamMember, error) {
 
 	sql = fmt.Sprintf(sql, teamID)
 	var teamMembers []TeamMember
-	if err := r.store.DB.Select(&teamMembers, sql); err != nil {
+	if err := sqlx.Select(r.db(), &teamMembers, sql); err != nil {
 		return []TeamMember{}, errors.Wrap(err, "failed to list team members")
 	}
 	return teamMembers, nil
@@ -147,22 +366,23 @@ func (r *Repository) GetTeams() ([]TeamMember, error) {
 	sql := `SELECT
 		team_member.*,
 		team.id "team.id",
-		team.name "team.name"
+		team.name "team.name",
+		team.slug "team.slug"
 	  FROM
 	  team_member INNER JOIN team ON team_member.team_id = team.id;`
 
 	var teamMembers []TeamMember
-	if err := r.store.DB.Select(&teamMembers, sql); err != nil {
+	if err := sqlx.Select(r.db(), &teamMembers, sql); err != nil {
 		return []TeamMember{}, errors.Wrap(err, "failed to get team members")
 	}
 	return teamMembers, nil
 }
 
-// GetTeam returns the team based on the name
+// GetTeam returns the team with the same slug as the name
 func (r *Repository) GetTeam(name string) (*Team, error) {
-	q := sq.Select("*").From(teamTableName).Where("name LIKE ?", fmt.Sprint("%", name, "%"))
+	q := sq.Select("*").From(teamTableName).Where(sq.Eq{"slug": teamSlug(name)})
 	var teams []Team
-	if err := r.store.SelectBuilder(r.store.DB, &teams, q); err != nil {
+	if err := r.store.SelectBuilder(r.db(), &teams, q); err != nil {
 		return nil, errors.Wrap(err, "failed to find a team")
 	}
 	if len(teams) == 0 {
@@ -171,34 +391,340 @@ func (r *Repository) GetTeam(name string) (*Team, error) {
 	return &teams[0], nil
 }
 
-// CreateMember creates a new member which will be assigned to a Team
-func (r *Repository) CreateMember(teamID, userID, label string) error {
+// GetTeamByID returns the team based on the ID
+func (r *Repository) GetTeamByID(id string) (*Team, error) {
+	var teams []Team
+	if err := r.store.SelectBuilder(r.db(), &teams, sq.Select("*").From(teamTableName).Where(sq.Eq{"id": id})); err != nil {
+		return nil, errors.Wrap(err, "failed to get team")
+	}
+	if len(teams) == 0 {
+		return nil, nil
+	}
+	return &teams[0], nil
+}
+
+// UpdateTeamStrategy updates the nomination strategy of the team
+func (r *Repository) UpdateTeamStrategy(id, strategy string) error {
+	_, err := r.store.ExecBuilder(r.db(), sq.
+		Update(teamTableName).
+		Set("nomination_strategy", strategy).
+		Set("updated_at", time.Now().UnixNano()/int64(time.Millisecond)).
+		Where(sq.Eq{"id": id}))
+	if err != nil {
+		return errors.Wrap(err, "failed to update team nomination strategy")
+	}
+	return nil
+}
+
+// RenameTeam updates the name of the team
+func (r *Repository) RenameTeam(id, name string) error {
+	_, err := r.store.ExecBuilder(r.db(), sq.
+		Update(teamTableName).
+		Set("name", name).
+		Set("slug", teamSlug(name)).
+		Set("updated_at", time.Now().UnixNano()/int64(time.Millisecond)).
+		Where(sq.Eq{"id": id}))
+	if err != nil {
+		return errors.Wrap(err, "failed to rename team")
+	}
+	return nil
+}
+
+// DeleteTeam deletes the team with its members, roles, templates,
+// subscriptions, calendar tokens and gamedays
+func (r *Repository) DeleteTeam(id string) error {
+	teamGamedays := sq.Expr("gameday_id IN (SELECT id FROM gameday WHERE team_id = ?)", id)
+	teamMembers := sq.Expr("member_id IN (SELECT id FROM team_member WHERE team_id = ?)", id)
+	builders := []sq.DeleteBuilder{
+		sq.Delete(nomineeTableName).Where(teamGamedays),
+		sq.Delete(rsvpTableName).Where(teamGamedays),
+		sq.Delete(historyTableName).Where(teamGamedays),
+		sq.Delete(gamedayTableName).Where(sq.Eq{"team_id": id}),
+		sq.Delete(exemptionTableName).Where(teamMembers),
+		sq.Delete(memberTableName).Where(sq.Eq{"team_id": id}),
+		sq.Delete(roleTableName).Where(sq.Eq{"team_id": id}),
+		sq.Delete(templateTableName).Where(sq.Eq{"team_id": id}),
+		sq.Delete(subscriptionTableName).Where(sq.Eq{"team_id": id}),
+		sq.Delete(calendarTokenTableName).Where(sq.Eq{"team_id": id}),
+		sq.Delete(adminTableName).Where(sq.Eq{"team_id": id}),
+		sq.Delete(teamTableName).Where(sq.Eq{"id": id}),
+	}
+	return r.transaction(func(tx *sqlx.Tx) error {
+		for _, builder := range builders {
+			if _, err := r.store.ExecBuilder(tx, builder); err != nil {
+				return errors.Wrapf(err, "failed to delete team: %s", id)
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteMember deletes the member with its exemptions and responses,
+// the nominations of the member are kept
+func (r *Repository) DeleteMember(memberID string) error {
+	builders := []sq.DeleteBuilder{
+		sq.Delete(exemptionTableName).Where(sq.Eq{"member_id": memberID}),
+		sq.Delete(rsvpTableName).Where(sq.Eq{"member_id": memberID}),
+		sq.Delete(memberTableName).Where(sq.Eq{"id": memberID}),
+	}
+	return r.transaction(func(tx *sqlx.Tx) error {
+		for _, builder := range builders {
+			if _, err := r.store.ExecBuilder(tx, builder); err != nil {
+				return errors.Wrapf(err, "failed to delete team member: %s", memberID)
+			}
+		}
+		return nil
+	})
+}
+
+// UpdateTeamAcknowledgement updates how long the nominees of the team have
+// to acknowledge their role and what happens when they don't
+func (r *Repository) UpdateTeamAcknowledgement(id string, remindAfter, escalateBefore int64, escalation string) error {
+	_, err := r.store.ExecBuilder(r.db(), sq.
+		Update(teamTableName).
+		Set("ack_remind_after", remindAfter).
+		Set("ack_escalate_before", escalateBefore).
+		Set("ack_escalation", escalation).
+		Set("updated_at", time.Now().UnixNano()/int64(time.Millisecond)).
+		Where(sq.Eq{"id": id}))
+	if err != nil {
+		return errors.Wrap(err, "failed to update team acknowledgement settings")
+	}
+	return nil
+}
+
+// UpdateTeamSync links the team to the source its members are synced
+// from, an empty source unlinks the team
+func (r *Repository) UpdateTeamSync(id, source, sourceID, sourceName string) error {
+	_, err := r.store.ExecBuilder(r.db(), sq.
+		Update(teamTableName).
+		Set("sync_source", source).
+		Set("sync_source_id", sourceID).
+		Set("sync_source_name", sourceName).
+		Set("synced_at", 0).
+		Set("updated_at", time.Now().UnixNano()/int64(time.Millisecond)).
+		Where(sq.Eq{"id": id}))
+	if err != nil {
+		return errors.Wrap(err, "failed to update team sync source")
+	}
+	return nil
+}
+
+// MarkTeamSynced records when the members of the team were synced
+func (r *Repository) MarkTeamSynced(id string) error {
+	_, err := r.store.ExecBuilder(r.db(), sq.
+		Update(teamTableName).
+		Set("synced_at", time.Now().UnixNano()/int64(time.Millisecond)).
+		Where(sq.Eq{"id": id}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to mark team synced: %s", id)
+	}
+	return nil
+}
+
+// ListAllTeams returns the teams ordered by name
+func (r *Repository) ListAllTeams() ([]Team, error) {
+	var teams []Team
+	if err := r.store.SelectBuilder(r.db(), &teams, sq.Select("*").From(teamTableName).OrderBy("name")); err != nil {
+		return nil, errors.Wrap(err, "failed to list teams")
+	}
+	return teams, nil
+}
+
+// CreateTeamAdmin grants the user the administration of the team
+func (r *Repository) CreateTeamAdmin(admin TeamAdmin) error {
 	insertsMap := map[string]interface{}{
 		"id":         store.NewID(),
-		"team_id":    teamID,
-		"user_id":    userID,
-		"label":      label,
+		"team_id":    admin.TeamID,
+		"user_id":    admin.UserID,
+		"label":      admin.Label,
+		"created_by": admin.CreatedBy,
 		"created_at": time.Now().UnixNano() / int64(time.Millisecond),
-		"updated_at": 0,
 	}
-	_, err := r.store.ExecBuilder(r.store.DB, sq.Insert(memberTableName).SetMap(insertsMap))
+	_, err := r.store.ExecBuilder(r.db(), sq.Insert(adminTableName).SetMap(insertsMap))
+	if err != nil {
+		return errors.Wrap(err, "failed to create a team admin")
+	}
+	return nil
+}
+
+// ListTeamAdmins returns the admins of the team in the order they were granted
+func (r *Repository) ListTeamAdmins(teamID string) ([]TeamAdmin, error) {
+	q := sq.Select("*").
+		From(adminTableName).
+		Where(sq.Eq{"team_id": teamID}).
+		OrderBy("created_at", "id")
+	var admins []TeamAdmin
+	if err := r.store.SelectBuilder(r.db(), &admins, q); err != nil {
+		return nil, errors.Wrap(err, "failed to list team admins")
+	}
+	return admins, nil
+}
+
+// DeleteTeamAdmin revokes the administration of the team from the user
+func (r *Repository) DeleteTeamAdmin(teamID, userID string) error {
+	builder := sq.Delete(adminTableName).Where(sq.Eq{"team_id": teamID, "user_id": userID})
+	if _, err := r.store.ExecBuilder(r.db(), builder); err != nil {
+		return errors.Wrap(err, "failed to delete team admin")
+	}
+	return nil
+}
+
+// ListTeamRoles returns the roles of the team in the order they were created
+func (r *Repository) ListTeamRoles(teamID string) ([]TeamRole, error) {
+	q := sq.Select("*").
+		From(roleTableName).
+		Where(sq.Eq{"team_id": teamID}).
+		OrderBy("created_at", "name")
+
+	var roles []TeamRole
+	if err := r.store.SelectBuilder(r.db(), &roles, q); err != nil {
+		return []TeamRole{}, errors.Wrap(err, "failed to get team roles")
+	}
+	return roles, nil
+}
+
+// SaveTeamRole creates the role of the team or updates it when the team
+// already has a role with the same name
+func (r *Repository) SaveTeamRole(role TeamRole) error {
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	result, err := r.store.ExecBuilder(r.db(), sq.
+		Update(roleTableName).
+		Set("label", role.Label).
+		Set("count", role.Count).
+		Set("rules", role.Rules).
+		Set("updated_at", now).
+		Where(sq.Eq{"team_id": role.TeamID, "name": role.Name}))
+	if err != nil {
+		return errors.Wrap(err, "failed to update team role")
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected > 0 {
+		return nil
+	}
+
+	_, err = r.store.ExecBuilder(r.db(), sq.
+		Insert(roleTableName).
+		SetMap(map[string]interface{}{
+			"id":         store.NewID(),
+			"team_id":    role.TeamID,
+			"name":       role.Name,
+			"label":      role.Label,
+			"count":      role.Count,
+			"rules":      role.Rules,
+			"created_at": now,
+			"updated_at": 0,
+		}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to create role %s for TeamID: %s", role.Name, role.TeamID)
+	}
+	return nil
+}
+
+// DeleteTeamRole deletes the role of the team
+func (r *Repository) DeleteTeamRole(teamID string, name NomineeRole) error {
+	builder := sq.Delete(roleTableName).Where(sq.Eq{"team_id": teamID, "name": name})
+	if _, err := r.store.ExecBuilder(r.db(), builder); err != nil {
+		return errors.Wrap(err, "failed to delete team role")
+	}
+	return nil
+}
+
+// CreateMember creates a new member which will be assigned to a Team
+func (r *Repository) CreateMember(member TeamMember) error {
+	if member.Notifications == "" {
+		member.Notifications = NotifyAll
+	}
+	insertsMap := map[string]interface{}{
+		"id":            store.NewID(),
+		"team_id":       member.TeamID,
+		"user_id":       member.UserID,
+		"label":         member.Label,
+		"level":         member.Level,
+		"skills":        member.Skills,
+		"timezone":      member.Timezone,
+		"notifications": member.Notifications,
+		"created_at":    time.Now().UnixNano() / int64(time.Millisecond),
+		"updated_at":    0,
+	}
+	_, err := r.store.ExecBuilder(r.db(), sq.Insert(memberTableName).SetMap(insertsMap))
 	if err != nil {
 		return errors.Wrap(err, "failed to create a team member")
 	}
 	return nil
 }
 
+// GetMember returns the member of the team for the given user
+func (r *Repository) GetMember(teamID, userID string) (*TeamMember, error) {
+	q := sq.Select("*").From(memberTableName).Where(sq.Eq{"team_id": teamID, "user_id": userID})
+	var members []TeamMember
+	if err := r.store.SelectBuilder(r.db(), &members, q); err != nil {
+		return nil, errors.Wrap(err, "failed to find a team member")
+	}
+	if len(members) == 0 {
+		return nil, nil
+	}
+	return &members[0], nil
+}
+
+// UpdateMemberAttributes updates the experience level and the skills of the member
+func (r *Repository) UpdateMemberAttributes(memberID string, level ExperienceLevel, skills string) error {
+	_, err := r.store.ExecBuilder(r.db(), sq.
+		Update(memberTableName).
+		Set("level", level).
+		Set("skills", skills).
+		Set("updated_at", time.Now().UnixNano()/int64(time.Millisecond)).
+		Where(sq.Eq{"id": memberID}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to update member: %s", memberID)
+	}
+	return nil
+}
+
+// ListUserMembers returns the memberships of the user in every team,
+// the most recently updated first
+func (r *Repository) ListUserMembers(userID string) ([]TeamMember, error) {
+	q := sq.Select("team_member.*", `team.id "team.id"`, `team.name "team.name"`).
+		From(memberTableName).
+		Join("team ON team_member.team_id = team.id").
+		Where(sq.Eq{"team_member.user_id": userID}).
+		OrderBy("team_member.updated_at DESC", "team.name")
+	var members []TeamMember
+	if err := r.store.SelectBuilder(r.db(), &members, q); err != nil {
+		return nil, errors.Wrapf(err, "failed to list the memberships of user: %s", userID)
+	}
+	return members, nil
+}
+
+// UpdateMemberProfile updates the profile of the user in every team
+func (r *Repository) UpdateMemberProfile(userID string, profile MemberProfile) error {
+	_, err := r.store.ExecBuilder(r.db(), sq.
+		Update(memberTableName).
+		Set("timezone", profile.Timezone).
+		Set("notifications", profile.Notifications).
+		Set("updated_at", time.Now().UnixNano()/int64(time.Millisecond)).
+		Where(sq.Eq{"user_id": userID}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to update the profile of user: %s", userID)
+	}
+	return nil
+}
+
 // Updateember updates an existing member
 func (r *Repository) CreateNominee(nominee GamedayNominee) (string, error) {
+	return r.insertNominee(r.db(), nominee)
+}
+
+func (r *Repository) insertNominee(e sqlx.Ext, nominee GamedayNominee) (string, error) {
 	id := store.NewID()
-	_, err := r.store.ExecBuilder(r.store.DB, sq.
+	_, err := r.store.ExecBuilder(e, sq.
 		Insert(nomineeTableName).
 		SetMap(map[string]interface{}{
 			"id":         id,
 			"gameday_id": nominee.GamedayID,
 			"member_id":  nominee.MemberID,
-			"is_mod":     nominee.IsMasterOfDisaster,
-			"is_on_call": nominee.IsOnCall,
+			"role":       nominee.Role,
+			"strategy":   nominee.Strategy,
+			"reason":     nominee.Reason,
 			"created_at": time.Now().UnixNano() / int64(time.Millisecond),
 			"updated_at": 0,
 		}))
@@ -208,19 +734,467 @@ func (r *Repository) CreateNominee(nominee GamedayNominee) (string, error) {
 	return id, nil
 }
 
+// UpdateNomineeMember replaces the member of the nominee and the reason of the pick
+func (r *Repository) UpdateNomineeMember(nomineeID, memberID, reason string) error {
+	_, err := r.store.ExecBuilder(r.db(), sq.
+		Update(nomineeTableName).
+		Set("member_id", memberID).
+		Set("reason", reason).
+		Set("acknowledged_at", 0).
+		Set("reminded_at", 0).
+		Set("escalated_at", 0).
+		Set("updated_at", time.Now().UnixNano()/int64(time.Millisecond)).
+		Where(sq.Eq{"id": nomineeID}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to update nominee: %s", nomineeID)
+	}
+	return nil
+}
+
+// DeleteNominee deletes the nominee of a gameday
+func (r *Repository) DeleteNominee(nomineeID string) error {
+	builder := sq.Delete(nomineeTableName).Where(sq.Eq{"id": nomineeID})
+	if _, err := r.store.ExecBuilder(r.db(), builder); err != nil {
+		return errors.Wrapf(err, "failed to delete nominee: %s", nomineeID)
+	}
+	return nil
+}
+
+// AcknowledgeNominee records that the nominee acknowledged the role
+func (r *Repository) AcknowledgeNominee(nomineeID string) error {
+	return r.markNominee(nomineeID, "acknowledged_at")
+}
+
+// MarkNomineeReminded records that the nominee was reminded to acknowledge the role
+func (r *Repository) MarkNomineeReminded(nomineeID string) error {
+	return r.markNominee(nomineeID, "reminded_at")
+}
+
+// MarkNomineeEscalated records that the nominee didn't acknowledge the role in time
+func (r *Repository) MarkNomineeEscalated(nomineeID string) error {
+	return r.markNominee(nomineeID, "escalated_at")
+}
+
+func (r *Repository) markNominee(nomineeID, column string) error {
+	_, err := r.store.ExecBuilder(r.db(), sq.
+		Update(nomineeTableName).
+		Set(column, time.Now().UnixNano()/int64(time.Millisecond)).
+		Where(sq.Eq{"id": nomineeID}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to update %s of nominee: %s", column, nomineeID)
+	}
+	return nil
+}
+
+// CreateHistory logs a change in the history of the gameday
+func (r *Repository) CreateHistory(history GamedayHistory) error {
+	_, err := r.store.ExecBuilder(r.db(), sq.
+		Insert(historyTableName).
+		SetMap(map[string]interface{}{
+			"id":         store.NewID(),
+			"gameday_id": history.GamedayID,
+			"user_id":    history.UserID,
+			"action":     history.Action,
+			"message":    history.Message,
+			"created_at": time.Now().UnixNano() / int64(time.Millisecond),
+		}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to create history for GamedayID: %s", history.GamedayID)
+	}
+	return nil
+}
+
+// ListGamedayHistory returns the changes of the gameday, oldest first
+func (r *Repository) ListGamedayHistory(gamedayID string) ([]GamedayHistory, error) {
+	q := sq.Select("*").
+		From(historyTableName).
+		Where(sq.Eq{"gameday_id": gamedayID}).
+		OrderBy("created_at")
+
+	var history []GamedayHistory
+	if err := r.store.SelectBuilder(r.db(), &history, q); err != nil {
+		return []GamedayHistory{}, errors.Wrap(err, "failed to get gameday history")
+	}
+	return history, nil
+}
+
+// nomineeMemberColumns the user and the label of the member of the nominees,
+// the members removed from the team since then keep their nominations
+var nomineeMemberColumns = []string{
+	"COALESCE(team_member.user_id, '') user_id",
+	fmt.Sprintf("COALESCE(team_member.label, '%s') label", removedMemberLabel),
+}
+
 // ListGamedayNominees returns the list of gameday nominees by provided gameday ID
 func (r *Repository) ListGamedayNominees(gamedayID string) ([]GamedayNominee, error) {
-	sql := `SELECT
-		gameday_nominee.*,
-		gameday.id "gameday.id"
-	  FROM
-	  gameday_nominee INNER JOIN gameday ON gameday_nominee.gameday_id = gameday.id
-	  WHERE gameday.id = "%s" AND gameday.state NOT IN ('scheduled', 'in_progress', 'cancelled', 'completed');`
-	sql = fmt.Sprintf(sql, gamedayID)
+	q := sq.Select("gameday_nominee.*", `gameday.id "gameday.id"`, "COALESCE(team_role.label, '') role_label").
+		Columns(nomineeMemberColumns...).
+		From(nomineeTableName).
+		Join("gameday ON gameday_nominee.gameday_id = gameday.id").
+		LeftJoin("team_member ON gameday_nominee.member_id = team_member.id").
+		LeftJoin("team_role ON team_role.team_id = gameday.team_id AND team_role.name = gameday_nominee.role").
+		Where("gameday.id = ?", gamedayID)
 
 	var nominees []GamedayNominee
-	if err := r.store.DB.Select(&nominees, sql); err != nil {
+	if err := r.store.SelectBuilder(r.db(), &nominees, q); err != nil {
 		return []GamedayNominee{}, errors.Wrap(err, "failed to get gameday nominees")
 	}
 	return nominees, nil
 }
+
+// ListTeamNominations returns the nominees of the gamedays of the team,
+// with the schedule, state and dates of their gameday
+func (r *Repository) ListTeamNominations(teamID string) ([]GamedayNominee, error) {
+	q := sq.Select("gameday_nominee.*",
+		`gameday.id "gameday.id"`, `gameday.scheduled_at "gameday.scheduled_at"`, `gameday.state "gameday.state"`,
+		`gameday.created_at "gameday.created_at"`, `gameday.updated_at "gameday.updated_at"`,
+		"COALESCE(team_role.label, '') role_label").
+		Columns(nomineeMemberColumns...).
+		From(nomineeTableName).
+		Join("gameday ON gameday_nominee.gameday_id = gameday.id").
+		LeftJoin("team_member ON gameday_nominee.member_id = team_member.id").
+		LeftJoin("team_role ON team_role.team_id = gameday.team_id AND team_role.name = gameday_nominee.role").
+		Where(sq.Eq{"gameday.team_id": teamID})
+
+	var nominees []GamedayNominee
+	if err := r.store.SelectBuilder(r.db(), &nominees, q); err != nil {
+		return []GamedayNominee{}, errors.Wrap(err, "failed to get team nominations")
+	}
+	return nominees, nil
+}
+
+// SaveSubscription creates the subscription of a channel to a team or
+// updates the events when the channel is already subscribed
+func (r *Repository) SaveSubscription(subscription Subscription) error {
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	result, err := r.store.ExecBuilder(r.db(), sq.
+		Update(subscriptionTableName).
+		Set("events", subscription.Events).
+		Set("updated_at", now).
+		Where(sq.Eq{"team_id": subscription.TeamID, "channel_id": subscription.ChannelID}))
+	if err != nil {
+		return errors.Wrap(err, "failed to update subscription")
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected > 0 {
+		return nil
+	}
+
+	_, err = r.store.ExecBuilder(r.db(), sq.
+		Insert(subscriptionTableName).
+		SetMap(map[string]interface{}{
+			"id":         store.NewID(),
+			"team_id":    subscription.TeamID,
+			"channel_id": subscription.ChannelID,
+			"events":     subscription.Events,
+			"created_at": now,
+			"updated_at": 0,
+		}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to create subscription for TeamID: %s and ChannelID: %s", subscription.TeamID, subscription.ChannelID)
+	}
+	return nil
+}
+
+// DeleteSubscription deletes the subscription of a channel to a team
+func (r *Repository) DeleteSubscription(teamID, channelID string) error {
+	builder := sq.Delete(subscriptionTableName).Where(sq.Eq{"team_id": teamID, "channel_id": channelID})
+	if _, err := r.store.ExecBuilder(r.db(), builder); err != nil {
+		return errors.Wrap(err, "failed to delete subscription")
+	}
+	return nil
+}
+
+// ListSubscriptionsByTeam returns the subscriptions to the events of a team
+func (r *Repository) ListSubscriptionsByTeam(teamID string) ([]Subscription, error) {
+	return r.listSubscriptions(sq.Eq{"subscription.team_id": teamID})
+}
+
+// ListSubscriptionsByChannel returns the subscriptions of a channel
+func (r *Repository) ListSubscriptionsByChannel(channelID string) ([]Subscription, error) {
+	return r.listSubscriptions(sq.Eq{"subscription.channel_id": channelID})
+}
+
+func (r *Repository) listSubscriptions(where sq.Eq) ([]Subscription, error) {
+	q := sq.Select("subscription.*", `team.id "team.id"`, `team.name "team.name"`).
+		From(subscriptionTableName).
+		Join("team ON subscription.team_id = team.id").
+		Where(where)
+
+	var subscriptions []Subscription
+	if err := r.store.SelectBuilder(r.db(), &subscriptions, q); err != nil {
+		return []Subscription{}, errors.Wrap(err, "failed to get subscriptions")
+	}
+	return subscriptions, nil
+}
+
+// SaveRSVP stores the response of a member to a gameday invitation
+func (r *Repository) SaveRSVP(gamedayID, memberID string, response RSVPResponse) error {
+	return r.saveRSVP(gamedayID, memberID, map[string]interface{}{"response": response})
+}
+
+// MarkAttendance records that a member attended a gameday
+func (r *Repository) MarkAttendance(gamedayID, memberID string) error {
+	return r.saveRSVP(gamedayID, memberID, map[string]interface{}{"attended": true})
+}
+
+// saveRSVP updates the RSVP of a member or creates it when the member
+// hasn't responded yet
+func (r *Repository) saveRSVP(gamedayID, memberID string, values map[string]interface{}) error {
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	result, err := r.store.ExecBuilder(r.db(), sq.
+		Update(rsvpTableName).
+		SetMap(values).
+		Set("updated_at", now).
+		Where(sq.Eq{"gameday_id": gamedayID, "member_id": memberID}))
+	if err != nil {
+		return errors.Wrap(err, "failed to update gameday rsvp")
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected > 0 {
+		return nil
+	}
+
+	insertsMap := map[string]interface{}{
+		"id":         store.NewID(),
+		"gameday_id": gamedayID,
+		"member_id":  memberID,
+		"response":   "",
+		"attended":   false,
+		"created_at": now,
+		"updated_at": 0,
+	}
+	for k, v := range values {
+		insertsMap[k] = v
+	}
+	if _, err := r.store.ExecBuilder(r.db(), sq.Insert(rsvpTableName).SetMap(insertsMap)); err != nil {
+		return errors.Wrapf(err, "failed to create rsvp for GamedayID: %s and MemberID: %s", gamedayID, memberID)
+	}
+	return nil
+}
+
+// ListGamedayRSVPs returns the responses and attendance of the members
+// for the given gameday ID
+func (r *Repository) ListGamedayRSVPs(gamedayID string) ([]GamedayRSVP, error) {
+	q := sq.Select("gameday_rsvp.*", "team_member.user_id", "team_member.label").
+		From(rsvpTableName).
+		Join("team_member ON gameday_rsvp.member_id = team_member.id").
+		Where("gameday_rsvp.gameday_id = ?", gamedayID)
+
+	var rsvps []GamedayRSVP
+	if err := r.store.SelectBuilder(r.db(), &rsvps, q); err != nil {
+		return []GamedayRSVP{}, errors.Wrap(err, "failed to get gameday rsvps")
+	}
+	return rsvps, nil
+}
+
+// CreateTemplate creates a new gameday template
+func (r *Repository) CreateTemplate(template GamedayTemplate) error {
+	_, err := r.store.ExecBuilder(r.db(), sq.
+		Insert(templateTableName).
+		SetMap(map[string]interface{}{
+			"id":         store.NewID(),
+			"name":       template.Name,
+			"title":      template.Title,
+			"team_id":    template.TeamID,
+			"scenarios":  template.Scenarios,
+			"checklist":  template.Checklist,
+			"duration":   template.Duration,
+			"roles":      template.Roles,
+			"created_at": time.Now().UnixNano() / int64(time.Millisecond),
+			"updated_at": 0,
+		}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to create gameday template: %s", template.Name)
+	}
+	return nil
+}
+
+// UpdateTemplate updates the configuration of an existing gameday template
+func (r *Repository) UpdateTemplate(template GamedayTemplate) error {
+	builder := sq.Update(templateTableName).
+		SetMap(map[string]interface{}{
+			"title":      template.Title,
+			"team_id":    template.TeamID,
+			"scenarios":  template.Scenarios,
+			"checklist":  template.Checklist,
+			"duration":   template.Duration,
+			"roles":      template.Roles,
+			"updated_at": time.Now().UnixNano() / int64(time.Millisecond),
+		}).
+		Where("id = ?", template.ID)
+	if _, err := r.store.ExecBuilder(r.db(), builder); err != nil {
+		return errors.Wrapf(err, "failed to update gameday template: %s", template.Name)
+	}
+	return nil
+}
+
+// GetTemplate returns the gameday template based on the name
+func (r *Repository) GetTemplate(name string) (*GamedayTemplate, error) {
+	templates, err := r.listTemplates(sq.Eq{"gameday_template.name": name})
+	if err != nil {
+		return nil, err
+	}
+	if len(templates) == 0 {
+		return nil, nil
+	}
+	return &templates[0], nil
+}
+
+// ListTemplates returns the list of gameday templates
+func (r *Repository) ListTemplates() ([]GamedayTemplate, error) {
+	return r.listTemplates(sq.Eq{})
+}
+
+func (r *Repository) listTemplates(where sq.Eq) ([]GamedayTemplate, error) {
+	q := sq.Select("gameday_template.*", `team.id "team.id"`, `team.name "team.name"`).
+		From(templateTableName).
+		Join("team ON gameday_template.team_id = team.id").
+		Where(where).
+		OrderBy("gameday_template.name")
+
+	var templates []GamedayTemplate
+	if err := r.store.SelectBuilder(r.db(), &templates, q); err != nil {
+		return []GamedayTemplate{}, errors.Wrap(err, "failed to get gameday templates")
+	}
+	return templates, nil
+}
+
+// CreateAway creates a period when the user is away
+func (r *Repository) CreateAway(away MemberAway) error {
+	_, err := r.store.ExecBuilder(r.db(), sq.
+		Insert(awayTableName).
+		SetMap(map[string]interface{}{
+			"id":         store.NewID(),
+			"user_id":    away.UserID,
+			"starts_at":  away.StartsAt,
+			"ends_at":    away.EndsAt,
+			"created_at": time.Now().UnixNano() / int64(time.Millisecond),
+		}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to create away period for UserID: %s", away.UserID)
+	}
+	return nil
+}
+
+// ListAwayByUser returns the away periods of the user which end after the given time
+func (r *Repository) ListAwayByUser(userID string, after int64) ([]MemberAway, error) {
+	q := sq.Select("*").
+		From(awayTableName).
+		Where(sq.Eq{"user_id": userID}).
+		Where(sq.Gt{"ends_at": after}).
+		OrderBy("starts_at")
+
+	var aways []MemberAway
+	if err := r.store.SelectBuilder(r.db(), &aways, q); err != nil {
+		return []MemberAway{}, errors.Wrap(err, "failed to get away periods")
+	}
+	return aways, nil
+}
+
+// ListAwayBetween returns the away periods which overlap the given period
+func (r *Repository) ListAwayBetween(from, to int64) ([]MemberAway, error) {
+	q := sq.Select("*").
+		From(awayTableName).
+		Where(sq.Lt{"starts_at": to}).
+		Where(sq.Gt{"ends_at": from})
+
+	var aways []MemberAway
+	if err := r.store.SelectBuilder(r.db(), &aways, q); err != nil {
+		return []MemberAway{}, errors.Wrap(err, "failed to get away periods")
+	}
+	return aways, nil
+}
+
+// CreateExemption creates a period when the member is out of the rotation
+func (r *Repository) CreateExemption(exemption MemberExemption) error {
+	_, err := r.store.ExecBuilder(r.db(), sq.
+		Insert(exemptionTableName).
+		SetMap(map[string]interface{}{
+			"id":         store.NewID(),
+			"member_id":  exemption.MemberID,
+			"reason":     exemption.Reason,
+			"expires_at": exemption.ExpiresAt,
+			"created_by": exemption.CreatedBy,
+			"created_at": time.Now().UnixNano() / int64(time.Millisecond),
+		}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to create exemption for MemberID: %s", exemption.MemberID)
+	}
+	return nil
+}
+
+// ListTeamExemptions returns the exemptions of the members of the team
+// which expire after the given time
+func (r *Repository) ListTeamExemptions(teamID string, after int64) ([]MemberExemption, error) {
+	q := sq.Select("member_exemption.*", "team_member.user_id", "team_member.label").
+		From(exemptionTableName).
+		Join("team_member ON member_exemption.member_id = team_member.id").
+		Where(sq.Eq{"team_member.team_id": teamID}).
+		Where(sq.Gt{"member_exemption.expires_at": after}).
+		OrderBy("member_exemption.expires_at")
+
+	var exemptions []MemberExemption
+	if err := r.store.SelectBuilder(r.db(), &exemptions, q); err != nil {
+		return []MemberExemption{}, errors.Wrap(err, "failed to get team exemptions")
+	}
+	return exemptions, nil
+}
+
+// DeleteExemptions deletes the exemptions of the member
+func (r *Repository) DeleteExemptions(memberID string) error {
+	builder := sq.Delete(exemptionTableName).Where(sq.Eq{"member_id": memberID})
+	if _, err := r.store.ExecBuilder(r.db(), builder); err != nil {
+		return errors.Wrap(err, "failed to delete exemptions")
+	}
+	return nil
+}
+
+// GetCalendarToken returns the calendar token of the team or of the user
+func (r *Repository) GetCalendarToken(teamID, userID string) (*CalendarToken, error) {
+	return r.getCalendarToken(sq.Eq{"team_id": teamID, "user_id": userID})
+}
+
+// GetCalendarTokenByToken returns the calendar token with the given secret
+func (r *Repository) GetCalendarTokenByToken(token string) (*CalendarToken, error) {
+	return r.getCalendarToken(sq.Eq{"token": token})
+}
+
+func (r *Repository) getCalendarToken(where sq.Eq) (*CalendarToken, error) {
+	q := sq.Select("*").From(calendarTokenTableName).Where(where)
+	var tokens []CalendarToken
+	if err := r.store.SelectBuilder(r.db(), &tokens, q); err != nil {
+		return nil, errors.Wrap(err, "failed to find a calendar token")
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	return &tokens[0], nil
+}
+
+// CreateCalendarToken creates the secret token of the calendar of the
+// team or of the user
+func (r *Repository) CreateCalendarToken(teamID, userID string) (string, error) {
+	token := store.NewID() + store.NewID()
+	_, err := r.store.ExecBuilder(r.db(), sq.
+		Insert(calendarTokenTableName).
+		SetMap(map[string]interface{}{
+			"id":         store.NewID(),
+			"token":      token,
+			"team_id":    teamID,
+			"user_id":    userID,
+			"created_at": time.Now().UnixNano() / int64(time.Millisecond),
+		}))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create calendar token")
+	}
+	return token, nil
+}
+
+// DeleteCalendarToken deletes the secret token of the calendar of the
+// team or of the user
+func (r *Repository) DeleteCalendarToken(teamID, userID string) error {
+	builder := sq.Delete(calendarTokenTableName).Where(sq.Eq{"team_id": teamID, "user_id": userID})
+	if _, err := r.store.ExecBuilder(r.db(), builder); err != nil {
+		return errors.Wrap(err, "failed to delete calendar token")
+	}
+	return nil
+}
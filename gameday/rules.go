@@ -0,0 +1,216 @@
+package gameday
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// roleRule restricts the members who are eligible for a role
+type roleRule interface {
+	// eligible returns true when the member can be nominated for the role
+	eligible(member TeamMember, nominations []GamedayNominee, role NomineeRole) bool
+	String() string
+}
+
+// servedRule the member must have served the role on a previous gameday
+type servedRule struct {
+	role NomineeRole
+}
+
+func (r servedRule) eligible(member TeamMember, nominations []GamedayNominee, _ NomineeRole) bool {
+	for _, n := range nominations {
+		if n.MemberID == member.ID && nominatedFor(n, r.role) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r servedRule) String() string {
+	return "served:" + string(r.role)
+}
+
+// levelRule the member must be at least or at most as experienced as the level
+type levelRule struct {
+	level ExperienceLevel
+	max   bool
+}
+
+func (r levelRule) eligible(member TeamMember, _ []GamedayNominee, _ NomineeRole) bool {
+	if r.max {
+		return member.Level.rank() <= r.level.rank()
+	}
+	return member.Level.rank() >= r.level.rank()
+}
+
+func (r levelRule) String() string {
+	if r.max {
+		return "max-level:" + string(r.level)
+	}
+	return "level:" + string(r.level)
+}
+
+// skillRule the member must have the skill
+type skillRule struct {
+	skill string
+}
+
+func (r skillRule) eligible(member TeamMember, _ []GamedayNominee, _ NomineeRole) bool {
+	return member.hasSkill(r.skill)
+}
+
+func (r skillRule) String() string {
+	return "skill:" + r.skill
+}
+
+// timezoneRule the member must be in the timezone or in one of the
+// timezones of the region, like `europe` for `Europe/Paris`
+type timezoneRule struct {
+	timezone string
+}
+
+func (r timezoneRule) eligible(member TeamMember, _ []GamedayNominee, _ NomineeRole) bool {
+	timezone := strings.ToLower(member.Timezone)
+	return timezone == r.timezone || strings.HasPrefix(timezone, r.timezone+"/")
+}
+
+func (r timezoneRule) String() string {
+	return "timezone:" + r.timezone
+}
+
+// cooldownRule the member must not have held the role in the
+// latest gamedays of the team
+type cooldownRule struct {
+	gamedays int
+}
+
+func (r cooldownRule) eligible(member TeamMember, nominations []GamedayNominee, role NomineeRole) bool {
+	latest := latestGamedays(nominations, r.gamedays)
+	for _, n := range nominations {
+		if n.MemberID == member.ID && nominatedFor(n, role) && latest[n.GamedayID] {
+			return false
+		}
+	}
+	return true
+}
+
+func (r cooldownRule) String() string {
+	return fmt.Sprintf("cooldown:%d", r.gamedays)
+}
+
+// latestGamedays returns the IDs of the latest scheduled gamedays of the nominations
+func latestGamedays(nominations []GamedayNominee, count int) map[string]bool {
+	scheduledAt := map[string]int64{}
+	for _, n := range nominations {
+		scheduledAt[n.GamedayID] = n.Gameday.ScheduledAt
+	}
+	var ids []string
+	for id := range scheduledAt {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if scheduledAt[ids[i]] != scheduledAt[ids[j]] {
+			return scheduledAt[ids[i]] > scheduledAt[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+
+	latest := map[string]bool{}
+	for i := 0; i < count && i < len(ids); i++ {
+		latest[ids[i]] = true
+	}
+	return latest
+}
+
+// parseRoleRules returns the space separated rules of a role:
+// `served:<role>` the member served the role on a previous gameday,
+// `level:<level>` and `max-level:<level>` the member is at least or at most as experienced,
+// `skill:<skill>` the member has the skill,
+// `timezone:<timezone>` the member is in the timezone or its region and
+// `cooldown:<n>` the member didn't hold the role in the latest n gamedays of the team
+func parseRoleRules(rules string) ([]roleRule, error) {
+	var results []roleRule
+	for _, field := range strings.Fields(rules) {
+		parts := strings.SplitN(field, ":", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			return nil, errors.Errorf("invalid rule %s, expected <rule>:<value>", field)
+		}
+		value := strings.ToLower(parts[1])
+		switch strings.ToLower(parts[0]) {
+		case "served":
+			results = append(results, servedRule{role: NomineeRole(value)})
+		case "level", "max-level":
+			level := ExperienceLevel(value)
+			if level.rank() < 0 {
+				return nil, errors.Errorf("unknown level %s, expected one of: %s", parts[1], levelNames())
+			}
+			results = append(results, levelRule{level: level, max: strings.EqualFold(parts[0], "max-level")})
+		case "skill":
+			results = append(results, skillRule{skill: value})
+		case "timezone":
+			results = append(results, timezoneRule{timezone: strings.TrimSuffix(value, "/")})
+		case "cooldown":
+			gamedays, err := strconv.Atoi(value)
+			if err != nil || gamedays <= 0 {
+				return nil, errors.Errorf("invalid rule %s, the cooldown should be a positive number of gamedays", field)
+			}
+			results = append(results, cooldownRule{gamedays: gamedays})
+		default:
+			return nil, errors.Errorf("unknown rule %s", parts[0])
+		}
+	}
+	return results, nil
+}
+
+// levelNames the comma separated experience levels
+func levelNames() string {
+	var names []string
+	for _, level := range experienceLevels {
+		names = append(names, string(level))
+	}
+	return strings.Join(names, ", ")
+}
+
+// eligibleMembers returns the members who match every rule of the role and
+// for each rule which excluded members, the explanation of the exclusions
+func eligibleMembers(members []TeamMember, nominations []GamedayNominee, role NomineeRole, rules []roleRule) ([]TeamMember, []string) {
+	excluded := make([][]string, len(rules))
+	var results []TeamMember
+	for _, m := range members {
+		eligible := true
+		for i, rule := range rules {
+			if !rule.eligible(m, nominations, role) {
+				excluded[i] = append(excluded[i], "@"+m.Label)
+				eligible = false
+				break
+			}
+		}
+		if eligible {
+			results = append(results, m)
+		}
+	}
+
+	var explanations []string
+	for i, rule := range rules {
+		if len(excluded[i]) > 0 {
+			explanations = append(explanations, fmt.Sprintf("%s excluded %s", rule, strings.Join(excluded[i], ", ")))
+		}
+	}
+	return results, explanations
+}
+
+// nominationReason explains why the member was picked for the role
+func nominationReason(strategy NominationStrategy, eligible []TeamMember, explanations []string) string {
+	reason := fmt.Sprintf("%s pick among %d eligible member(s)", strategy.Name(), len(eligible))
+	if len(explanations) > 0 {
+		reason += "; " + strings.Join(explanations, "; ")
+	}
+	if len(reason) > 1024 {
+		reason = reason[:1021] + "..."
+	}
+	return reason
+}
@@ -0,0 +1,85 @@
+package gameday
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+)
+
+// Runtime the state shared by the calls and the schedulers, the service
+// is only set once the database is configured
+type Runtime struct {
+	mu     sync.Mutex
+	svc    *Service
+	botCtx *apps.Context
+}
+
+// NewRuntime factory method to create the runtime of the app
+func NewRuntime() *Runtime {
+	return &Runtime{}
+}
+
+// Service returns the service of the app, nil until the database is configured
+func (rt *Runtime) Service() *Service {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	return rt.svc
+}
+
+// SetService sets the service once the database is configured
+func (rt *Runtime) SetService(svc *Service) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	rt.svc = svc
+}
+
+// RememberCalls keeps the context of the calls so the schedulers can act
+// as the bot between the calls, it must run after VerifyCalls so only the
+// verified contexts with the configured server are kept
+func (rt *Runtime) RememberCalls(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.Body != nil {
+			body, err := io.ReadAll(r.Body)
+			if err == nil {
+				if call, err := apps.CallRequestFromJSON(body); err == nil {
+					rt.rememberContext(call.Context)
+				}
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// rememberContext keeps the context of the latest call with a bot
+// token so the schedulers can reach Mattermost between the calls
+func (rt *Runtime) rememberContext(ctx *apps.Context) {
+	if ctx == nil || ctx.BotAccessToken == "" || ctx.MattermostSiteURL == "" {
+		return
+	}
+	botCtx := apps.Context{
+		BotUserID:         ctx.BotUserID,
+		ActingUserID:      ctx.BotUserID,
+		MattermostSiteURL: ctx.MattermostSiteURL,
+	}
+	botCtx.AppID = ctx.AppID
+	botCtx.BotAccessToken = ctx.BotAccessToken
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	rt.botCtx = &botCtx
+}
+
+// scheduled returns the service and the context the schedulers act with,
+// nil until the database is configured and the app received a call
+func (rt *Runtime) scheduled() (*Service, *apps.Context) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	if rt.svc == nil || rt.botCtx == nil {
+		return nil, nil
+	}
+	return rt.svc, rt.botCtx
+}
@@ -0,0 +1,27 @@
+package gameday
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRuntimeScheduled(t *testing.T) {
+	rt := NewRuntime()
+	handler := rt.RememberCalls(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	call := func() {
+		payload := `{"context":{"bot_user_id":"bot","mattermost_site_url":"https://chat.example.com","bot_access_token":"token"}}`
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(payload)))
+	}
+
+	call()
+	if svc, _ := rt.scheduled(); svc != nil {
+		t.Error("expected the schedulers to wait until the app is configured")
+	}
+	rt.SetService(&Service{})
+	svc, botCtx := rt.scheduled()
+	if svc == nil || botCtx == nil || botCtx.ActingUserID != "bot" || botCtx.BotAccessToken != "token" {
+		t.Errorf("expected the context of the call to be remembered, got %+v", botCtx)
+	}
+}
@@ -2,121 +2,1761 @@ package gameday
 
 import (
 	"fmt"
+	"io"
 	"math/rand"
+	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/mattermost/mattermost-plugin-apps/apps"
 	"github.com/mattermost/mattermost-plugin-apps/apps/mmclient"
+	"github.com/mattermost/mattermost-server/v5/model"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 )
 
+// statusCheckWindow how soon a gameday starts for the Mattermost status
+// of its nominees to be checked
+const statusCheckWindow = time.Hour
+
+// ErrCalendarNotFound when the token of a calendar feed doesn't exist
+var ErrCalendarNotFound = errors.New("calendar not found")
+
 // Service respresents the struct for the business logic
 // for Gameday service
 type Service struct {
-	repo GamedayRepository
+	repo    GamedayRepository
+	rootURL string
+	logger  logrus.FieldLogger
+
+	mu   *sync.Mutex
+	seed *rand.Rand
+}
+
+// NewService factory method to create the service, the root URL
+// is used to build the links served by the app, the random source
+// generates the seeds of the nominations and the logger records the
+// failures which don't fail the calls
+func NewService(repo GamedayRepository, rootURL string, source rand.Source, logger logrus.FieldLogger) *Service {
+	return &Service{
+		repo:    repo,
+		rootURL: strings.TrimSuffix(rootURL, "/"),
+		logger:  logger,
+		mu:      &sync.Mutex{},
+		seed:    rand.New(source),
+	}
+}
+
+// inTransaction runs fn with the service bound to a single transaction of
+// the repository, nothing is saved unless fn succeeds
+func (s *Service) inTransaction(fn func(tx *Service) error) error {
+	return s.repo.Transaction(func(repo GamedayRepository) error {
+		return fn(&Service{repo: repo, rootURL: s.rootURL, logger: s.logger, mu: s.mu, seed: s.seed})
+	})
+}
+
+// nextSeed returns the seed of the nominations of a new gameday, never
+// zero which means the seed wasn't recorded
+func (s *Service) nextSeed() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for {
+		if seed := s.seed.Int63(); seed != 0 {
+			return seed
+		}
+	}
+}
+
+// CreateTeam responsible to create a team owned by the acting user with
+// the members of the DTO, the teams are identified by the slug of their name
+func (s *Service) CreateTeam(ctx *apps.Context, dto CreateTeamDTO) (MembershipReport, error) {
+	name := strings.TrimSpace(dto.Name)
+	existing, err := s.repo.GetTeam(name)
+	if err != nil {
+		return MembershipReport{}, errors.Wrap(err, "failed to get a team in repository")
+	}
+	if existing != nil {
+		return MembershipReport{}, errors.Errorf("team %s already exists, add members with `team member add`", existing.Name)
+	}
+	team, err := s.ensureTeam(ctx, name)
+	if err != nil {
+		return MembershipReport{}, err
+	}
+	if strings.TrimSpace(dto.Members) == "" {
+		return MembershipReport{Team: team}, nil
+	}
+	return s.addMembers(ctx, team, parseUsernames(dto.Members))
+}
+
+// AddMembers responsible to add the members of the DTO to the team
+func (s *Service) AddMembers(ctx *apps.Context, dto AddMembersDTO) (MembershipReport, error) {
+	team, err := s.repo.GetTeamByID(dto.Team.Value)
+	if err != nil {
+		return MembershipReport{}, errors.Wrap(err, "failed to get team in repository")
+	}
+	if team == nil {
+		return MembershipReport{}, errors.Errorf("team %s doesn't exist", dto.Team.Label)
+	}
+	return s.addMembers(ctx, *team, parseUsernames(dto.Members))
+}
+
+// addMembers adds the users to the team, nobody is added when a username
+// is unknown and the users who already are members are reported
+func (s *Service) addMembers(ctx *apps.Context, team Team, usernames []string) (MembershipReport, error) {
+	var users []MemberDTO
+	var unknown []string
+	for _, username := range usernames {
+		user, _ := mmclient.AsBot(ctx).GetUserByUsername(username, "")
+		if user == nil {
+			unknown = append(unknown, "@"+username)
+			continue
+		}
+		users = append(users, MemberDTO{Label: user.Username, UserID: user.Id})
+	}
+	if len(unknown) > 0 {
+		return MembershipReport{}, errors.Errorf("unknown user(s): %s", strings.Join(unknown, ", "))
+	}
+
+	report := MembershipReport{Team: team}
+	for _, u := range users {
+		member, err := s.repo.GetMember(team.ID, u.UserID)
+		if err != nil {
+			return report, errors.Wrap(err, "failed to get team member in repository")
+		}
+		if member != nil {
+			report.Existing = append(report.Existing, u.Label)
+			continue
+		}
+		if err := s.createMember(ctx, team.ID, u.UserID, u.Label); err != nil {
+			return report, errors.Wrap(err, "failed to create a member in repository")
+		}
+		report.Added = append(report.Added, u.Label)
+		mmclient.AsBot(ctx).DM(u.UserID, fmt.Sprintf("You are added in Team: **%s**", strings.ToUpper(team.Name)))
+	}
+	if len(report.Added) == 0 && len(report.Existing) > 0 {
+		return report, errors.Errorf("@%s already member(s) of team %s", strings.Join(report.Existing, ", @"), team.Name)
+	}
+	return report, nil
+}
+
+// ensureTeam returns the team with the name, the team is created with
+// the default roles and owned by the acting user when it doesn't exist
+func (s *Service) ensureTeam(ctx *apps.Context, name string) (Team, error) {
+	if teamSlug(name) == "" {
+		return Team{}, errors.New("the name of the team is empty")
+	}
+	team, err := s.repo.GetTeam(name)
+	if err != nil {
+		return Team{}, errors.Wrap(err, "failed to get a team in repository")
+	}
+	if team != nil {
+		return *team, nil
+	}
+	teamID, err := s.repo.CreateTeam(name, ctx.ActingUserID)
+	if err != nil {
+		return Team{}, errors.Wrap(err, "failed to create a team in repository")
+	}
+	for _, role := range defaultTeamRoles {
+		role.TeamID = teamID
+		if err := s.repo.SaveTeamRole(role); err != nil {
+			return Team{}, errors.Wrap(err, "failed to create the roles of the team in repository")
+		}
+	}
+	team, err = s.repo.GetTeamByID(teamID)
+	if err != nil || team == nil {
+		return Team{}, errors.Wrap(err, "failed to get the created team in repository")
+	}
+	return *team, nil
+}
+
+// ConfigureTeam responsible to update the settings of the team
+func (s *Service) ConfigureTeam(dto ConfigureTeamDTO) (Team, error) {
+	team, err := s.repo.GetTeamByID(dto.Team.Value)
+	if err != nil {
+		return Team{}, errors.Wrap(err, "failed to get team in repository")
+	}
+	if team == nil {
+		return Team{}, errors.Errorf("team %s doesn't exist", dto.Team.Label)
+	}
+	if dto.Strategy.Value != "" {
+		strategy := strings.ToLower(dto.Strategy.Value)
+		if !isNominationStrategy(strategy) {
+			return Team{}, errors.Errorf("unknown nomination strategy %s, expected one of: %s", dto.Strategy.Value, strings.Join(nominationStrategyNames(), ", "))
+		}
+		if err := s.repo.UpdateTeamStrategy(team.ID, strategy); err != nil {
+			return Team{}, errors.Wrap(err, "failed to update team in repository")
+		}
+		team.NominationStrategy = strategy
+	}
+	if dto.RemindAfter == "" && dto.EscalateBefore == "" && dto.Escalation.Value == "" {
+		return *team, nil
+	}
+
+	if team.AckRemindAfter, err = parseHours("remind_after", dto.RemindAfter, team.AckRemindAfter); err != nil {
+		return Team{}, err
+	}
+	if team.AckEscalateBefore, err = parseHours("escalate_before", dto.EscalateBefore, team.AckEscalateBefore); err != nil {
+		return Team{}, err
+	}
+	if dto.Escalation.Value != "" {
+		team.AckEscalation = strings.ToLower(dto.Escalation.Value)
+	}
+	if !isAckEscalation(team.AckEscalation) {
+		return Team{}, errors.Errorf("unknown escalation %s, expected one of: %s", team.AckEscalation, ackEscalationNames())
+	}
+	if err := s.repo.UpdateTeamAcknowledgement(team.ID, team.AckRemindAfter, team.AckEscalateBefore, team.AckEscalation); err != nil {
+		return Team{}, errors.Wrap(err, "failed to update team in repository")
+	}
+	return *team, nil
+}
+
+// RemoveMember responsible to remove a member from the team, another member
+// is drawn for the roles the member holds on the scheduled gamedays and the
+// role is left vacant when nobody else can hold it, returns what was done
+func (s *Service) RemoveMember(ctx *apps.Context, dto TeamMemberDTO) ([]string, error) {
+	member, err := s.getAdministeredMember(ctx, dto.Team, dto.Member)
+	if err != nil {
+		return nil, err
+	}
+	return s.removeMembers(ctx, dto.Team.Label, []TeamMember{*member})
+}
+
+// removeMembers deletes the members of the team, the members are replaced
+// on the scheduled gamedays by the members who stay and the roles are left
+// vacant when nobody else can be drawn
+func (s *Service) removeMembers(ctx *apps.Context, teamName string, members []TeamMember) ([]string, error) {
+	if len(members) == 0 {
+		return nil, nil
+	}
+	removed := map[string]bool{}
+	for _, m := range members {
+		removed[m.ID] = true
+	}
+
+	// the members are removed with all of the replacements or not at all,
+	// nobody is notified before it is saved
+	var report []string
+	var replacements []replacement
+	err := s.inTransaction(func(tx *Service) error {
+		gamedays, err := tx.repo.ListGamedaysByTeam(members[0].TeamID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get the gamedays of the team in repository")
+		}
+		for _, g := range gamedays {
+			if g.State != GamedayScheduledState {
+				continue
+			}
+			nominees, err := tx.repo.ListGamedayNominees(g.ID)
+			if err != nil {
+				return errors.Wrap(err, "failed to fetch gameday nominees")
+			}
+			for _, n := range nominees {
+				if !removed[n.MemberID] {
+					continue
+				}
+				r, err := tx.renominate(ctx, g.ID, n.Role, n.UserID, removed)
+				if err == nil {
+					replacements = append(replacements, r)
+					report = append(report, fmt.Sprintf("@%s replaces @%s as %s of gameday %s", r.member.Label, n.Label, getNomineeRole(n), g.Title))
+					continue
+				}
+				if err := tx.repo.DeleteNominee(n.ID); err != nil {
+					return errors.Wrap(err, "failed to delete the nominee in repository")
+				}
+				report = append(report, fmt.Sprintf("the %s of gameday %s is vacant: %s", getNomineeRole(n), g.Title, err))
+			}
+		}
+		for _, m := range members {
+			if err := tx.repo.DeleteMember(m.ID); err != nil {
+				return errors.Wrap(err, "failed to delete team member in repository")
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range replacements {
+		s.notifyReplacement(ctx, r)
+	}
+	for _, m := range members {
+		mmclient.AsBot(ctx).DM(m.UserID, fmt.Sprintf("You were removed from team: **%s**", teamName))
+	}
+	return report, nil
+}
+
+// RenameTeam responsible to rename the team, the names are unique
+func (s *Service) RenameTeam(ctx *apps.Context, dto RenameTeamDTO) (Team, error) {
+	team, err := s.getAdministeredTeam(ctx, dto.Team)
+	if err != nil {
+		return Team{}, err
+	}
+	name := strings.TrimSpace(dto.Name)
+	if teamSlug(name) == "" {
+		return Team{}, errors.New("the name of the team is empty")
+	}
+	existing, err := s.repo.GetTeam(name)
+	if err != nil {
+		return Team{}, errors.Wrap(err, "failed to get a team in repository")
+	}
+	if existing != nil && existing.ID != team.ID {
+		return Team{}, errors.Errorf("team %s already exists", existing.Name)
+	}
+	if err := s.repo.RenameTeam(team.ID, name); err != nil {
+		return Team{}, errors.Wrap(err, "failed to rename team in repository")
+	}
+
+	members, err := s.repo.ListTeams(team.ID)
+	if err != nil {
+		return Team{}, errors.Wrap(err, "failed to fetch team members in repository")
+	}
+	for _, m := range members {
+		mmclient.AsBot(ctx).DM(m.UserID, fmt.Sprintf("Team **%s** was renamed to **%s**", team.Name, name))
+	}
+	team.Name = name
+	return *team, nil
+}
+
+// DeleteTeam responsible to delete the team with its gamedays, the teams
+// with scheduled gamedays are only deleted when forced and the gamedays
+// are cancelled first so their posts and subscribers are told
+func (s *Service) DeleteTeam(ctx *apps.Context, dto DeleteTeamDTO) error {
+	team, err := s.getAdministeredTeam(ctx, dto.Team)
+	if err != nil {
+		return err
+	}
+	gamedays, err := s.repo.ListGamedaysByTeam(team.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get the gamedays of the team in repository")
+	}
+	var scheduled []Gameday
+	for _, g := range gamedays {
+		if g.State == GamedayScheduledState || g.State == GamedayInProgressState {
+			scheduled = append(scheduled, g)
+		}
+	}
+	if len(scheduled) > 0 && !dto.Force {
+		return errors.Errorf("team %s has %d scheduled gameday(s), delete it with `--force true` to cancel them", team.Name, len(scheduled))
+	}
+	for _, g := range scheduled {
+		if err := s.UpdateGamedayState(ctx, g.ID, GamedayCancelledState, fmt.Sprintf("team %s was deleted", team.Name)); err != nil {
+			return errors.Wrapf(err, "failed to cancel gameday %s", g.Title)
+		}
+	}
+
+	members, err := s.repo.ListTeams(team.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch team members in repository")
+	}
+	if err := s.repo.DeleteTeam(team.ID); err != nil {
+		return errors.Wrap(err, "failed to delete team in repository")
+	}
+	msg := fmt.Sprintf("Team **%s** was deleted", team.Name)
+	if len(scheduled) > 0 {
+		msg += fmt.Sprintf(", its %d scheduled gameday(s) are cancelled", len(scheduled))
+	}
+	for _, m := range members {
+		mmclient.AsBot(ctx).DM(m.UserID, msg)
+	}
+	return nil
+}
+
+// ShowTeam responsible to return the team with its members and roles
+func (s *Service) ShowTeam(name string) (Team, []TeamMember, []TeamRole, error) {
+	team, err := s.repo.GetTeam(strings.ToLower(strings.TrimSpace(name)))
+	if err != nil {
+		return Team{}, nil, nil, errors.Wrap(err, "failed to get a team in repository")
+	}
+	if team == nil {
+		return Team{}, nil, nil, errors.Errorf("team %s doesn't exist", name)
+	}
+	members, err := s.repo.ListTeams(team.ID)
+	if err != nil {
+		return Team{}, nil, nil, errors.Wrap(err, "failed to fetch team members in repository")
+	}
+	roles, err := s.ListRoles(team.ID)
+	if err != nil {
+		return Team{}, nil, nil, err
+	}
+	return *team, members, roles, nil
+}
+
+// SetMemberAttributes responsible to update the experience level and the
+// skills of a team member, the fields which aren't provided are kept
+func (s *Service) SetMemberAttributes(dto MemberAttributesDTO) (TeamMember, error) {
+	member, err := s.repo.GetMember(dto.Team.Value, dto.Member.UserID)
+	if err != nil {
+		return TeamMember{}, errors.Wrap(err, "failed to get team member in repository")
+	}
+	if member == nil {
+		return TeamMember{}, errors.Errorf("@%s isn't a member of team: %s", dto.Member.Label, dto.Team.Label)
+	}
+	if dto.Level.Value != "" {
+		member.Level = ExperienceLevel(dto.Level.Value)
+	}
+	if dto.Skills != "" {
+		member.Skills = strings.Join(parseSkills(dto.Skills), ",")
+	}
+	if err := s.repo.UpdateMemberAttributes(member.ID, member.Level, member.Skills); err != nil {
+		return TeamMember{}, errors.Wrap(err, "failed to update team member in repository")
+	}
+	return *member, nil
+}
+
+// SaveRole responsible to add a role to the team or to update
+// the role when the team already has it
+func (s *Service) SaveRole(dto RoleDTO) (TeamRole, error) {
+	count, err := dto.ParseCount()
+	if err != nil {
+		return TeamRole{}, err
+	}
+	role := TeamRole{
+		TeamID: dto.Team.Value,
+		Name:   NomineeRole(dto.Name),
+		Label:  dto.Label,
+		Count:  count,
+		Rules:  strings.Join(strings.Fields(dto.Rules), " "),
+	}
+	if role.Label == "" {
+		role.Label = dto.Name
+	}
+	if err := s.repo.SaveTeamRole(role); err != nil {
+		return TeamRole{}, errors.Wrap(err, "failed to save team role in repository")
+	}
+	return role, nil
+}
+
+// RemoveRole responsible to remove a role of the team, the team
+// keeps at least one role to nominate
+func (s *Service) RemoveRole(teamID string, name NomineeRole) error {
+	roles, err := s.ListRoles(teamID)
+	if err != nil {
+		return err
+	}
+	if findTeamRole(roles, name) == nil {
+		return errors.Errorf("the team doesn't have the role %s", name)
+	}
+	if len(roles) == 1 {
+		return errors.Errorf("the role %s is the last role of the team", name)
+	}
+	if err := s.repo.DeleteTeamRole(teamID, name); err != nil {
+		return errors.Wrap(err, "failed to delete team role in repository")
+	}
+	return nil
+}
+
+// AddExemption responsible to take a member out of the rotation until the
+// exemption expires, only the admins of the team can exempt members
+func (s *Service) AddExemption(ctx *apps.Context, dto ExemptionDTO) ([]MemberExemption, error) {
+	member, err := s.getAdministeredMember(ctx, dto.Team, dto.Member)
+	if err != nil {
+		return nil, err
+	}
+	exemption := MemberExemption{
+		MemberID:  member.ID,
+		Reason:    dto.Reason,
+		ExpiresAt: time.Time(dto.Until).Unix(),
+		CreatedBy: ctx.ActingUserID,
+	}
+	if exemption.ExpiresAt <= time.Now().Unix() {
+		return nil, errors.New("the exemption would already be expired")
+	}
+	if err := s.repo.CreateExemption(exemption); err != nil {
+		return nil, errors.Wrap(err, "failed to create exemption in repository")
+	}
+	return s.ListExemptions(dto.Team.Value)
+}
+
+// RemoveExemption responsible to put a member back in the rotation,
+// only the admins of the team can remove exemptions
+func (s *Service) RemoveExemption(ctx *apps.Context, dto ExemptionDTO) error {
+	member, err := s.getAdministeredMember(ctx, dto.Team, dto.Member)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteExemptions(member.ID); err != nil {
+		return errors.Wrap(err, "failed to delete exemptions in repository")
+	}
+	return nil
+}
+
+// ListExemptions responsible to list the exemptions of the team which didn't expire
+func (s *Service) ListExemptions(teamID string) ([]MemberExemption, error) {
+	exemptions, err := s.repo.ListTeamExemptions(teamID, time.Now().Unix())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get exemptions in repository")
+	}
+	return exemptions, nil
+}
+
+// getAdministeredTeam returns the team when the acting user is an admin of the team
+func (s *Service) getAdministeredTeam(ctx *apps.Context, teamDTO LookupDTO) (*Team, error) {
+	team, err := s.repo.GetTeamByID(teamDTO.Value)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get team in repository")
+	}
+	if team == nil {
+		return nil, errors.Errorf("team %s doesn't exist", teamDTO.Label)
+	}
+	if err := s.checkTeamAdmin(ctx, *team); err != nil {
+		return nil, err
+	}
+	return team, nil
+}
+
+// getAdministeredMember returns the member of the team when the acting
+// user is an admin of the team
+func (s *Service) getAdministeredMember(ctx *apps.Context, teamDTO LookupDTO, memberDTO MemberDTO) (*TeamMember, error) {
+	team, err := s.getAdministeredTeam(ctx, teamDTO)
+	if err != nil {
+		return nil, err
+	}
+	member, err := s.repo.GetMember(team.ID, memberDTO.UserID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get team member in repository")
+	}
+	if member == nil {
+		return nil, errors.Errorf("@%s isn't a member of team: %s", memberDTO.Label, team.Name)
+	}
+	return member, nil
+}
+
+// checkTeamAdmin returns an error unless the acting user is the owner
+// or an admin of the team or a system admin of Mattermost
+func (s *Service) checkTeamAdmin(ctx *apps.Context, team Team) error {
+	return s.authorize(ctx, PermissionTeamAdmin, &team)
+}
+
+// AddTeamAdmin responsible to make the user an admin of the team
+func (s *Service) AddTeamAdmin(ctx *apps.Context, dto TeamMemberDTO) (Team, error) {
+	team, err := s.getAdministeredTeam(ctx, dto.Team)
+	if err != nil {
+		return Team{}, err
+	}
+	admin, err := s.isTeamAdmin(*team, dto.Member.UserID)
+	if err != nil {
+		return Team{}, err
+	}
+	if admin {
+		return Team{}, errors.Errorf("@%s already is an admin of team %s", dto.Member.Label, team.Name)
+	}
+	if err := s.repo.CreateTeamAdmin(TeamAdmin{
+		TeamID:    team.ID,
+		UserID:    dto.Member.UserID,
+		Label:     dto.Member.Label,
+		CreatedBy: ctx.ActingUserID,
+	}); err != nil {
+		return Team{}, errors.Wrap(err, "failed to create team admin in repository")
+	}
+	mmclient.AsBot(ctx).DM(dto.Member.UserID, fmt.Sprintf("You are an admin of team: **%s**", team.Name))
+	return *team, nil
+}
+
+// RemoveTeamAdmin responsible to revoke the administration of the team
+// from the user, the owner always is an admin
+func (s *Service) RemoveTeamAdmin(ctx *apps.Context, dto TeamMemberDTO) (Team, error) {
+	team, err := s.getAdministeredTeam(ctx, dto.Team)
+	if err != nil {
+		return Team{}, err
+	}
+	if team.OwnerID == dto.Member.UserID {
+		return Team{}, errors.Errorf("@%s owns team %s and always is an admin", dto.Member.Label, team.Name)
+	}
+	admin, err := s.isTeamAdmin(*team, dto.Member.UserID)
+	if err != nil {
+		return Team{}, err
+	}
+	if !admin {
+		return Team{}, errors.Errorf("@%s isn't an admin of team %s", dto.Member.Label, team.Name)
+	}
+	if err := s.repo.DeleteTeamAdmin(team.ID, dto.Member.UserID); err != nil {
+		return Team{}, errors.Wrap(err, "failed to delete team admin in repository")
+	}
+	return *team, nil
+}
+
+// ListRoles responsible to list the roles of the team
+func (s *Service) ListRoles(teamID string) ([]TeamRole, error) {
+	roles, err := s.repo.ListTeamRoles(teamID)
+	if err != nil {
+		return []TeamRole{}, errors.Wrap(err, "failed to get team roles in repository")
+	}
+	return roles, nil
+}
+
+// LookupRoles responsible to return the roles nominated for the gameday
+// with a formatted data structure so the application can show up the values correctly
+func (s *Service) LookupRoles(gamedayID string) ([]LookupDTO, error) {
+	_, nominees, err := s.GetGameday(gamedayID)
+	if err != nil {
+		return []LookupDTO{}, err
+	}
+	var results []LookupDTO
+	seen := map[NomineeRole]bool{}
+	for _, n := range nominees {
+		if seen[n.Role] {
+			continue
+		}
+		seen[n.Role] = true
+		results = append(results, LookupDTO{Label: getNomineeRole(n), Value: string(n.Role)})
+	}
+	return results, nil
+}
+
+// LookupTeams responsible to return the teams with a formatted data structure
+// so the application can show up the values correctly
+func (s *Service) LookupTeams() ([]LookupDTO, error) {
+	teams, err := s.repo.ListAllTeams()
+	if err != nil {
+		return []LookupDTO{}, errors.Wrap(err, "failed to get teams in repository")
+	}
+	var results []LookupDTO
+	for _, team := range teams {
+		results = append(results, team.toLookupTeamDTO())
+	}
+	return results, nil
+}
+
+// ListTeamSummaries responsible to return the teams with their members,
+// admins and gamedays, only the team with the name when it is set
+func (s *Service) ListTeamSummaries(ctx *apps.Context, name string) ([]TeamSummary, error) {
+	var teams []Team
+	if strings.TrimSpace(name) != "" {
+		team, err := s.repo.GetTeam(strings.TrimSpace(name))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get a team in repository")
+		}
+		if team == nil {
+			return nil, errors.Errorf("team %s doesn't exist", name)
+		}
+		teams = append(teams, *team)
+	} else {
+		var err error
+		if teams, err = s.repo.ListAllTeams(); err != nil {
+			return nil, errors.Wrap(err, "failed to get teams in repository")
+		}
+	}
+
+	var summaries []TeamSummary
+	for _, team := range teams {
+		members, err := s.repo.ListTeams(team.ID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to fetch team members in repository")
+		}
+		gamedays, err := s.repo.ListGamedaysByTeam(team.ID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get the gamedays of the team in repository")
+		}
+		summary := TeamSummary{Team: team, Members: members}
+		summary.LastGameday, summary.NextGameday = lastAndNextGameday(gamedays)
+		if team.OwnerID != "" {
+			summary.Admins = append(summary.Admins, s.getUsername(ctx, members, team.OwnerID))
+		}
+		admins, err := s.repo.ListTeamAdmins(team.ID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get team admins in repository")
+		}
+		for _, a := range admins {
+			summary.Admins = append(summary.Admins, a.Label)
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, nil
+}
+
+// getUsername returns the label of the member or the Mattermost username
+// of the user, the ID when the user can't be found
+func (s *Service) getUsername(ctx *apps.Context, members []TeamMember, userID string) string {
+	for _, m := range members {
+		if m.UserID == userID {
+			return m.Label
+		}
+	}
+	user, _ := mmclient.AsBot(ctx).GetUser(userID, "")
+	if user == nil {
+		return userID
+	}
+	return user.Username
+}
+
+// CreateGameday responsible to create a gameday in database
+func (s *Service) CreateGameday(ctx *apps.Context, dto GamedayDTO) error {
+	gameday, nominees, err := s.createGameday(ctx, dto)
+	if err != nil {
+		return err
+	}
+	return s.announceGameday(ctx, gameday, nominees)
+}
+
+// createGameday nominates the members and saves the gameday without
+// notifying anyone
+func (s *Service) createGameday(ctx *apps.Context, dto GamedayDTO) (Gameday, []GamedayNominee, error) {
+	preview, err := s.PreviewGameday(ctx, dto)
+	if err != nil {
+		return Gameday{}, nil, err
+	}
+	gameday, nominees := preview.Gameday, preview.Nominees
+	gameday.ID, err = s.repo.CreateGamedayWithNominees(gameday, nominees)
+	if err != nil {
+		return Gameday{}, nil, errors.Wrap(err, "failed to create a gameday")
+	}
+	return gameday, nominees, nil
+}
+
+// announceGameday sends the invitations of the created gameday to the
+// members and the nominees and notifies the subscribers
+func (s *Service) announceGameday(ctx *apps.Context, gameday Gameday, nominees []GamedayNominee) error {
+	members, err := s.repo.ListTeams(gameday.TeamID)
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch team members in repository")
+	}
+	nominated := map[string]bool{}
+	for _, n := range nominees {
+		nominated[n.UserID] = true
+	}
+	for _, m := range members {
+		if m.wantsAnnouncements() || nominated[m.UserID] {
+			_, _ = mmclient.AsBot(ctx).DMPost(m.UserID, newRSVPPost(ctx.AppID, gameday, m.Timezone))
+		}
+	}
+	timezones := memberTimezones(members)
+	for _, n := range nominees {
+		msg := fmt.Sprintf("You are the **%s** for gameday: _**%s**_ scheduled at: _**%s**_", getNomineeRole(n), gameday.Title, formatLocalTime(gameday.ScheduledAt, timezones[n.UserID]))
+		_, _ = mmclient.AsBot(ctx).DMPost(n.UserID, newAcknowledgementPost(ctx.AppID, gameday, msg))
+	}
+
+	s.warnUnavailableNominees(ctx, gameday, nominees)
+
+	s.publishGamedayEvent(ctx, gameday.ID, GamedayCreatedEvent)
+	return nil
+}
+
+// PreviewGameday responsible to run the nomination of a new gameday
+// without saving it nor notifying anyone
+func (s *Service) PreviewGameday(ctx *apps.Context, dto GamedayDTO) (NominationPreview, error) {
+	gameday := Gameday{
+		Title:       dto.Name,
+		TeamID:      dto.Team.Value,
+		State:       GamedayScheduledState,
+		ScheduledAt: dto.ScheduledAt.Unix(),
+		ChannelID:   ctx.ChannelID,
+		Scenarios:   dto.Scenarios,
+		Checklist:   dto.Checklist,
+		Duration:    dto.Duration,
+		Roles:       dto.Roles,
+	}
+	if gameday.Duration == 0 {
+		gameday.Duration = defaultDuration
+	}
+	team, err := s.repo.GetTeamByID(dto.Team.Value)
+	if err != nil {
+		return NominationPreview{}, errors.Wrap(err, "failed to get team in repository")
+	}
+	if team == nil {
+		return NominationPreview{}, errors.Errorf("team %s not found", dto.Team.Label)
+	}
+	gameday.Team = *team
+	teamRoles, err := s.ListRoles(team.ID)
+	if err != nil {
+		return NominationPreview{}, err
+	}
+	if gameday.Roles, err = expandRoles(teamRoles, dto.Roles); err != nil {
+		return NominationPreview{}, errors.Wrapf(err, "failed to get the roles of team %s", team.Name)
+	}
+	gameday.CreatedAt = time.Now().UnixNano() / int64(time.Millisecond)
+	gameday.Seed = s.nextSeed()
+	pool, err := s.getNominationPool(gameday, gameday.CreatedAt)
+	if err != nil {
+		return NominationPreview{}, err
+	}
+	strategy := getNominationStrategy(team.NominationStrategy)
+	roles := gamedayRoles(teamRoles, gameday.Roles)
+	gameday.Snapshot = newNominationSnapshot(strategy, pool.candidates, pool.nominations, roles).String()
+	preview := NominationPreview{Gameday: gameday, Candidates: pool.candidates, Exclusions: pool.exclusions}
+	rnd := rand.New(rand.NewSource(gameday.Seed))
+	preview.Nominees, err = nominateRoles(strategy, pool.candidates, pool.nominations, roles, rnd)
+	if err != nil {
+		return preview, errors.Wrapf(err, "failed to nominate the members of team %s", team.Name)
+	}
+	return preview, nil
+}
+
+// warnUnavailableNominees warns the acting user when a nominee of a gameday
+// which starts soon is on do not disturb or out of office in Mattermost
+func (s *Service) warnUnavailableNominees(ctx *apps.Context, gameday Gameday, nominees []GamedayNominee) {
+	if time.Until(time.Unix(gameday.ScheduledAt, 0)) > statusCheckWindow {
+		return
+	}
+	for _, n := range nominees {
+		status, res := mmclient.AsBot(ctx).GetUserStatus(n.UserID, "")
+		if res.Error != nil || status == nil {
+			continue
+		}
+		if status.Status == model.STATUS_DND || status.Status == model.STATUS_OUT_OF_OFFICE {
+			mmclient.AsBot(ctx).DM(ctx.ActingUserID, fmt.Sprintf("The **%s** @%s of gameday: _**%s**_ is currently `%s`, you can draw another member with `gameday renominate`", getNomineeRole(n), n.Label, gameday.Title, status.Status))
+		}
+	}
+}
+
+// SetAway responsible to register a period when the acting user can't be
+// nominated, returns the upcoming away periods of the user
+func (s *Service) SetAway(ctx *apps.Context, dto AwayDTO) ([]MemberAway, error) {
+	away := MemberAway{
+		UserID:   ctx.ActingUserID,
+		StartsAt: dto.From.Unix(),
+		EndsAt:   dto.To.Unix(),
+	}
+	if away.EndsAt <= away.StartsAt {
+		return nil, errors.New("the end of the away period must be after its start")
+	}
+	if away.EndsAt <= time.Now().Unix() {
+		return nil, errors.New("the away period is already over")
+	}
+	if err := s.repo.CreateAway(away); err != nil {
+		return nil, errors.Wrap(err, "failed to create away period in repository")
+	}
+	aways, err := s.repo.ListAwayByUser(ctx.ActingUserID, time.Now().Unix())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get away periods in repository")
+	}
+	return aways, nil
+}
+
+// Reschedule responsible to move a scheduled gameday to another time,
+// returns the nominees who are away at the new time
+func (s *Service) Reschedule(ctx *apps.Context, dto RescheduleDTO) ([]GamedayNominee, error) {
+	gameday, nominees, err := s.GetGameday(dto.ID.Value)
+	if err != nil {
+		return nil, err
+	}
+	if gameday.State != GamedayScheduledState {
+		return nil, errors.Errorf("gameday %s is %s", gameday.Title, gameday.State)
+	}
+	previous := time.Unix(gameday.ScheduledAt, 0).Format(timeLayout)
+	gameday.ScheduledAt = dto.ScheduledAt.Unix()
+	if err := s.repo.UpdateGamedaySchedule(gameday.ID, gameday.ScheduledAt); err != nil {
+		return nil, errors.Wrapf(err, "failed to reschedule GamedayID: %s", gameday.ID)
+	}
+	if err := s.repo.CreateHistory(GamedayHistory{
+		GamedayID: gameday.ID,
+		UserID:    ctx.ActingUserID,
+		Action:    RescheduledAction,
+		Message:   fmt.Sprintf("rescheduled from %s to %s", previous, dto.ScheduledAt.String()),
+	}); err != nil {
+		return nil, errors.Wrapf(err, "failed to log the history for GamedayID: %s", gameday.ID)
+	}
+
+	aways, err := s.repo.ListAwayBetween(gameday.ScheduledAt, gameday.endsAt())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch the away periods")
+	}
+	away := map[string]bool{}
+	for _, a := range aways {
+		away[a.UserID] = true
+	}
+	var unavailable []GamedayNominee
+	for _, n := range nominees {
+		scheduledAt := formatLocalTime(gameday.ScheduledAt, s.timezoneOf(gameday.TeamID, n.UserID))
+		mmclient.AsBot(ctx).DM(n.UserID, fmt.Sprintf("Gameday: _**%s**_ where you are the **%s** is rescheduled to: _**%s**_", gameday.Title, getNomineeRole(n), scheduledAt))
+		if away[n.UserID] {
+			unavailable = append(unavailable, n)
+		}
+	}
+	s.warnUnavailableNominees(ctx, gameday, nominees)
+
+	s.refreshGamedayPost(ctx, gameday, nominees)
+	return unavailable, nil
+}
+
+// VerifyNominees responsible to replay the nominations of the gameday with
+// its recorded seed and the snapshot of the input taken when it was created
+func (s *Service) VerifyNominees(gamedayID string) (NominationVerification, error) {
+	gameday, nominees, err := s.GetGameday(gamedayID)
+	if err != nil {
+		return NominationVerification{}, err
+	}
+	verification := NominationVerification{Gameday: gameday, Nominees: nominees}
+	if gameday.Seed == 0 || gameday.Snapshot == "" {
+		return verification, errors.Errorf("the nomination of gameday %s wasn't recorded", gameday.Title)
+	}
+	snapshot, err := parseNominationSnapshot(gameday.Snapshot)
+	if err != nil {
+		return verification, err
+	}
+
+	verification.Strategy = snapshot.Strategy
+	verification.Replayed, err = snapshot.replay(gameday.Seed)
+	if err != nil {
+		return verification, errors.Wrap(err, "failed to replay the nominations")
+	}
+	return verification, nil
+}
+
+// ApplyTemplate fills the gameday with the configuration of the selected
+// template, the name and the team of the gameday take precedence
+func (s *Service) ApplyTemplate(dto GamedayDTO) (GamedayDTO, error) {
+	template, err := s.repo.GetTemplate(strings.ToLower(dto.Template.Value))
+	if err != nil {
+		return GamedayDTO{}, errors.Wrap(err, "failed to get gameday template in repository")
+	}
+	if template == nil {
+		return GamedayDTO{}, errors.Errorf("gameday template: %s not found", dto.Template.Value)
+	}
+
+	result := template.toGamedayDTO(dto.ScheduledAt)
+	if dto.Name != "" {
+		result.Name = dto.Name
+	}
+	if dto.Team.Value != "" {
+		result.Team = dto.Team
+	}
+	result.DryRun = dto.DryRun
+	return result, nil
+}
+
+// CloneGameday creates a new gameday with the configuration of an existing
+// one and fresh nominations
+func (s *Service) CloneGameday(ctx *apps.Context, dto CloneGamedayDTO) (GamedayDTO, error) {
+	source, _, err := s.GetGameday(dto.ID.Value)
+	if err != nil {
+		return GamedayDTO{}, err
+	}
+	clone := source.toGameDayDTO()
+	clone.ScheduledAt = dto.ScheduledAt
+	clone.State = GamedayScheduledState
+	clone.Reason = ""
+	if err := s.CreateGameday(ctx, clone); err != nil {
+		return GamedayDTO{}, errors.Wrapf(err, "failed to clone GamedayID: %s", dto.ID.Value)
+	}
+	return clone, nil
+}
+
+// UpdateGamedayState updates the state of a gameday accordingly
+// to the action
+func (s *Service) UpdateGamedayState(ctx *apps.Context, gamedayID string, state GamedayState, reason string) error {
+	gameday, err := s.repo.GetGameday(gamedayID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get gameday in repository")
+	}
+	if gameday == nil {
+		return errors.Errorf("gameday with ID: %s not found", gamedayID)
+	}
+	if !gameday.State.canBecome(state) {
+		return errors.Errorf("gameday %s is %s, it can't be %s", gameday.Title, gameday.State, state)
+	}
+	if err := s.repo.UpdateGamedayState(gamedayID, state, reason); err != nil {
+		return err
+	}
+	// the state is saved, the members are notified at best
+	switch state {
+	case GamedayInProgressState:
+		if err := s.promptAttendance(ctx, gamedayID); err != nil {
+			s.logger.WithField("gameday", gamedayID).WithError(err).Error("failed to prompt the attendance")
+		}
+	case GamedayCancelledState:
+		if err := s.notifyCancellation(ctx, gamedayID); err != nil {
+			s.logger.WithField("gameday", gamedayID).WithError(err).Error("failed to notify the cancellation")
+		}
+	}
+	s.publishGamedayEvent(ctx, gamedayID, stateEvents[state])
+	return nil
+}
+
+// RespondRSVP stores the response of the acting user to a gameday invitation
+// and alerts the admins of the team when a nominee can't attend
+func (s *Service) RespondRSVP(ctx *apps.Context, gamedayID string, response RSVPResponse) (Gameday, error) {
+	gameday, nominees, err := s.GetGameday(gamedayID)
+	if err != nil {
+		return Gameday{}, err
+	}
+	member, err := s.repo.GetMember(gameday.TeamID, ctx.ActingUserID)
+	if err != nil {
+		return Gameday{}, errors.Wrap(err, "failed to get team member in repository")
+	}
+	if member == nil {
+		return Gameday{}, errors.Errorf("you aren't a member of team: %s", gameday.Team.Name)
+	}
+	if err := s.repo.SaveRSVP(gamedayID, member.ID, response); err != nil {
+		return Gameday{}, errors.Wrapf(err, "failed to save the response for GamedayID: %s", gamedayID)
+	}
+	if response != RSVPDeclined {
+		return gameday, nil
+	}
+	for _, n := range nominees {
+		if n.MemberID != member.ID {
+			continue
+		}
+		// the response is saved, the admins are alerted at best
+		adminIDs, err := s.listTeamAdminIDs(gameday.Team)
+		if err != nil {
+			s.logger.WithField("gameday", gamedayID).WithError(err).Error("failed to alert the admins of the team")
+			return gameday, nil
+		}
+		for _, id := range adminIDs {
+			mmclient.AsBot(ctx).DM(id, fmt.Sprintf("@%s can't attend gameday: _**%s**_ where they are nominated as **%s**. You may want to re-nominate.", member.Label, gameday.Title, getNomineeRole(n)))
+		}
+	}
+	return gameday, nil
+}
+
+// TakeAttendance records the attendance of a member, only the Master of
+// Disaster of the gameday is allowed to take attendance
+func (s *Service) TakeAttendance(ctx *apps.Context, gamedayID, userID string) error {
+	gameday, nominees, err := s.GetGameday(gamedayID)
+	if err != nil {
+		return err
+	}
+	if !isMasterOfDisaster(nominees, ctx.ActingUserID) {
+		return errors.New("only the Master of Disaster can take attendance")
+	}
+	member, err := s.repo.GetMember(gameday.TeamID, userID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get team member in repository")
+	}
+	if member == nil {
+		return errors.Errorf("user isn't a member of team: %s", gameday.Team.Name)
+	}
+	if err := s.repo.MarkAttendance(gamedayID, member.ID); err != nil {
+		return errors.Wrapf(err, "failed to take attendance for GamedayID: %s", gamedayID)
+	}
+	return nil
+}
+
+// Renominate responsible to draw a new member for the role of the gameday,
+// the current nominees and the members who don't match the rules of the
+// role are excluded
+func (s *Service) Renominate(ctx *apps.Context, gamedayID string, role NomineeRole, userID string) (GamedayNominee, error) {
+	r, err := s.renominate(ctx, gamedayID, role, userID, nil)
+	if err != nil {
+		return GamedayNominee{}, err
+	}
+	s.notifyReplacement(ctx, r)
+	return r.nominee, nil
+}
+
+// replacement a nominee of a gameday replaced by another member of the
+// team, both are notified once the replacement is saved
+type replacement struct {
+	gameday  Gameday
+	previous GamedayNominee
+	member   TeamMember
+	nominee  GamedayNominee
+}
+
+// renominate draws and saves a new member for the role of the gameday
+// without notifying anyone, the excluded members can't be drawn
+func (s *Service) renominate(ctx *apps.Context, gamedayID string, role NomineeRole, userID string, excluded map[string]bool) (replacement, error) {
+	gameday, nominees, err := s.getActiveGameday(gamedayID)
+	if err != nil {
+		return replacement{}, err
+	}
+	current, err := selectNominee(gameday, nominees, role, userID)
+	if err != nil {
+		return replacement{}, err
+	}
+	pool, err := s.getNominationPool(gameday, time.Now().UnixNano()/int64(time.Millisecond))
+	if err != nil {
+		return replacement{}, err
+	}
+	teamRoles, err := s.ListRoles(gameday.TeamID)
+	if err != nil {
+		return replacement{}, err
+	}
+	rules, err := parseRoleRules(gamedayRoles(teamRoles, string(role))[0].Rules)
+	if err != nil {
+		return replacement{}, errors.Wrapf(err, "invalid rules of the role %s", role)
+	}
+
+	var available []TeamMember
+	for _, m := range pool.candidates {
+		if !isNominated(nominees, m.ID) && !excluded[m.ID] {
+			available = append(available, m)
+		}
+	}
+	strategy := getNominationStrategy(current.Strategy)
+	eligible, explanations := eligibleMembers(available, pool.nominations, role, rules)
+	member := strategy.Nominate(eligible, pool.nominations, role, rand.New(rand.NewSource(s.nextSeed())))
+	if member == nil {
+		return replacement{}, errors.Wrapf(ErrTeamTooSmall, "no other member of team %s can be %s", gameday.Team.Name, getNomineeRole(*current))
+	}
+	return s.saveReplacement(ctx, gameday, *current, *member, RenominatedAction, nominationReason(strategy, eligible, explanations))
+}
+
+// nominationPool the members who can be nominated for a gameday, the
+// nominations of the team and why the other members are left out
+type nominationPool struct {
+	candidates  []TeamMember
+	nominations []GamedayNominee
+	exclusions  []string
+}
+
+// getNominationPool returns the members who can be nominated for the
+// gameday and the nominations of the team as they were at the given time
+func (s *Service) getNominationPool(gameday Gameday, at int64) (nominationPool, error) {
+	members, err := s.repo.ListTeams(gameday.TeamID)
+	if err != nil {
+		return nominationPool{}, errors.Wrap(err, "failed to fetch team members in repository")
+	}
+	nominations, err := s.repo.ListTeamNominations(gameday.TeamID)
+	if err != nil {
+		return nominationPool{}, errors.Wrap(err, "failed to fetch the nominations of the team")
+	}
+	aways, err := s.repo.ListAwayBetween(gameday.ScheduledAt, gameday.endsAt())
+	if err != nil {
+		return nominationPool{}, errors.Wrap(err, "failed to fetch the away periods")
+	}
+	exemptions, err := s.repo.ListTeamExemptions(gameday.TeamID, gameday.ScheduledAt)
+	if err != nil {
+		return nominationPool{}, errors.Wrap(err, "failed to fetch the exemptions of the team")
+	}
+	members = membersAt(members, at)
+	return nominationPool{
+		candidates:  unexemptedMembers(availableMembers(members, aways, at), exemptions, at),
+		nominations: nominationsAt(nominations, at),
+		exclusions:  unavailableReasons(members, aways, exemptions, at),
+	}, nil
 }
 
-func NewService(repo GamedayRepository) *Service {
-	return &Service{
-		repo: repo,
+// SwapNominee responsible to replace the nominee of the role with a
+// volunteer of the team, the nominee user is only needed when several
+// members hold the role
+func (s *Service) SwapNominee(ctx *apps.Context, gamedayID string, role NomineeRole, nomineeUserID, userID string) (GamedayNominee, error) {
+	gameday, nominees, err := s.getActiveGameday(gamedayID)
+	if err != nil {
+		return GamedayNominee{}, err
+	}
+	current, err := selectNominee(gameday, nominees, role, nomineeUserID)
+	if err != nil {
+		return GamedayNominee{}, err
+	}
+	member, err := s.repo.GetMember(gameday.TeamID, userID)
+	if err != nil {
+		return GamedayNominee{}, errors.Wrap(err, "failed to get team member in repository")
+	}
+	if member == nil {
+		return GamedayNominee{}, errors.Errorf("user isn't a member of team: %s", gameday.Team.Name)
+	}
+	for _, n := range nominees {
+		if n.MemberID == member.ID {
+			return GamedayNominee{}, errors.Errorf("@%s is already the %s of gameday %s", member.Label, getNomineeRole(n), gameday.Title)
+		}
+	}
+	return s.replaceNominee(ctx, gameday, *current, *member, SwappedAction, "volunteered")
+}
+
+// replaceNominee updates the nominee with the member, notifies both of them
+// and logs the change in the history of the gameday
+func (s *Service) replaceNominee(ctx *apps.Context, gameday Gameday, nominee GamedayNominee, member TeamMember, action GamedayHistoryAction, reason string) (GamedayNominee, error) {
+	r, err := s.saveReplacement(ctx, gameday, nominee, member, action, reason)
+	if err != nil {
+		return GamedayNominee{}, err
+	}
+	s.notifyReplacement(ctx, r)
+	return r.nominee, nil
+}
+
+// saveReplacement updates the nominee with the member and logs the change
+// in the history of the gameday
+func (s *Service) saveReplacement(ctx *apps.Context, gameday Gameday, nominee GamedayNominee, member TeamMember, action GamedayHistoryAction, reason string) (replacement, error) {
+	if err := s.repo.UpdateNomineeMember(nominee.ID, member.ID, reason); err != nil {
+		return replacement{}, errors.Wrapf(err, "failed to replace the nominee for GamedayID: %s", gameday.ID)
 	}
+	if err := s.repo.CreateHistory(GamedayHistory{
+		GamedayID: gameday.ID,
+		UserID:    ctx.ActingUserID,
+		Action:    action,
+		Message:   fmt.Sprintf("@%s replaced @%s as %s (%s)", member.Label, nominee.Label, getNomineeRole(nominee), action),
+	}); err != nil {
+		return replacement{}, errors.Wrapf(err, "failed to log the history for GamedayID: %s", gameday.ID)
+	}
+
+	replaced := nominee
+	replaced.MemberID = member.ID
+	replaced.UserID = member.UserID
+	replaced.Label = member.Label
+	replaced.Reason = reason
+	return replacement{gameday: gameday, previous: nominee, member: member, nominee: replaced}, nil
 }
 
-// CreateTeam responsible to create a team by the DTO sent from
-// mattermost app
-func (s *Service) CreateTeam(dto CreateTeamDTO) ([]TeamMember, error) {
-	var teamID string
+// notifyReplacement tells the previous nominee and the member about the
+// saved replacement and refreshes the post of the gameday
+func (s *Service) notifyReplacement(ctx *apps.Context, r replacement) {
+	roleLabel := getNomineeRole(r.previous)
+	scheduledAt := formatLocalTime(r.gameday.ScheduledAt, s.timezoneOf(r.gameday.TeamID, r.previous.UserID))
+	mmclient.AsBot(ctx).DM(r.previous.UserID, fmt.Sprintf("You are no longer the **%s** for gameday: _**%s**_ scheduled at: _**%s**_, @%s replaces you", roleLabel, r.gameday.Title, scheduledAt, r.member.Label))
+	msg := fmt.Sprintf("You are the **%s** for gameday: _**%s**_ scheduled at: _**%s**_, you replace @%s", roleLabel, r.gameday.Title, r.member.localTime(r.gameday.ScheduledAt), r.previous.Label)
+	_, _ = mmclient.AsBot(ctx).DMPost(r.member.UserID, newAcknowledgementPost(ctx.AppID, r.gameday, msg))
 
-	team, err := s.repo.GetTeam(strings.ToLower(dto.Name))
+	nominees, err := s.repo.ListGamedayNominees(r.gameday.ID)
 	if err != nil {
-		return []TeamMember{}, errors.Wrap(err, "failed to get a team in repository")
+		s.logger.WithField("gameday", r.gameday.ID).WithError(err).Error("failed to refresh the gameday post")
+		return
 	}
-	if team != nil {
-		teamID = team.ID
-	} else {
-		teamID, err = s.repo.CreateTeam(dto.Name)
+	sortNominees(r.gameday.Roles, nominees)
+	s.refreshGamedayPost(ctx, r.gameday, nominees)
+}
+
+// getActiveGameday returns the gameday and its nominees when the gameday
+// is scheduled or in progress
+func (s *Service) getActiveGameday(gamedayID string) (Gameday, []GamedayNominee, error) {
+	gameday, nominees, err := s.GetGameday(gamedayID)
+	if err != nil {
+		return Gameday{}, nil, err
+	}
+	if gameday.State != GamedayScheduledState && gameday.State != GamedayInProgressState {
+		return Gameday{}, nil, errors.Errorf("gameday %s is %s", gameday.Title, gameday.State)
+	}
+	return gameday, nominees, nil
+}
+
+// GetGamedayHistory responsible to list the changes of the gameday
+func (s *Service) GetGamedayHistory(gamedayID string) ([]GamedayHistory, error) {
+	history, err := s.repo.ListGamedayHistory(gamedayID)
+	if err != nil {
+		return []GamedayHistory{}, errors.Wrap(err, "failed to get gameday history in repository")
+	}
+	return history, nil
+}
+
+// ListAttendance responsible to list the responses and the attendance
+// of the members for a gameday
+func (s *Service) ListAttendance(gamedayID string) ([]GamedayRSVP, error) {
+	rsvps, err := s.repo.ListGamedayRSVPs(gamedayID)
+	if err != nil {
+		return []GamedayRSVP{}, errors.Wrap(err, "failed to get gameday rsvps in repository")
+	}
+	return rsvps, nil
+}
+
+// notifyCancellation lets every member and nominee know that the gameday
+// was cancelled and why
+func (s *Service) notifyCancellation(ctx *apps.Context, gamedayID string) error {
+	gameday, nominees, err := s.GetGameday(gamedayID)
+	if err != nil {
+		return err
+	}
+	members, err := s.repo.ListTeams(gameday.TeamID)
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch team members in repository")
+	}
+
+	// the nominees are always notified, the members unless they only
+	// want the messages about their nominations
+	var userIDs []string
+	for _, m := range members {
+		if m.wantsAnnouncements() {
+			userIDs = append(userIDs, m.UserID)
+		}
+	}
+	for _, n := range nominees {
+		userIDs = append(userIDs, n.UserID)
+	}
+	timezones := memberTimezones(members)
+	notified := map[string]bool{}
+	for _, userID := range userIDs {
+		if notified[userID] {
+			continue
+		}
+		notified[userID] = true
+		msg := fmt.Sprintf("Gameday: _**%s**_ scheduled at: _**%s**_ was cancelled", gameday.Title, formatLocalTime(gameday.ScheduledAt, timezones[userID]))
+		if gameday.Reason != "" {
+			msg += fmt.Sprintf("\n**Reason:** %s", gameday.Reason)
+		}
+		mmclient.AsBot(ctx).DM(userID, msg)
+	}
+	return nil
+}
+
+// promptAttendance reminds the Master of Disaster to take attendance
+// when the gameday starts
+func (s *Service) promptAttendance(ctx *apps.Context, gamedayID string) error {
+	gameday, nominees, err := s.GetGameday(gamedayID)
+	if err != nil {
+		return err
+	}
+	rsvps, err := s.ListAttendance(gamedayID)
+	if err != nil {
+		return err
+	}
+	msg := fmt.Sprintf("Gameday: _**%s**_ just started, take attendance with `/chaos-engine gameday attendance --id %s --member @user`\n", gameday.Title, gameday.ID)
+	msg += getAttendanceMarkdown(rsvps)
+	for _, n := range nominees {
+		if nominatedFor(n, MasterOfDisasterRole) {
+			mmclient.AsBot(ctx).DM(n.UserID, msg)
+		}
+	}
+	return nil
+}
+
+// GetGameday responsible to return a gameday with its nominees
+func (s *Service) GetGameday(gamedayID string) (Gameday, []GamedayNominee, error) {
+	gameday, err := s.repo.GetGameday(gamedayID)
+	if err != nil {
+		return Gameday{}, []GamedayNominee{}, errors.Wrap(err, "failed to get gameday in repository")
+	}
+	if gameday == nil {
+		return Gameday{}, []GamedayNominee{}, errors.Errorf("gameday with ID: %s not found", gamedayID)
+	}
+	nominees, err := s.repo.ListGamedayNominees(gamedayID)
+	if err != nil {
+		return Gameday{}, []GamedayNominee{}, errors.Wrap(err, "failed to fetch gameday nominees")
+	}
+	sortNominees(gameday.Roles, nominees)
+	return *gameday, nominees, nil
+}
+
+// publishGamedayEvent refreshes the interactive post of the gameday and
+// notifies the channels which are subscribed to the event, the change is
+// already saved so the failures are only logged
+func (s *Service) publishGamedayEvent(ctx *apps.Context, gamedayID string, event GamedayEvent) {
+	gameday, nominees, err := s.GetGameday(gamedayID)
+	if err != nil {
+		s.logger.WithField("gameday", gamedayID).WithError(err).Error("failed to publish the gameday event")
+		return
+	}
+	s.refreshGamedayPost(ctx, gameday, nominees)
+	if err := s.notifySubscribers(ctx, gameday, event); err != nil {
+		s.logger.WithField("gameday", gamedayID).WithError(err).Error("failed to notify the subscribers")
+	}
+}
+
+// refreshGamedayPost creates or updates the interactive post of the gameday
+// so the channel reflects the current state, the change is already saved
+// so the failures are only logged and the post catches up on the next change
+func (s *Service) refreshGamedayPost(ctx *apps.Context, gameday Gameday, nominees []GamedayNominee) {
+	if err := s.writeGamedayPost(ctx, gameday, nominees); err != nil {
+		s.logger.WithField("gameday", gameday.ID).WithError(err).Error("failed to refresh the gameday post")
+	}
+}
+
+// writeGamedayPost creates the interactive post of the gameday or updates it
+func (s *Service) writeGamedayPost(ctx *apps.Context, gameday Gameday, nominees []GamedayNominee) error {
+	gamedayID := gameday.ID
+	if gameday.ChannelID == "" {
+		return nil
+	}
+
+	post := newGamedayPost(ctx.AppID, gameday, nominees)
+	if gameday.PostID == "" {
+		created, err := mmclient.AsBot(ctx).CreatePost(post)
 		if err != nil {
-			return []TeamMember{}, errors.Wrap(err, "failed to create a team in repository")
+			return errors.Wrapf(err, "failed to create the post for GamedayID: %s", gamedayID)
 		}
+		return s.repo.UpdateGamedayPost(gamedayID, gameday.ChannelID, created.Id)
 	}
 
-	if err := s.repo.CreateMember(teamID, dto.Member.UserID, dto.Member.Label); err != nil {
-		return []TeamMember{}, errors.Wrap(err, "failed to create a member in repository")
+	post.Id = gameday.PostID
+	if _, res := mmclient.AsBot(ctx).UpdatePost(gameday.PostID, post); res.Error != nil {
+		return errors.Wrapf(res.Error, "failed to update the post for GamedayID: %s", gamedayID)
 	}
-	members, err := s.repo.ListTeams(teamID)
+	return nil
+}
+
+// notifySubscribers posts the gameday event to every channel subscribed
+// to the team of the gameday
+func (s *Service) notifySubscribers(ctx *apps.Context, gameday Gameday, event GamedayEvent) error {
+	subscriptions, err := s.repo.ListSubscriptionsByTeam(gameday.TeamID)
 	if err != nil {
-		return []TeamMember{}, errors.Wrap(err, "failed to fetch team members in repository")
+		return errors.Wrapf(err, "failed to fetch subscriptions for TeamID: %s", gameday.TeamID)
+	}
+	msg := getGamedayEventMessage(gameday, event)
+	for _, sub := range subscriptions {
+		if !sub.HasEvent(event) {
+			continue
+		}
+		_, _ = mmclient.AsBot(ctx).CreatePost(&model.Post{ChannelId: sub.ChannelID, Message: msg})
 	}
-	return members, nil
+	return nil
 }
 
-// LookupTeams responsible to return the teams with a formatted data structure
+// Subscribe subscribes the channel of the call to the events of a team
+func (s *Service) Subscribe(ctx *apps.Context, dto SubscriptionDTO) error {
+	if ctx.ChannelID == "" {
+		return errors.New("failed to subscribe: missing channel")
+	}
+	sub := Subscription{
+		TeamID:    dto.Team.Value,
+		ChannelID: ctx.ChannelID,
+		Events:    strings.Join(dto.ParseEvents(), ","),
+	}
+	if err := s.repo.SaveSubscription(sub); err != nil {
+		return errors.Wrap(err, "failed to save subscription in repository")
+	}
+	return nil
+}
+
+// Unsubscribe removes the subscription of the channel of the call
+// to the events of a team
+func (s *Service) Unsubscribe(ctx *apps.Context, teamID string) error {
+	if err := s.repo.DeleteSubscription(teamID, ctx.ChannelID); err != nil {
+		return errors.Wrap(err, "failed to delete subscription in repository")
+	}
+	return nil
+}
+
+// ListSubscriptions responsible to list the subscriptions of a channel
+func (s *Service) ListSubscriptions(channelID string) ([]Subscription, error) {
+	subscriptions, err := s.repo.ListSubscriptionsByChannel(channelID)
+	if err != nil {
+		return []Subscription{}, errors.Wrap(err, "failed to get subscriptions in repository")
+	}
+	return subscriptions, nil
+}
+
+// CreateTemplate responsible to create a named gameday template
+func (s *Service) CreateTemplate(dto TemplateDTO) error {
+	duration, err := dto.ParseDuration()
+	if err != nil {
+		return err
+	}
+	if duration == 0 {
+		duration = defaultDuration
+	}
+	template := GamedayTemplate{
+		Name:      strings.ToLower(dto.Name),
+		Title:     dto.Title,
+		TeamID:    dto.Team.Value,
+		Scenarios: dto.Scenarios,
+		Checklist: dto.Checklist,
+		Duration:  duration,
+		Roles:     dto.Roles,
+	}
+	if err := s.repo.CreateTemplate(template); err != nil {
+		return errors.Wrap(err, "failed to create gameday template in repository")
+	}
+	return nil
+}
+
+// EditTemplate responsible to update the provided fields of a gameday template
+func (s *Service) EditTemplate(dto TemplateDTO) error {
+	template, err := s.repo.GetTemplate(strings.ToLower(dto.Name))
+	if err != nil {
+		return errors.Wrap(err, "failed to get gameday template in repository")
+	}
+	if template == nil {
+		return errors.Errorf("gameday template: %s not found", dto.Name)
+	}
+
+	duration, err := dto.ParseDuration()
+	if err != nil {
+		return err
+	}
+	if duration != 0 {
+		template.Duration = duration
+	}
+	if dto.Title != "" {
+		template.Title = dto.Title
+	}
+	if dto.Team.Value != "" {
+		template.TeamID = dto.Team.Value
+	}
+	if dto.Scenarios != "" {
+		template.Scenarios = dto.Scenarios
+	}
+	if dto.Checklist != "" {
+		template.Checklist = dto.Checklist
+	}
+	if dto.Roles != "" {
+		template.Roles = dto.Roles
+	}
+	if err := s.repo.UpdateTemplate(*template); err != nil {
+		return errors.Wrap(err, "failed to update gameday template in repository")
+	}
+	return nil
+}
+
+// ListTemplates responsible to list the gameday templates
+func (s *Service) ListTemplates() ([]GamedayTemplate, error) {
+	templates, err := s.repo.ListTemplates()
+	if err != nil {
+		return []GamedayTemplate{}, errors.Wrap(err, "failed to get gameday templates in repository")
+	}
+	return templates, nil
+}
+
+// LookupTemplates responsible to return the templates with a formatted data structure
 // so the application can show up the values correctly
-func (s *Service) LookupTeams() ([]LookupDTO, error) {
-	teams, err := s.repo.GetTeams()
+func (s *Service) LookupTemplates() ([]LookupDTO, error) {
+	templates, err := s.ListTemplates()
 	if err != nil {
-		return []LookupDTO{}, errors.Wrap(err, "failed to get teams in repository")
+		return []LookupDTO{}, err
 	}
 	var results []LookupDTO
-	for _, team := range teams {
-		results = append(results, team.toLookupTeamDTO())
+	for _, t := range templates {
+		results = append(results, t.toLookupTemplateDTO())
 	}
 	return results, nil
 }
 
-// GetTeams responsible to return the full list of teams
-func (s *Service) GetTeams() ([]TeamMember, error) {
-	teams, err := s.repo.GetTeams()
+// Import responsible to create the teams, members and gamedays of the content,
+// nothing is created on a dry run or when a line is invalid
+func (s *Service) Import(ctx *apps.Context, dto ImportDTO) (ImportReport, error) {
+	report := ImportReport{DryRun: dto.DryRun}
+	records, err := parseImport(ImportFormat(dto.Format.Value), dto.Content, &report)
+	if err != nil {
+		return report, errors.Wrap(err, "failed to parse the imported content")
+	}
+
+	// the teams without members are known too, their gamedays and roles
+	// are checked like the ones of the other teams
+	allTeams, err := s.repo.ListAllTeams()
+	if err != nil {
+		return report, errors.Wrap(err, "failed to get teams in repository")
+	}
+	teams := map[string]Team{}
+	for _, t := range allTeams {
+		teams[t.Slug] = t
+	}
+	teamMembers, err := s.repo.GetTeams()
+	if err != nil {
+		return report, errors.Wrap(err, "failed to get team members in repository")
+	}
+	members := map[string]bool{}
+	for _, m := range teamMembers {
+		members[m.Team.Slug+"/"+m.UserID] = true
+	}
+
+	users := map[string]string{}
+	gamedays := map[string]bool{}
+	for _, record := range records {
+		if err := record.validate(); err != nil {
+			report.addError(record.Line, "%s", err)
+			continue
+		}
+		teamKey := teamSlug(record.Team)
+		team, ok := teams[teamKey]
+		if !ok {
+			team = Team{Name: record.Team, Slug: teamKey}
+			teams[teamKey] = team
+			report.Teams = append(report.Teams, team.Name)
+		}
+
+		for _, username := range record.Members {
+			userID, ok := users[username]
+			if !ok {
+				user, resp := mmclient.AsBot(ctx).GetUserByUsername(username, "")
+				if resp.Error != nil && resp.StatusCode != http.StatusNotFound {
+					return report, errors.Wrapf(resp.Error, "failed to get the user @%s", username)
+				}
+				if user == nil {
+					report.addError(record.Line, "unknown user @%s", username)
+					continue
+				}
+				userID = user.Id
+				users[username] = userID
+			}
+			if members[teamKey+"/"+userID] {
+				report.Skipped++
+				continue
+			}
+			members[teamKey+"/"+userID] = true
+			report.Members = append(report.Members, ImportMember{Team: team.Name, Username: username, UserID: userID, slug: teamKey})
+		}
+
+		if record.Title == "" {
+			continue
+		}
+		gamedayKey := fmt.Sprintf("%s/%s/%d", teamKey, strings.ToLower(record.Title), record.ScheduledAt.Unix())
+		if !gamedays[gamedayKey] && team.ID != "" {
+			existing, err := s.repo.ListGamedaysByTeam(team.ID)
+			if err != nil {
+				return report, errors.Wrap(err, "failed to get gamedays in repository")
+			}
+			for _, g := range existing {
+				gamedays[fmt.Sprintf("%s/%s/%d", teamKey, strings.ToLower(g.Title), g.ScheduledAt)] = true
+			}
+		}
+		if gamedays[gamedayKey] {
+			report.Skipped++
+			continue
+		}
+		gamedays[gamedayKey] = true
+		report.Gamedays = append(report.Gamedays, ImportGameday{Line: record.Line, Team: team.Name, Title: record.Title, ScheduledAt: record.ScheduledAt, slug: teamKey})
+	}
+
+	teamSizes := map[string]int{}
+	for key := range members {
+		teamSizes[strings.SplitN(key, "/", 2)[0]]++
+	}
+	for _, g := range report.Gamedays {
+		teamRoles := defaultTeamRoles
+		if team := teams[g.slug]; team.ID != "" {
+			if teamRoles, err = s.ListRoles(team.ID); err != nil {
+				return report, err
+			}
+		}
+		roles := countRoles(teamRoles)
+		if size := teamSizes[g.slug]; size < roles {
+			report.addError(g.Line, "team %s has %d member(s) but needs %d to nominate distinct roles", g.Team, size, roles)
+		}
+	}
+	if report.DryRun || len(report.Errors) > 0 {
+		return report, nil
+	}
+
+	// everything is imported or nothing, the gamedays are announced once saved
+	type importedGameday struct {
+		gameday  Gameday
+		nominees []GamedayNominee
+	}
+	var imported []importedGameday
+	err = s.inTransaction(func(tx *Service) error {
+		for _, m := range report.Members {
+			team := teams[m.slug]
+			if team.ID == "" {
+				if team, err = tx.ensureTeam(ctx, m.Team); err != nil {
+					return errors.Wrapf(err, "failed to import team %s", m.Team)
+				}
+			}
+			if err := tx.createMember(ctx, team.ID, m.UserID, m.Username); err != nil {
+				return errors.Wrapf(err, "failed to import member @%s of team %s", m.Username, m.Team)
+			}
+			teams[m.slug] = team
+		}
+		for _, g := range report.Gamedays {
+			team := teams[g.slug]
+			dto := GamedayDTO{
+				Name:        g.Title,
+				Team:        LookupDTO{Label: team.Name, Value: team.ID},
+				ScheduledAt: ScheduledAtTime(g.ScheduledAt),
+			}
+			gameday, nominees, err := tx.createGameday(ctx, dto)
+			if err != nil {
+				return errors.Wrapf(err, "failed to import gameday %s of team %s", g.Title, g.Team)
+			}
+			imported = append(imported, importedGameday{gameday: gameday, nominees: nominees})
+		}
+		return nil
+	})
 	if err != nil {
-		return []TeamMember{}, errors.Wrap(err, "failed to get teams in repository")
+		return report, err
+	}
+	for _, g := range imported {
+		if err := s.announceGameday(ctx, g.gameday, g.nominees); err != nil {
+			return report, errors.Wrapf(err, "failed to announce the imported gameday %s", g.gameday.Title)
+		}
 	}
-	return teams, nil
+	return report, nil
 }
 
-// CreateGameday responsible to create a gameday in database
-func (s *Service) CreateGameday(ctx *apps.Context, dto GamedayDTO) error {
-	gameday := Gameday{
-		Title:       dto.Name,
-		TeamID:      dto.Team.Value,
-		State:       GamedayScheduledState,
-		ScheduledAt: dto.ScheduledAt.Unix(),
+// NominationStats responsible to compute the nominations of the members
+// of the team and the fairness of the rotation
+func (s *Service) NominationStats(teamID string) (NominationStats, error) {
+	team, err := s.repo.GetTeamByID(teamID)
+	if err != nil {
+		return NominationStats{}, errors.Wrap(err, "failed to get team in repository")
+	}
+	if team == nil {
+		return NominationStats{}, errors.Errorf("team with ID: %s not found", teamID)
 	}
-	gamedayID, err := s.repo.CreateGameday(gameday)
+	members, err := s.repo.ListTeams(teamID)
 	if err != nil {
-		return errors.Wrap(err, "failed to create a gameday")
+		return NominationStats{}, errors.Wrap(err, "failed to fetch team members in repository")
 	}
-	members, err := s.repo.ListTeams(dto.Team.Value)
+	nominations, err := s.repo.ListTeamNominations(teamID)
 	if err != nil {
-		return errors.Wrap(err, "failed to fetch team members in repository")
+		return NominationStats{}, errors.Wrap(err, "failed to fetch the nominations of the team")
 	}
-	msg := fmt.Sprintf("Gameday: **%s** is scheduled for %s", strings.ToUpper(dto.Name), dto.ScheduledAt.String())
-	for _, m := range members {
-		mmclient.AsBot(ctx).DM(m.UserID, msg)
+	roles, err := s.ListRoles(teamID)
+	if err != nil {
+		return NominationStats{}, err
+	}
+	return computeNominationStats(*team, roles, membersAt(members, time.Now().UnixNano()/int64(time.Millisecond)), nominations, time.Now()), nil
+}
+
+// calendarUser returns the user of the calendar, empty for the calendar
+// of a team
+func calendarUser(ctx *apps.Context, teamID string) (string, error) {
+	if teamID != "" {
+		return "", nil
 	}
-	currentNominees, err := s.repo.ListGamedayNominees(gamedayID)
+	if ctx.ActingUserID == "" {
+		return "", errors.New("missing acting user")
+	}
+	return ctx.ActingUserID, nil
+}
+
+// CalendarLink responsible to return the URL of the iCalendar feed of the
+// team or of the acting user when the team isn't provided
+func (s *Service) CalendarLink(ctx *apps.Context, teamID string) (string, error) {
+	userID, err := calendarUser(ctx, teamID)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create calendar link")
+	}
+
+	var token string
+	calendarToken, err := s.repo.GetCalendarToken(teamID, userID)
 	if err != nil {
-		return errors.Wrapf(err, "failed to fetch current gameday nominees")
+		return "", errors.Wrap(err, "failed to get calendar token in repository")
 	}
-	filteredMembers := filterOutCurrentNominees(currentNominees, members)
-	mod := shuffleAndPickMember(filteredMembers)
-	if _, err := s.repo.CreateNominee(GamedayNominee{GamedayID: gamedayID, MemberID: mod.ID, IsMasterOfDisaster: true}); err != nil {
-		return errors.Wrapf(err, "failed to nominate a team member for MOD for GamedayID: %s and MemberID: %s", gamedayID, mod.ID)
+	if calendarToken != nil {
+		token = calendarToken.Token
+	} else if token, err = s.repo.CreateCalendarToken(teamID, userID); err != nil {
+		return "", errors.Wrap(err, "failed to create calendar token in repository")
 	}
-	mmclient.AsBot(ctx).DM(mod.UserID, fmt.Sprintf("You are the **Master of Disaster** for gameday: _**%s**_ scheduled at: _**%s**_", gameday.Title, dto.ScheduledAt.String()))
+	return fmt.Sprintf("%s/api/v1/calendar/%s.ics", s.rootURL, token), nil
+}
 
-	oncall := shuffleAndPickMember(filteredMembers)
-	if _, err := s.repo.CreateNominee(GamedayNominee{GamedayID: gamedayID, MemberID: oncall.ID, IsOnCall: true}); err != nil {
-		return errors.Wrapf(err, "failed to nominate a team member for OnCall for GamedayID: %s and MemberID: %s", gamedayID, mod.ID)
+// RevokeCalendarLink responsible to delete the token of the iCalendar feed
+// of the team or of the acting user, the previous link stops working
+func (s *Service) RevokeCalendarLink(ctx *apps.Context, teamID string) error {
+	userID, err := calendarUser(ctx, teamID)
+	if err != nil {
+		return errors.Wrap(err, "failed to revoke calendar link")
+	}
+	if err := s.repo.DeleteCalendarToken(teamID, userID); err != nil {
+		return errors.Wrap(err, "failed to delete calendar token in repository")
 	}
-	mmclient.AsBot(ctx).DM(oncall.UserID, fmt.Sprintf("You are **On-Call** for gameday: _**%s**_ scheduled at: _**%s**_", gameday.Title, dto.ScheduledAt.String()))
 	return nil
 }
 
-// UpdateGamedayState updates the state of a gameday accordingly
-// to the action
-func (s *Service) UpdateGamedayState(gamedayID string, state GamedayState) error {
-	return s.repo.UpdateGamedayState(gamedayID, state)
+// RotateCalendarLink responsible to replace the token of the iCalendar feed
+// of the team or of the acting user, returns the new link
+func (s *Service) RotateCalendarLink(ctx *apps.Context, teamID string) (string, error) {
+	var link string
+	err := s.inTransaction(func(tx *Service) error {
+		if err := tx.RevokeCalendarLink(ctx, teamID); err != nil {
+			return err
+		}
+		var err error
+		link, err = tx.CalendarLink(ctx, teamID)
+		return err
+	})
+	return link, err
+}
+
+// WriteCalendar writes the iCalendar feed which matches the token
+func (s *Service) WriteCalendar(w io.Writer, token string) error {
+	calendarToken, err := s.repo.GetCalendarTokenByToken(token)
+	if err != nil {
+		return errors.Wrap(err, "failed to get calendar token in repository")
+	}
+	if calendarToken == nil {
+		return ErrCalendarNotFound
+	}
+
+	if calendarToken.TeamID == "" {
+		gamedays, err := s.repo.ListGamedaysByUser(calendarToken.UserID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get gamedays in repository")
+		}
+		return writeCalendar(w, "Chaos Gamedays", gamedays, time.Now())
+	}
+
+	// the feed is named after the team even before its first gameday
+	team, err := s.repo.GetTeamByID(calendarToken.TeamID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get team in repository")
+	}
+	if team == nil {
+		return ErrCalendarNotFound
+	}
+	gamedays, err := s.repo.ListGamedaysByTeam(team.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get gamedays in repository")
+	}
+	return writeCalendar(w, fmt.Sprintf("Chaos Gamedays: %s", team.Name), gamedays, time.Now())
 }
 
 // ListGamedays responsible to list the scheduled and in progress gamedays
@@ -132,6 +1772,19 @@ func (s *Service) ListGamedays() ([]GamedayDTO, error) {
 	return results, nil
 }
 
+// ListArchivedGamedays responsible to list the completed and cancelled gamedays
+func (s *Service) ListArchivedGamedays() ([]GamedayDTO, error) {
+	gamedays, err := s.repo.ListGamedaysByState([]string{string(GamedayCompletedState), string(GamedayCancelledState)})
+	if err != nil {
+		return []GamedayDTO{}, errors.Wrap(err, "failed to get gamedays in repository")
+	}
+	var results []GamedayDTO
+	for _, g := range gamedays {
+		results = append(results, g.toGameDayDTO())
+	}
+	return results, nil
+}
+
 // LookupGamedays responsible to lookup the scheduled and in progress gamedays
 func (s *Service) LookupGamedays(state []string) ([]LookupDTO, error) {
 	gamedays, err := s.repo.ListGamedaysByState(state)
@@ -145,31 +1798,62 @@ func (s *Service) LookupGamedays(state []string) ([]LookupDTO, error) {
 	return results, nil
 }
 
-// filterOutCurrentNominees returns only the people who weren't of last gameday as any type of nominee
-func filterOutCurrentNominees(nominees []GamedayNominee, members []TeamMember) []TeamMember {
-	var filteredMembers []TeamMember
-	if len(nominees) == 0 {
-		return members
+// isMasterOfDisaster checks if the user is nominated as Master of Disaster
+func isMasterOfDisaster(nominees []GamedayNominee, userID string) bool {
+	for _, n := range nominees {
+		if n.UserID == userID && nominatedFor(n, MasterOfDisasterRole) {
+			return true
+		}
 	}
-	for _, m := range members {
-		for _, n := range nominees {
-			if m.ID == n.ID && (n.IsMasterOfDisaster || n.IsOnCall) {
-				continue
-			}
-			filteredMembers = append(filteredMembers, m)
+	return false
+}
+
+// selectNominee returns the nominee of the role, the user picks the
+// nominee when several members hold the role
+func selectNominee(gameday Gameday, nominees []GamedayNominee, role NomineeRole, userID string) (*GamedayNominee, error) {
+	var found []*GamedayNominee
+	for i, n := range nominees {
+		if nominatedFor(n, role) && (userID == "" || n.UserID == userID) {
+			found = append(found, &nominees[i])
 		}
 	}
-	return filteredMembers
+	switch {
+	case len(found) == 0 && userID != "":
+		return nil, errors.Errorf("the user isn't the %s of gameday %s", role, gameday.Title)
+	case len(found) == 0:
+		return nil, errors.Errorf("gameday %s has no nominee for the role %s", gameday.Title, role)
+	case len(found) > 1:
+		return nil, errors.Errorf("gameday %s has %d nominees for the role %s, pick one with `--member`", gameday.Title, len(found), role)
+	}
+	return found[0], nil
 }
 
-// shuffleAndPickMember shuffle the members and picks one randomly
-func shuffleAndPickMember(members []TeamMember) *TeamMember {
-	if len(members) == 0 {
-		return nil
+// countRoles returns the number of members the roles nominate
+func countRoles(roles []TeamRole) int {
+	var count int
+	for _, r := range roles {
+		count += r.Count
 	}
-	rand.Seed(time.Now().UnixNano())
-	rand.Shuffle(len(members), func(i, j int) {
-		members[i], members[j] = members[j], members[i]
-	})
-	return &members[0]
+	return count
+}
+
+// isNominated checks if the member is a nominee of the gameday
+func isNominated(nominees []GamedayNominee, memberID string) bool {
+	for _, n := range nominees {
+		if n.MemberID == memberID {
+			return true
+		}
+	}
+	return false
+}
+
+// getNomineeRole returns the label of the role of the nominee
+func getNomineeRole(nominee GamedayNominee) string {
+	if nominee.RoleLabel != "" {
+		return nominee.RoleLabel
+	}
+	if role := findTeamRole(defaultTeamRoles, nominee.Role); role != nil {
+		return role.Label
+	}
+	return string(nominee.Role)
 }
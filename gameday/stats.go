@@ -0,0 +1,150 @@
+package gameday
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-plugin-apps/utils/md"
+)
+
+// MemberStats the nominations of a member of the team, the upcoming
+// gamedays are counted apart from the ones the member served
+type MemberStats struct {
+	Member     TeamMember
+	Roles      map[NomineeRole]int
+	Gamedays   int
+	Upcoming   int
+	LastServed int64
+}
+
+// NominationStats the nominations of the members of a team with the
+// standard deviation of the counts of each role
+type NominationStats struct {
+	Team      Team
+	Roles     []TeamRole
+	Members   []MemberStats
+	Deviation map[NomineeRole]float64
+}
+
+// Fairness describes how skewed the rotation is from the highest
+// standard deviation of the role counts
+func (s NominationStats) Fairness() string {
+	var highest float64
+	for _, d := range s.Deviation {
+		highest = math.Max(highest, d)
+	}
+	switch {
+	case highest <= 0.5:
+		return "balanced"
+	case highest <= 1:
+		return "slightly skewed"
+	}
+	return "skewed"
+}
+
+// computeNominationStats counts the nominations of the members for the gamedays
+// which weren't cancelled, a member served when the gameday is completed or
+// scheduled by now and the later gamedays are only counted as upcoming
+func computeNominationStats(team Team, roles []TeamRole, members []TeamMember, nominations []GamedayNominee, now time.Time) NominationStats {
+	stats := NominationStats{Team: team, Roles: roles, Deviation: map[NomineeRole]float64{}}
+	byMember := map[string]*MemberStats{}
+	for _, m := range members {
+		stats.Members = append(stats.Members, MemberStats{Member: m, Roles: map[NomineeRole]int{}})
+	}
+	for i := range stats.Members {
+		byMember[stats.Members[i].Member.ID] = &stats.Members[i]
+	}
+
+	gamedays := map[string]map[string]bool{}
+	upcoming := map[string]map[string]bool{}
+	for _, n := range nominations {
+		member, ok := byMember[n.MemberID]
+		if !ok || n.Gameday.State == GamedayCancelledState {
+			continue
+		}
+		if n.Gameday.State != GamedayCompletedState && n.Gameday.ScheduledAt > now.Unix() {
+			if upcoming[n.MemberID] == nil {
+				upcoming[n.MemberID] = map[string]bool{}
+			}
+			upcoming[n.MemberID][n.GamedayID] = true
+			continue
+		}
+		member.Roles[n.Role]++
+		if gamedays[n.MemberID] == nil {
+			gamedays[n.MemberID] = map[string]bool{}
+		}
+		gamedays[n.MemberID][n.GamedayID] = true
+		if n.Gameday.ScheduledAt > member.LastServed {
+			member.LastServed = n.Gameday.ScheduledAt
+		}
+	}
+	for i := range stats.Members {
+		stats.Members[i].Gamedays = len(gamedays[stats.Members[i].Member.ID])
+		stats.Members[i].Upcoming = len(upcoming[stats.Members[i].Member.ID])
+	}
+
+	for _, role := range roles {
+		var counts []float64
+		for _, m := range stats.Members {
+			counts = append(counts, float64(m.Roles[role.Name]))
+		}
+		stats.Deviation[role.Name] = standardDeviation(counts)
+	}
+
+	sort.SliceStable(stats.Members, func(i, j int) bool {
+		return stats.Members[i].Gamedays > stats.Members[j].Gamedays
+	})
+	return stats
+}
+
+// standardDeviation the population standard deviation of the values
+func standardDeviation(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	var variance float64
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(variance / float64(len(values)))
+}
+
+// getNominationStatsMarkdown markdown leaderboard with the nominations of the members
+func getNominationStatsMarkdown(stats NominationStats) md.MD {
+	if len(stats.Members) == 0 {
+		return md.MD(fmt.Sprintf("Team %s doesn't have any members", stats.Team.Name))
+	}
+	txt := fmt.Sprintf("#### Nominations of team: %s\n", stats.Team.Name)
+	txt += "| Member |"
+	for _, role := range stats.Roles {
+		txt += fmt.Sprintf(" %s |", role.Label)
+	}
+	txt += " Gamedays | Upcoming | Last served |\n"
+	txt += "| :-- |" + strings.Repeat(" :-- |", len(stats.Roles)+3) + "\n"
+	for _, m := range stats.Members {
+		txt += fmt.Sprintf("| @%s |", m.Member.Label)
+		for _, role := range stats.Roles {
+			txt += fmt.Sprintf(" %d |", m.Roles[role.Name])
+		}
+		lastServed := "never"
+		if m.LastServed > 0 {
+			lastServed = time.Unix(m.LastServed, 0).Format(timeLayout)
+		}
+		txt += fmt.Sprintf(" %d | %d | %s |\n", m.Gamedays, m.Upcoming, lastServed)
+	}
+
+	var deviations []string
+	for _, role := range stats.Roles {
+		deviations = append(deviations, fmt.Sprintf("%s %.2f", role.Label, stats.Deviation[role.Name]))
+	}
+	txt += fmt.Sprintf("\n**Fairness:** %s (standard deviation: %s)\n", stats.Fairness(), strings.Join(deviations, ", "))
+	return md.MD(txt)
+}
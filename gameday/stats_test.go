@@ -0,0 +1,50 @@
+package gameday
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestComputeNominationStats(t *testing.T) {
+	members := []TeamMember{{ID: "alice", Label: "alice"}, {ID: "bob", Label: "bob"}, {ID: "carol", Label: "carol"}}
+	nominations := []GamedayNominee{
+		{MemberID: "alice", GamedayID: "g1", Role: MasterOfDisasterRole, Gameday: Gameday{ScheduledAt: 100, State: GamedayCompletedState}},
+		{MemberID: "bob", GamedayID: "g1", Role: OnCallRole, Gameday: Gameday{ScheduledAt: 100, State: GamedayCompletedState}},
+		{MemberID: "alice", GamedayID: "g2", Role: MasterOfDisasterRole, Gameday: Gameday{ScheduledAt: 200, State: GamedayCompletedState}},
+		{MemberID: "carol", GamedayID: "g2", Role: OnCallRole, Gameday: Gameday{ScheduledAt: 200, State: GamedayCompletedState}},
+		{MemberID: "bob", GamedayID: "g3", Role: MasterOfDisasterRole, Gameday: Gameday{ScheduledAt: 300, State: GamedayCancelledState}},
+		{MemberID: "alice", GamedayID: "g4", Role: MasterOfDisasterRole, Gameday: Gameday{ScheduledAt: 900, State: GamedayScheduledState}},
+		{MemberID: "gone", GamedayID: "g4", Role: OnCallRole, Gameday: Gameday{ScheduledAt: 900, State: GamedayScheduledState}},
+	}
+
+	stats := computeNominationStats(Team{Name: "sre"}, defaultTeamRoles, members, nominations, time.Unix(500, 0))
+	if len(stats.Members) != 3 {
+		t.Fatalf("wrong members: got %+v", stats.Members)
+	}
+	alice := stats.Members[0]
+	if alice.Member.ID != "alice" || alice.Roles[MasterOfDisasterRole] != 2 || alice.Gamedays != 2 || alice.Upcoming != 1 || alice.LastServed != 200 {
+		t.Errorf("wrong stats for alice: got %+v", alice)
+	}
+	for _, m := range stats.Members[1:] {
+		if m.Roles[MasterOfDisasterRole] != 0 || m.Roles[OnCallRole] != 1 || m.Gamedays != 1 || m.Upcoming != 0 {
+			t.Errorf("wrong stats for %s: got %+v", m.Member.ID, m)
+		}
+	}
+
+	// the upcoming gameday g4 isn't counted, mod counts 2, 0, 0 and oncall
+	// counts 0, 1, 1
+	if d := stats.Deviation[MasterOfDisasterRole]; math.Abs(d-2*math.Sqrt(2)/3) > 1e-9 {
+		t.Errorf("wrong mod deviation: got %f", d)
+	}
+	if d := stats.Deviation[OnCallRole]; math.Abs(d-math.Sqrt(2)/3) > 1e-9 {
+		t.Errorf("wrong oncall deviation: got %f", d)
+	}
+	if stats.Fairness() != "slightly skewed" {
+		t.Errorf("wrong fairness: got %s", stats.Fairness())
+	}
+
+	if got := computeNominationStats(Team{}, defaultTeamRoles, members, nil, time.Unix(500, 0)).Fairness(); got != "balanced" {
+		t.Errorf("wrong fairness without nominations: got %s", got)
+	}
+}
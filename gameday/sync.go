@@ -0,0 +1,294 @@
+package gameday
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/apps/mmclient"
+	"github.com/mattermost/mattermost-plugin-apps/utils/md"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// SyncSourceChannel the members of the team are the members of a channel
+	SyncSourceChannel = "channel"
+	// SyncSourceGroup the members of the team are the members of a user group
+	SyncSourceGroup = "group"
+	// SyncSourceTeam the members of the team are the members of a Mattermost team
+	SyncSourceTeam = "team"
+)
+
+// membershipSyncInterval how often the members of the teams are synced
+const membershipSyncInterval = time.Hour
+
+// syncPageSize the users fetched from Mattermost per request
+const syncPageSize = 200
+
+// SyncReport the members added to and removed from a team by a sync
+type SyncReport struct {
+	Team    Team
+	Added   []string
+	Removed []string
+	// Replacements how the nominations of the removed members were handled
+	Replacements []string
+}
+
+func (r SyncReport) hasChanges() bool {
+	return len(r.Added) > 0 || len(r.Removed) > 0
+}
+
+// getSyncMarkdown markdown for the changes of a sync
+func getSyncMarkdown(report SyncReport) md.MD {
+	if !report.hasChanges() {
+		return md.MD(fmt.Sprintf("The members of team **%s** are up to date", report.Team.Name))
+	}
+	txt := fmt.Sprintf("#### Synced team: %s\n", report.Team.Name)
+	if len(report.Added) > 0 {
+		txt += fmt.Sprintf("**Added:** @%s\n", strings.Join(report.Added, ", @"))
+	}
+	if len(report.Removed) > 0 {
+		txt += fmt.Sprintf("**Removed:** @%s\n", strings.Join(report.Removed, ", @"))
+	}
+	for _, line := range report.Replacements {
+		txt += fmt.Sprintf("- %s\n", line)
+	}
+	return md.MD(txt)
+}
+
+// diffMembership returns the users who aren't members of the team yet and
+// the members who aren't users of the source anymore, the deactivated
+// users and the bots are never members
+func diffMembership(members []TeamMember, users []*model.User) ([]*model.User, []TeamMember) {
+	isMember := make(map[string]bool)
+	for _, m := range members {
+		isMember[m.UserID] = true
+	}
+	active := make(map[string]bool)
+	var added []*model.User
+	for _, u := range users {
+		if u == nil || u.DeleteAt > 0 || u.IsBot || active[u.Id] {
+			continue
+		}
+		active[u.Id] = true
+		if !isMember[u.Id] {
+			added = append(added, u)
+		}
+	}
+	var removed []TeamMember
+	for _, m := range members {
+		if !active[m.UserID] {
+			removed = append(removed, m)
+		}
+	}
+	return added, removed
+}
+
+// LinkTeam responsible to link the team to the channel, group or Mattermost
+// team its members are synced from, the members are synced right away
+func (s *Service) LinkTeam(ctx *apps.Context, dto LinkTeamDTO) (SyncReport, error) {
+	team, err := s.getAdministeredTeam(ctx, dto.Team)
+	if err != nil {
+		return SyncReport{}, err
+	}
+	client := mmclient.AsBot(ctx)
+	switch {
+	case dto.Channel.Value != "":
+		channel, resp := client.GetChannel(dto.Channel.Value, "")
+		if resp.Error != nil {
+			return SyncReport{}, errors.Wrapf(resp.Error, "failed to get the channel %s, the bot must be a member of the channel", dto.Channel.Label)
+		}
+		team.SyncSource, team.SyncSourceID, team.SyncSourceName = SyncSourceChannel, channel.Id, "~"+channel.Name
+	case strings.TrimSpace(dto.Group) != "":
+		group, err := findGroup(client, strings.TrimPrefix(strings.TrimSpace(dto.Group), "@"))
+		if err != nil {
+			return SyncReport{}, err
+		}
+		team.SyncSource, team.SyncSourceID, team.SyncSourceName = SyncSourceGroup, group.Id, group.DisplayName
+	default:
+		mmTeam, resp := client.GetTeamByName(strings.ToLower(strings.TrimSpace(dto.MattermostTeam)), "")
+		if resp.Error != nil {
+			return SyncReport{}, errors.Wrapf(resp.Error, "failed to get the Mattermost team %s", dto.MattermostTeam)
+		}
+		team.SyncSource, team.SyncSourceID, team.SyncSourceName = SyncSourceTeam, mmTeam.Id, mmTeam.Name
+	}
+	if err := s.repo.UpdateTeamSync(team.ID, team.SyncSource, team.SyncSourceID, team.SyncSourceName); err != nil {
+		return SyncReport{}, errors.Wrap(err, "failed to link team in repository")
+	}
+	return s.syncTeam(ctx, *team)
+}
+
+// findGroup returns the user group with the name or the display name
+func findGroup(client *mmclient.Client, name string) (*model.Group, error) {
+	groups, resp := client.GetGroups(model.GroupSearchOpts{
+		Q:        name,
+		PageOpts: &model.PageOpts{Page: 0, PerPage: syncPageSize},
+	})
+	if resp.Error != nil {
+		return nil, errors.Wrapf(resp.Error, "failed to search the group %s", name)
+	}
+	for _, g := range groups {
+		if (g.Name != nil && strings.EqualFold(*g.Name, name)) || strings.EqualFold(g.DisplayName, name) {
+			return g, nil
+		}
+	}
+	return nil, errors.Errorf("group %s doesn't exist", name)
+}
+
+// UnlinkTeam responsible to stop syncing the members of the team, the
+// current members are kept
+func (s *Service) UnlinkTeam(ctx *apps.Context, dto UnlinkTeamDTO) (Team, error) {
+	team, err := s.getAdministeredTeam(ctx, dto.Team)
+	if err != nil {
+		return Team{}, err
+	}
+	if team.SyncSource == "" {
+		return Team{}, errors.Errorf("team %s isn't linked", team.Name)
+	}
+	if err := s.repo.UpdateTeamSync(team.ID, "", "", ""); err != nil {
+		return Team{}, errors.Wrap(err, "failed to unlink team in repository")
+	}
+	return *team, nil
+}
+
+// SyncTeam responsible to sync the members of the team on demand
+func (s *Service) SyncTeam(ctx *apps.Context, name string) (SyncReport, error) {
+	team, err := s.repo.GetTeam(strings.TrimSpace(name))
+	if err != nil {
+		return SyncReport{}, errors.Wrap(err, "failed to get a team in repository")
+	}
+	if team == nil {
+		return SyncReport{}, errors.Errorf("team %s doesn't exist", name)
+	}
+	if err := s.checkTeamAdmin(ctx, *team); err != nil {
+		return SyncReport{}, err
+	}
+	return s.syncTeam(ctx, *team)
+}
+
+// SyncTeams responsible to sync the members of every team, the owners of
+// the teams are notified about the changes
+func (s *Service) SyncTeams(ctx *apps.Context) error {
+	teams, err := s.repo.ListAllTeams()
+	if err != nil {
+		return errors.Wrap(err, "failed to get teams in repository")
+	}
+	var failures []string
+	for _, team := range teams {
+		report, err := s.syncTeam(ctx, team)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %s", team.Name, err))
+			continue
+		}
+		if report.hasChanges() && team.OwnerID != "" {
+			mmclient.AsBot(ctx).DM(team.OwnerID, "%s", getSyncMarkdown(report))
+		}
+	}
+	if len(failures) > 0 {
+		return errors.Errorf("failed to sync teams: %s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// syncTeam adds the users of the source missing from the team and removes
+// the members who left it, the teams which aren't linked only lose their
+// deactivated members
+func (s *Service) syncTeam(ctx *apps.Context, team Team) (SyncReport, error) {
+	members, err := s.repo.ListTeams(team.ID)
+	if err != nil {
+		return SyncReport{}, errors.Wrap(err, "failed to fetch team members in repository")
+	}
+	var users []*model.User
+	if team.SyncSource != "" {
+		users, err = getSourceUsers(mmclient.AsBot(ctx), team)
+		if err != nil {
+			return SyncReport{}, err
+		}
+	} else {
+		for _, m := range members {
+			user, resp := mmclient.AsBot(ctx).GetUser(m.UserID, "")
+			if resp.Error != nil || user == nil {
+				// a member is only removed when the user is known to be deactivated
+				user = &model.User{Id: m.UserID}
+			}
+			users = append(users, user)
+		}
+	}
+
+	report := SyncReport{Team: team}
+	added, removed := diffMembership(members, users)
+	for _, u := range added {
+		if err := s.createMember(ctx, team.ID, u.Id, u.Username); err != nil {
+			return report, errors.Wrap(err, "failed to create a member in repository")
+		}
+		report.Added = append(report.Added, u.Username)
+		mmclient.AsBot(ctx).DM(u.Id, fmt.Sprintf("You are added in Team: **%s**", strings.ToUpper(team.Name)))
+	}
+	// the members who left are removed together so that none of them
+	// replaces another
+	lines, err := s.removeMembers(ctx, team.Name, removed)
+	if err != nil {
+		return report, err
+	}
+	for _, m := range removed {
+		report.Removed = append(report.Removed, m.Label)
+	}
+	report.Replacements = lines
+	if err := s.repo.MarkTeamSynced(team.ID); err != nil {
+		return report, errors.Wrap(err, "failed to mark team synced in repository")
+	}
+	return report, nil
+}
+
+// getSourceUsers returns the users of the channel, group or Mattermost
+// team the team is linked to
+func getSourceUsers(client *mmclient.Client, team Team) ([]*model.User, error) {
+	var users []*model.User
+	for page := 0; ; page++ {
+		var batch []*model.User
+		var resp *model.Response
+		switch team.SyncSource {
+		case SyncSourceChannel:
+			batch, resp = client.GetUsersInChannel(team.SyncSourceID, page, syncPageSize, "")
+		case SyncSourceGroup:
+			batch, resp = client.GetUsersInGroup(team.SyncSourceID, page, syncPageSize, "")
+		case SyncSourceTeam:
+			batch, resp = client.GetUsersInTeam(team.SyncSourceID, page, syncPageSize, "")
+		default:
+			return nil, errors.Errorf("unknown sync source %s of team %s", team.SyncSource, team.Name)
+		}
+		if resp.Error != nil {
+			return nil, errors.Wrapf(resp.Error, "failed to get the users of the %s %s", team.SyncSource, team.SyncSourceName)
+		}
+		users = append(users, batch...)
+		if len(batch) < syncPageSize {
+			return users, nil
+		}
+	}
+}
+
+// RunMembershipSyncScheduler syncs the members of the teams periodically
+// until the context is done
+func RunMembershipSyncScheduler(ctx context.Context, rt *Runtime, logger logrus.FieldLogger) error {
+	ticker := time.NewTicker(membershipSyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			svc, botCtx := rt.scheduled()
+			if svc == nil {
+				logger.Debug("skipping the membership sync until the app is configured and receives a call")
+				continue
+			}
+			if err := svc.SyncTeams(botCtx); err != nil {
+				logger.WithError(err).Error("failed to sync the members of the teams")
+			}
+		}
+	}
+}
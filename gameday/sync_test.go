@@ -0,0 +1,90 @@
+package gameday
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-server/v5/model"
+)
+
+func TestDiffMembership(t *testing.T) {
+	members := []TeamMember{
+		{ID: "m1", UserID: "alice", Label: "alice"},
+		{ID: "m2", UserID: "bob", Label: "bob"},
+		{ID: "m3", UserID: "carol", Label: "carol"},
+	}
+	users := []*model.User{
+		{Id: "alice", Username: "alice"},
+		{Id: "carol", Username: "carol", DeleteAt: 100},
+		{Id: "dave", Username: "dave"},
+		{Id: "dave", Username: "dave"},
+		{Id: "bot", Username: "bot", IsBot: true},
+	}
+
+	added, removed := diffMembership(members, users)
+	if len(added) != 1 || added[0].Id != "dave" {
+		t.Errorf("wrong added users: got %v want [dave]", added)
+	}
+	if len(removed) != 2 || removed[0].UserID != "bob" || removed[1].UserID != "carol" {
+		t.Errorf("wrong removed members: got %v want [bob carol]", removed)
+	}
+}
+
+func TestDiffMembershipUpToDate(t *testing.T) {
+	members := []TeamMember{{ID: "m1", UserID: "alice", Label: "alice"}}
+	added, removed := diffMembership(members, []*model.User{{Id: "alice", Username: "alice"}})
+	if len(added) != 0 || len(removed) != 0 {
+		t.Errorf("expected no changes, got added %v removed %v", added, removed)
+	}
+}
+
+func TestSyncTeamKeepsTheRemovedMembersOutOfTheReplacements(t *testing.T) {
+	svc := newTestService(t)
+	deactivated := map[string]bool{"alice": true, "bob": true}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/api/v4/users/")
+		if r.Method == http.MethodGet && deactivated[id] {
+			_ = json.NewEncoder(w).Encode(model.User{Id: id, Username: id, DeleteAt: 100})
+			return
+		}
+		mattermostHandler()(w, r)
+	}))
+	t.Cleanup(server.Close)
+	teamID, gamedayID := newTestTeam(t, svc, "alice", "bob", "carol")
+	// bob served the role less recently than carol
+	carol, err := svc.repo.GetMember(teamID, "carol")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.repo.CreateGamedayWithNominees(Gameday{
+		Title:       "Database failover",
+		TeamID:      teamID,
+		ScheduledAt: time.Now().Add(-24 * time.Hour).Unix(),
+		State:       GamedayCompletedState,
+	}, []GamedayNominee{{MemberID: carol.ID, Role: OnCallRole}}); err != nil {
+		t.Fatal(err)
+	}
+	team, err := svc.repo.GetTeamByID(teamID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := &apps.Context{MattermostSiteURL: server.URL}
+	ctx.BotAccessToken = "bot-token"
+	report, err := svc.syncTeam(ctx, *team)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Removed) != 2 {
+		t.Errorf("expected alice and bob to be removed, got %v", report.Removed)
+	}
+	_, nominees, err := svc.GetGameday(gamedayID)
+	if err != nil || len(nominees) != 1 || nominees[0].UserID != "carol" {
+		t.Errorf("expected carol to replace alice, got %v (%v)", nominees, err)
+	}
+}
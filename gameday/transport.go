@@ -1,11 +1,15 @@
 package gameday
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"math/rand"
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/mattermost/mattermost-app-chaosengine/config"
@@ -20,18 +24,91 @@ import (
 func AddRoutes(router *mux.Router, svc *Service, logger logrus.FieldLogger) {
 	router.HandleFunc("/api/v1/teams/create/submit", handleCreateTeam(svc, logger))
 	router.HandleFunc("/api/v1/teams/list/submit", handleGetTeams(svc, logger))
-	router.HandleFunc("/api/v1/gamedays/create/lookup", handleGamedayLookupTeams(svc, logger))
+	router.HandleFunc("/api/v1/teams/configure/submit", handleConfigureTeam(svc, logger))
+	router.HandleFunc("/api/v1/teams/configure/lookup", handleGamedayLookupTeams(svc, logger))
+	router.HandleFunc("/api/v1/teams/members/add/submit", handleAddMembers(svc, logger))
+	router.HandleFunc("/api/v1/teams/members/add/lookup", handleGamedayLookupTeams(svc, logger))
+	router.HandleFunc("/api/v1/teams/members/set/submit", handleSetMemberAttributes(svc, logger))
+	router.HandleFunc("/api/v1/teams/members/set/lookup", handleGamedayLookupTeams(svc, logger))
+	router.HandleFunc("/api/v1/teams/members/remove/submit", handleRemoveMember(svc, logger))
+	router.HandleFunc("/api/v1/teams/members/remove/lookup", handleGamedayLookupTeams(svc, logger))
+	router.HandleFunc("/api/v1/teams/rename/submit", handleRenameTeam(svc, logger))
+	router.HandleFunc("/api/v1/teams/rename/lookup", handleGamedayLookupTeams(svc, logger))
+	router.HandleFunc("/api/v1/teams/delete/submit", handleDeleteTeam(svc, logger))
+	router.HandleFunc("/api/v1/teams/delete/lookup", handleGamedayLookupTeams(svc, logger))
+	router.HandleFunc("/api/v1/teams/show/submit", handleShowTeam(svc, logger))
+	router.HandleFunc("/api/v1/teams/link/submit", handleLinkTeam(svc, logger))
+	router.HandleFunc("/api/v1/teams/link/lookup", handleGamedayLookupTeams(svc, logger))
+	router.HandleFunc("/api/v1/teams/unlink/submit", handleUnlinkTeam(svc, logger))
+	router.HandleFunc("/api/v1/teams/unlink/lookup", handleGamedayLookupTeams(svc, logger))
+	router.HandleFunc("/api/v1/teams/sync/submit", handleSyncTeam(svc, logger))
+	router.HandleFunc("/api/v1/teams/admins/add/submit", handleAddTeamAdmin(svc, logger))
+	router.HandleFunc("/api/v1/teams/admins/add/lookup", handleGamedayLookupTeams(svc, logger))
+	router.HandleFunc("/api/v1/teams/admins/remove/submit", handleRemoveTeamAdmin(svc, logger))
+	router.HandleFunc("/api/v1/teams/admins/remove/lookup", handleGamedayLookupTeams(svc, logger))
+	router.HandleFunc("/api/v1/teams/roles/add/submit", handleAddRole(svc, logger))
+	router.HandleFunc("/api/v1/teams/roles/add/lookup", handleGamedayLookupTeams(svc, logger))
+	router.HandleFunc("/api/v1/teams/roles/list/submit", handleListRoles(svc, logger))
+	router.HandleFunc("/api/v1/teams/roles/list/lookup", handleGamedayLookupTeams(svc, logger))
+	router.HandleFunc("/api/v1/teams/roles/remove/submit", handleRemoveRole(svc, logger))
+	router.HandleFunc("/api/v1/teams/roles/remove/lookup", handleGamedayLookupTeams(svc, logger))
+	router.HandleFunc("/api/v1/teams/exemptions/add/submit", handleAddExemption(svc, logger))
+	router.HandleFunc("/api/v1/teams/exemptions/add/lookup", handleGamedayLookupTeams(svc, logger))
+	router.HandleFunc("/api/v1/teams/exemptions/list/submit", handleListExemptions(svc, logger))
+	router.HandleFunc("/api/v1/teams/exemptions/list/lookup", handleGamedayLookupTeams(svc, logger))
+	router.HandleFunc("/api/v1/teams/exemptions/remove/submit", handleRemoveExemption(svc, logger))
+	router.HandleFunc("/api/v1/teams/exemptions/remove/lookup", handleGamedayLookupTeams(svc, logger))
+	router.HandleFunc("/api/v1/gamedays/create/lookup", handleGamedayLookup(svc, logger))
 	router.HandleFunc("/api/v1/gamedays/create/submit", handleCreateGameday(svc, logger))
 	router.HandleFunc("/api/v1/gamedays/list/submit", handleListGameDays(svc, logger))
+	router.HandleFunc("/api/v1/gamedays/archive/submit", handleArchiveGameDays(svc, logger))
+	router.HandleFunc("/api/v1/gamedays/clone/submit", handleCloneGameday(svc, logger))
+	router.HandleFunc("/api/v1/gamedays/clone/lookup", handleLookupGamedays(svc, logger))
 	router.HandleFunc("/api/v1/gamedays/start/submit", handleStartGameDay(svc, logger))
 	router.HandleFunc("/api/v1/gamedays/start/lookup", handleLookupGamedays(svc, logger))
 	router.HandleFunc("/api/v1/gamedays/complete/submit", handleCompleteGameDay(svc, logger))
 	router.HandleFunc("/api/v1/gamedays/complete/lookup", handleLookupGamedays(svc, logger))
 	router.HandleFunc("/api/v1/gamedays/cancel/submit", handleCancelGameDay(svc, logger))
 	router.HandleFunc("/api/v1/gamedays/cancel/lookup", handleLookupGamedays(svc, logger))
+	router.HandleFunc("/api/v1/gamedays/show/submit", handleShowGameDay(svc, logger))
+	router.HandleFunc("/api/v1/gamedays/show/lookup", handleLookupGamedays(svc, logger))
+	router.HandleFunc("/api/v1/gamedays/rsvp/submit", handleRSVPGameDay(svc, logger))
+	router.HandleFunc("/api/v1/gamedays/acknowledge/submit", handleAcknowledge(svc, logger))
+	router.HandleFunc("/api/v1/gamedays/attendance/submit", handleAttendanceGameDay(svc, logger))
+	router.HandleFunc("/api/v1/gamedays/attendance/lookup", handleLookupGamedays(svc, logger))
+	router.HandleFunc("/api/v1/gamedays/reschedule/submit", handleReschedule(svc, logger))
+	router.HandleFunc("/api/v1/gamedays/reschedule/lookup", handleLookupGamedays(svc, logger))
+	router.HandleFunc("/api/v1/away/submit", handleAway(svc, logger))
+	router.HandleFunc("/api/v1/profile/submit", handleProfile(svc, logger))
+	router.HandleFunc("/api/v1/gamedays/renominate/submit", handleRenominate(svc, logger))
+	router.HandleFunc("/api/v1/gamedays/renominate/lookup", handleLookupNomineeRoles(svc, logger))
+	router.HandleFunc("/api/v1/gamedays/swap/submit", handleSwapNominee(svc, logger))
+	router.HandleFunc("/api/v1/gamedays/swap/lookup", handleLookupNomineeRoles(svc, logger))
+	router.HandleFunc("/api/v1/gamedays/nominees/verify/submit", handleVerifyNominees(svc, logger))
+	router.HandleFunc("/api/v1/gamedays/nominees/verify/lookup", handleLookupGamedays(svc, logger))
+	router.HandleFunc("/api/v1/subscriptions/create/submit", handleSubscribe(svc, logger))
+	router.HandleFunc("/api/v1/subscriptions/create/lookup", handleGamedayLookupTeams(svc, logger))
+	router.HandleFunc("/api/v1/subscriptions/delete/submit", handleUnsubscribe(svc, logger))
+	router.HandleFunc("/api/v1/subscriptions/delete/lookup", handleGamedayLookupTeams(svc, logger))
+	router.HandleFunc("/api/v1/subscriptions/list/submit", handleListSubscriptions(svc, logger))
+	router.HandleFunc("/api/v1/templates/create/submit", handleCreateTemplate(svc, logger))
+	router.HandleFunc("/api/v1/templates/create/lookup", handleGamedayLookupTeams(svc, logger))
+	router.HandleFunc("/api/v1/templates/edit/submit", handleEditTemplate(svc, logger))
+	router.HandleFunc("/api/v1/templates/edit/lookup", handleGamedayLookupTeams(svc, logger))
+	router.HandleFunc("/api/v1/templates/list/submit", handleListTemplates(svc, logger))
+	router.HandleFunc("/api/v1/stats/nominations/submit", handleNominationStats(svc, logger))
+	router.HandleFunc("/api/v1/stats/nominations/lookup", handleGamedayLookupTeams(svc, logger))
+	router.HandleFunc("/api/v1/import/submit", handleImport(svc, logger))
+	router.HandleFunc("/api/v1/calendar/link/submit", handleCalendarLink(svc, logger))
+	router.HandleFunc("/api/v1/calendar/link/lookup", handleGamedayLookupTeams(svc, logger))
+	router.HandleFunc("/api/v1/calendar/rotate/submit", handleCalendarRotate(svc, logger))
+	router.HandleFunc("/api/v1/calendar/rotate/lookup", handleGamedayLookupTeams(svc, logger))
+	router.HandleFunc("/api/v1/calendar/revoke/submit", handleCalendarRevoke(svc, logger))
+	router.HandleFunc("/api/v1/calendar/revoke/lookup", handleGamedayLookupTeams(svc, logger))
+	router.HandleFunc("/api/v1/calendar/{token:[a-z0-9]+}.ics", handleCalendarFeed(svc, logger)).Methods(http.MethodGet).Name(calendarFeedRoute)
 }
 
-func HandleConfigure(router *mux.Router, logger logrus.FieldLogger) http.HandlerFunc {
+func HandleConfigure(router *mux.Router, rt *Runtime, logger logrus.FieldLogger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		call, err := apps.CallRequestFromJSONReader(r.Body)
 		if err != nil {
@@ -56,6 +133,15 @@ func HandleConfigure(router *mux.Router, logger logrus.FieldLogger) http.Handler
 			transport.WriteBadRequestError(w, err)
 			return
 		}
+		if err := AuthorizeSystemAdmin(call.Context); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		// the routes and their service are only added once
+		if rt.Service() != nil {
+			transport.WriteBadRequestError(w, fmt.Errorf("the app is already configured"))
+			return
+		}
 
 		cfg, error := config.SetDatabaseConfig(dto.Scheme, dto.Url, logger)
 		if error != nil {
@@ -79,8 +165,9 @@ func HandleConfigure(router *mux.Router, logger logrus.FieldLogger) http.Handler
 		}
 
 		gamedayRepo := NewRepository(store)
-		gamedaySvc := NewService(gamedayRepo)
+		gamedaySvc := NewService(gamedayRepo, cfg.App.RootURL, rand.NewSource(time.Now().UnixNano()), logger)
 		AddRoutes(router, gamedaySvc, logger)
+		rt.SetService(gamedaySvc)
 
 		msg := fmt.Sprintf("App Configured with Driver: **%s**", strings.ToUpper(dto.Scheme))
 		mmclient.AsBot(call.Context).DM(dto.Scheme, msg)
@@ -145,81 +232,121 @@ func handleCreateTeam(svc *Service, logger logrus.FieldLogger) http.HandlerFunc
 			transport.WriteBadRequestError(w, err)
 			return
 		}
+		if err := AuthorizeSystemAdmin(call.Context); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
 
-		members, err := svc.CreateTeam(dto)
+		report, err := svc.CreateTeam(call.Context, dto)
 		if err != nil {
 			logger.WithError(err).Error("failed to create team")
 			transport.WriteBadRequestError(w, err)
 			return
 		}
-		msg := fmt.Sprintf("You are added in Team: **%s**", strings.ToUpper(dto.Name))
-		mmclient.AsBot(call.Context).DM(dto.Member.UserID, msg)
 
 		transport.WriteJSON(w, apps.CallResponse{
 			Type:     apps.CallResponseTypeOK,
-			Markdown: getMarkdown(members),
+			Markdown: getMembershipMarkdown(report, true),
 		})
 	}
 }
 
-func handleGetTeams(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+func handleAddMembers(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		teams, err := svc.GetTeams()
+		call, err := apps.CallRequestFromJSONReader(r.Body)
 		if err != nil {
-			logger.WithError(err).Error("failed to get teams")
 			transport.WriteBadRequestError(w, err)
 			return
 		}
-
+		jsonString, err := json.Marshal(call.Values)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		var dto AddMembersDTO
+		if err := json.Unmarshal(jsonString, &dto); err != nil {
+			logger.WithError(err).Error("failed to unmarshal json")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := dto.Validate(); err != nil {
+			logger.WithError(err).Error("failed to validate request")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.Authorize(call.Context, PermissionTeamAdmin, dto.Team.Value); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		report, err := svc.AddMembers(call.Context, dto)
+		if err != nil {
+			logger.WithField("team", dto.Team.Value).WithError(err).Error("failed to add team members")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
 		transport.WriteJSON(w, apps.CallResponse{
 			Type:     apps.CallResponseTypeOK,
-			Markdown: getMarkdown(teams),
+			Markdown: getMembershipMarkdown(report, false),
 		})
 	}
 }
 
-func handleGamedayLookupTeams(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+func handleConfigureTeam(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		call, err := apps.CallRequestFromJSONReader(r.Body)
 		if err != nil {
 			transport.WriteBadRequestError(w, err)
 			return
 		}
-		if call.SelectedField != "team" {
-			transport.WriteBadRequestError(w, fmt.Errorf("unexpected lookup field: %s", call.SelectedField))
+		jsonString, err := json.Marshal(call.Values)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
 			return
 		}
-
-		teams, err := svc.LookupTeams()
+		var dto ConfigureTeamDTO
+		if err := json.Unmarshal(jsonString, &dto); err != nil {
+			logger.WithError(err).Error("failed to unmarshal json")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := dto.Validate(); err != nil {
+			logger.WithError(err).Error("failed to validate request")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.Authorize(call.Context, PermissionTeamAdmin, dto.Team.Value); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		team, err := svc.ConfigureTeam(dto)
 		if err != nil {
+			logger.WithField("team", dto.Team.Value).WithError(err).Error("failed to configure team")
 			transport.WriteBadRequestError(w, err)
 			return
 		}
 		transport.WriteJSON(w, apps.CallResponse{
 			Type: apps.CallResponseTypeOK,
-			Data: map[string]interface{}{
-				"items": teams,
-			},
+			Markdown: md.MD(fmt.Sprintf("Team **%s** nominates with the **%s** strategy, the nominees are reminded %d hour(s) after their nomination and escalated to **%s** %d hour(s) before the gameday",
+				dto.Team.Label, getNominationStrategy(team.NominationStrategy).Name(), team.AckRemindAfter, team.AckEscalation, team.AckEscalateBefore)),
 		})
 	}
 }
 
-func handleCreateGameday(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+func handleRemoveMember(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		call, err := apps.CallRequestFromJSONReader(r.Body)
 		if err != nil {
 			transport.WriteBadRequestError(w, err)
 			return
 		}
-
 		jsonString, err := json.Marshal(call.Values)
 		if err != nil {
-			logger.WithError(err).Error("failed to unmarshal create gameday request")
 			transport.WriteBadRequestError(w, err)
 			return
 		}
-		var dto GamedayDTO
+		var dto TeamMemberDTO
 		if err := json.Unmarshal(jsonString, &dto); err != nil {
+			logger.WithError(err).Error("failed to unmarshal json")
 			transport.WriteBadRequestError(w, err)
 			return
 		}
@@ -228,143 +355,1913 @@ func handleCreateGameday(svc *Service, logger logrus.FieldLogger) http.HandlerFu
 			transport.WriteBadRequestError(w, err)
 			return
 		}
-		if err := svc.CreateGameday(call.Context, dto); err != nil {
-			logger.WithError(err).Error("failed to create gameday")
+		if err := svc.Authorize(call.Context, PermissionTeamAdmin, dto.Team.Value); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		report, err := svc.RemoveMember(call.Context, dto)
+		if err != nil {
+			logger.WithField("team", dto.Team.Value).WithError(err).Error("failed to remove team member")
 			transport.WriteBadRequestError(w, err)
 			return
 		}
-
+		msg := fmt.Sprintf("@%s was removed from team **%s**\n", dto.Member.Label, dto.Team.Label)
+		for _, line := range report {
+			msg += fmt.Sprintf("- %s\n", line)
+		}
 		transport.WriteJSON(w, apps.CallResponse{
 			Type:     apps.CallResponseTypeOK,
-			Markdown: md.MD(fmt.Sprintf("Gameday **%s** scheduled succesfully", dto.Name)),
+			Markdown: md.MD(msg),
 		})
 	}
 }
 
-func handleListGameDays(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+func handleRenameTeam(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		gamedays, err := svc.ListGamedays()
+		call, err := apps.CallRequestFromJSONReader(r.Body)
 		if err != nil {
-			logger.WithError(err).Error("failed to list gamedays")
 			transport.WriteBadRequestError(w, err)
 			return
 		}
-
+		jsonString, err := json.Marshal(call.Values)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		var dto RenameTeamDTO
+		if err := json.Unmarshal(jsonString, &dto); err != nil {
+			logger.WithError(err).Error("failed to unmarshal json")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := dto.Validate(); err != nil {
+			logger.WithError(err).Error("failed to validate request")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.Authorize(call.Context, PermissionTeamAdmin, dto.Team.Value); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		team, err := svc.RenameTeam(call.Context, dto)
+		if err != nil {
+			logger.WithField("team", dto.Team.Value).WithError(err).Error("failed to rename team")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
 		transport.WriteJSON(w, apps.CallResponse{
 			Type:     apps.CallResponseTypeOK,
-			Markdown: getGameDaysMarkdown(gamedays),
+			Markdown: md.MD(fmt.Sprintf("Team **%s** was renamed to **%s**", dto.Team.Label, team.Name)),
 		})
 	}
 }
 
-func handleLookupGamedays(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+func handleDeleteTeam(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		call, err := apps.CallRequestFromJSONReader(r.Body)
 		if err != nil {
 			transport.WriteBadRequestError(w, err)
 			return
 		}
-		if call.SelectedField != "id" {
-			transport.WriteBadRequestError(w, fmt.Errorf("unexpected lookup field: %s", call.SelectedField))
+		jsonString, err := json.Marshal(call.Values)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		var dto DeleteTeamDTO
+		if err := json.Unmarshal(jsonString, &dto); err != nil {
+			logger.WithError(err).Error("failed to unmarshal json")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := dto.Validate(); err != nil {
+			logger.WithError(err).Error("failed to validate request")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.Authorize(call.Context, PermissionTeamAdmin, dto.Team.Value); err != nil {
+			writeAuthorizationError(w, err)
 			return
 		}
+		if err := svc.DeleteTeam(call.Context, dto); err != nil {
+			logger.WithField("team", dto.Team.Value).WithError(err).Error("failed to delete team")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		transport.WriteJSON(w, apps.CallResponse{
+			Type:     apps.CallResponseTypeOK,
+			Markdown: md.MD(fmt.Sprintf("Team **%s** was deleted", dto.Team.Label)),
+		})
+	}
+}
 
-		var states []string
-		if strings.Contains(call.Path, "start") {
-			states = append(states, string(GamedayScheduledState))
-		} else if strings.Contains(call.Path, "complete") {
-			states = append(states, string(GamedayInProgressState))
-		} else {
-			states = append(states, string(GamedayScheduledState), string(GamedayInProgressState))
+func handleShowTeam(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		call, err := apps.CallRequestFromJSONReader(r.Body)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
 		}
-		gamedays, err := svc.LookupGamedays(states)
+		jsonString, err := json.Marshal(call.Values)
 		if err != nil {
-			logger.WithField("states", states).WithError(err).Error("failed to lookup gamedays by state")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		var dto ShowTeamDTO
+		if err := json.Unmarshal(jsonString, &dto); err != nil {
+			logger.WithError(err).Error("failed to unmarshal json")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := dto.Validate(); err != nil {
+			logger.WithError(err).Error("failed to validate request")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.Authorize(call.Context, PermissionUser, ""); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		team, members, roles, err := svc.ShowTeam(dto.Name)
+		if err != nil {
+			logger.WithField("name", dto.Name).WithError(err).Error("failed to show team")
 			transport.WriteBadRequestError(w, err)
 			return
 		}
 		transport.WriteJSON(w, apps.CallResponse{
-			Type: apps.CallResponseTypeOK,
-			Data: map[string]interface{}{
-				"items": gamedays,
-			},
+			Type:     apps.CallResponseTypeOK,
+			Markdown: getTeamMarkdown(team, members, roles),
 		})
 	}
 }
 
-func handleStartGameDay(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+func handleLinkTeam(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		dto, err := parseUpdateGamedayStateDto(r)
+		call, err := apps.CallRequestFromJSONReader(r.Body)
 		if err != nil {
-			logger.WithError(err).Error("failed to parse gameday state")
 			transport.WriteBadRequestError(w, err)
 			return
 		}
-		if err := svc.UpdateGamedayState(dto.Value, GamedayInProgressState); err != nil {
-			logger.WithField("ID", dto.Value).WithError(err).Error("failed to start the gameday")
+		jsonString, err := json.Marshal(call.Values)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		var dto LinkTeamDTO
+		if err := json.Unmarshal(jsonString, &dto); err != nil {
+			logger.WithError(err).Error("failed to unmarshal json")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := dto.Validate(); err != nil {
+			logger.WithError(err).Error("failed to validate request")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.Authorize(call.Context, PermissionTeamAdmin, dto.Team.Value); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		report, err := svc.LinkTeam(call.Context, dto)
+		if err != nil {
+			logger.WithField("team", dto.Team.Value).WithError(err).Error("failed to link team")
 			transport.WriteBadRequestError(w, err)
 			return
 		}
 		transport.WriteJSON(w, apps.CallResponse{
 			Type:     apps.CallResponseTypeOK,
-			Markdown: md.MD("Gameday just started"),
+			Markdown: md.MD(fmt.Sprintf("Team **%s** is synced from the %s %s\n\n", report.Team.Name, report.Team.SyncSource, report.Team.SyncSourceName)) + getSyncMarkdown(report),
 		})
 	}
 }
 
-func handleCompleteGameDay(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+func handleUnlinkTeam(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		dto, err := parseUpdateGamedayStateDto(r)
+		call, err := apps.CallRequestFromJSONReader(r.Body)
 		if err != nil {
-			logger.WithError(err).Error("failed to parse gameday state")
 			transport.WriteBadRequestError(w, err)
 			return
 		}
-		if err := svc.UpdateGamedayState(dto.Value, GamedayCompletedState); err != nil {
-			logger.WithField("ID", dto.Value).WithError(err).Error("failed to complete the gameday")
+		jsonString, err := json.Marshal(call.Values)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		var dto UnlinkTeamDTO
+		if err := json.Unmarshal(jsonString, &dto); err != nil {
+			logger.WithError(err).Error("failed to unmarshal json")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := dto.Validate(); err != nil {
+			logger.WithError(err).Error("failed to validate request")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.Authorize(call.Context, PermissionTeamAdmin, dto.Team.Value); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		team, err := svc.UnlinkTeam(call.Context, dto)
+		if err != nil {
+			logger.WithField("team", dto.Team.Value).WithError(err).Error("failed to unlink team")
 			transport.WriteBadRequestError(w, err)
 			return
 		}
 		transport.WriteJSON(w, apps.CallResponse{
 			Type:     apps.CallResponseTypeOK,
-			Markdown: md.MD("Gameday just completed"),
+			Markdown: md.MD(fmt.Sprintf("Team **%s** isn't synced from the %s %s anymore", team.Name, team.SyncSource, team.SyncSourceName)),
 		})
 	}
 }
-func handleCancelGameDay(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+
+func handleSyncTeam(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		dto, err := parseUpdateGamedayStateDto(r)
+		call, err := apps.CallRequestFromJSONReader(r.Body)
 		if err != nil {
-			logger.WithError(err).Error("failed to parse gameday state")
 			transport.WriteBadRequestError(w, err)
 			return
 		}
-		if err := svc.UpdateGamedayState(dto.Value, GamedayCancelledState); err != nil {
-			logger.WithField("ID", dto.Value).WithError(err).Error("failed to cancel the gameday")
+		jsonString, err := json.Marshal(call.Values)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		var dto SyncTeamDTO
+		if err := json.Unmarshal(jsonString, &dto); err != nil {
+			logger.WithError(err).Error("failed to unmarshal json")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := dto.Validate(); err != nil {
+			logger.WithError(err).Error("failed to validate request")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.AuthorizeTeamName(call.Context, PermissionTeamAdmin, dto.Name); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		report, err := svc.SyncTeam(call.Context, dto.Name)
+		if err != nil {
+			logger.WithField("name", dto.Name).WithError(err).Error("failed to sync team")
 			transport.WriteBadRequestError(w, err)
 			return
 		}
 		transport.WriteJSON(w, apps.CallResponse{
 			Type:     apps.CallResponseTypeOK,
-			Markdown: md.MD("Gameday just cancelled"),
+			Markdown: getSyncMarkdown(report),
 		})
 	}
 }
 
-func parseUpdateGamedayStateDto(r *http.Request) (LookupDTO, error) {
-	call, err := apps.CallRequestFromJSONReader(r.Body)
-	if err != nil {
-		return LookupDTO{}, err
+func handleAddTeamAdmin(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		call, err := apps.CallRequestFromJSONReader(r.Body)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		jsonString, err := json.Marshal(call.Values)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		var dto TeamMemberDTO
+		if err := json.Unmarshal(jsonString, &dto); err != nil {
+			logger.WithError(err).Error("failed to unmarshal json")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := dto.Validate(); err != nil {
+			logger.WithError(err).Error("failed to validate request")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.Authorize(call.Context, PermissionTeamAdmin, dto.Team.Value); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		team, err := svc.AddTeamAdmin(call.Context, dto)
+		if err != nil {
+			logger.WithField("team", dto.Team.Value).WithError(err).Error("failed to add team admin")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		transport.WriteJSON(w, apps.CallResponse{
+			Type:     apps.CallResponseTypeOK,
+			Markdown: md.MD(fmt.Sprintf("@%s is an admin of team **%s**", dto.Member.Label, team.Name)),
+		})
 	}
+}
 
-	jsonString, err := json.Marshal(call.Values)
-	if err != nil {
-		return LookupDTO{}, err
+func handleRemoveTeamAdmin(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		call, err := apps.CallRequestFromJSONReader(r.Body)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		jsonString, err := json.Marshal(call.Values)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		var dto TeamMemberDTO
+		if err := json.Unmarshal(jsonString, &dto); err != nil {
+			logger.WithError(err).Error("failed to unmarshal json")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := dto.Validate(); err != nil {
+			logger.WithError(err).Error("failed to validate request")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.Authorize(call.Context, PermissionTeamAdmin, dto.Team.Value); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		team, err := svc.RemoveTeamAdmin(call.Context, dto)
+		if err != nil {
+			logger.WithField("team", dto.Team.Value).WithError(err).Error("failed to remove team admin")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		transport.WriteJSON(w, apps.CallResponse{
+			Type:     apps.CallResponseTypeOK,
+			Markdown: md.MD(fmt.Sprintf("@%s isn't an admin of team **%s** anymore", dto.Member.Label, team.Name)),
+		})
+	}
+}
+
+func handleSetMemberAttributes(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		call, err := apps.CallRequestFromJSONReader(r.Body)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		jsonString, err := json.Marshal(call.Values)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		var dto MemberAttributesDTO
+		if err := json.Unmarshal(jsonString, &dto); err != nil {
+			logger.WithError(err).Error("failed to unmarshal json")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := dto.Validate(); err != nil {
+			logger.WithError(err).Error("failed to validate request")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.Authorize(call.Context, PermissionTeamAdmin, dto.Team.Value); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		member, err := svc.SetMemberAttributes(dto)
+		if err != nil {
+			logger.WithField("team", dto.Team.Value).WithError(err).Error("failed to set member attributes")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		level := string(member.Level)
+		if level == "" {
+			level = "without level"
+		}
+		transport.WriteJSON(w, apps.CallResponse{
+			Type:     apps.CallResponseTypeOK,
+			Markdown: md.MD(fmt.Sprintf("@%s of team **%s** is **%s** with the skills: %s", member.Label, dto.Team.Label, level, strings.ReplaceAll(member.Skills, ",", ", "))),
+		})
+	}
+}
+
+func handleAddRole(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dto, ctx, err := parseRoleDTO(r, true)
+		if err != nil {
+			logger.WithError(err).Error("failed to parse team role")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.Authorize(ctx, PermissionTeamAdmin, dto.Team.Value); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		role, err := svc.SaveRole(dto)
+		if err != nil {
+			logger.WithField("team", dto.Team.Value).WithError(err).Error("failed to save team role")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		transport.WriteJSON(w, apps.CallResponse{
+			Type:     apps.CallResponseTypeOK,
+			Markdown: md.MD(fmt.Sprintf("Team **%s** nominates %d member(s) as **%s**", dto.Team.Label, role.Count, role.Label)),
+		})
+	}
+}
+
+func handleListRoles(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		call, err := apps.CallRequestFromJSONReader(r.Body)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		jsonString, err := json.Marshal(call.Values)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		var dto RoleDTO
+		if err := json.Unmarshal(jsonString, &dto); err != nil {
+			logger.WithError(err).Error("failed to unmarshal json")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if dto.Team.Value == "" {
+			transport.WriteBadRequestError(w, fmt.Errorf("failed: missing required field team"))
+			return
+		}
+		if err := svc.Authorize(call.Context, PermissionUser, ""); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		roles, err := svc.ListRoles(dto.Team.Value)
+		if err != nil {
+			logger.WithField("team", dto.Team.Value).WithError(err).Error("failed to list team roles")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		transport.WriteJSON(w, apps.CallResponse{
+			Type:     apps.CallResponseTypeOK,
+			Markdown: getRolesMarkdown(dto.Team.Label, roles),
+		})
+	}
+}
+
+func handleRemoveRole(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dto, ctx, err := parseRoleDTO(r, false)
+		if err != nil {
+			logger.WithError(err).Error("failed to parse team role")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.Authorize(ctx, PermissionTeamAdmin, dto.Team.Value); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		if err := svc.RemoveRole(dto.Team.Value, NomineeRole(dto.Name)); err != nil {
+			logger.WithField("team", dto.Team.Value).WithError(err).Error("failed to remove team role")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		transport.WriteJSON(w, apps.CallResponse{
+			Type:     apps.CallResponseTypeOK,
+			Markdown: md.MD(fmt.Sprintf("Team **%s** no longer nominates the role **%s**", dto.Team.Label, dto.Name)),
+		})
+	}
+}
+
+func parseRoleDTO(r *http.Request, add bool) (RoleDTO, *apps.Context, error) {
+	call, err := apps.CallRequestFromJSONReader(r.Body)
+	if err != nil {
+		return RoleDTO{}, nil, err
+	}
+	jsonString, err := json.Marshal(call.Values)
+	if err != nil {
+		return RoleDTO{}, nil, err
+	}
+	var dto RoleDTO
+	if err := json.Unmarshal(jsonString, &dto); err != nil {
+		return RoleDTO{}, nil, err
+	}
+	dto.Name = strings.ToLower(strings.TrimSpace(dto.Name))
+	if err := dto.Validate(add); err != nil {
+		return RoleDTO{}, nil, err
+	}
+	return dto, call.Context, nil
+}
+
+func handleAddExemption(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		call, dto, err := parseExemptionDTO(r, true)
+		if err != nil {
+			logger.WithError(err).Error("failed to parse exemption")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.Authorize(call.Context, PermissionTeamAdmin, dto.Team.Value); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		exemptions, err := svc.AddExemption(call.Context, dto)
+		if err != nil {
+			logger.WithField("team", dto.Team.Value).WithError(err).Error("failed to add exemption")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		transport.WriteJSON(w, apps.CallResponse{
+			Type:     apps.CallResponseTypeOK,
+			Markdown: getExemptionsMarkdown(dto.Team.Label, exemptions),
+		})
+	}
+}
+
+func handleListExemptions(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		call, err := apps.CallRequestFromJSONReader(r.Body)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		jsonString, err := json.Marshal(call.Values)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		var dto ExemptionDTO
+		if err := json.Unmarshal(jsonString, &dto); err != nil {
+			logger.WithError(err).Error("failed to unmarshal json")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if dto.Team.Value == "" {
+			transport.WriteBadRequestError(w, fmt.Errorf("failed: missing required field team"))
+			return
+		}
+		if err := svc.Authorize(call.Context, PermissionMember, dto.Team.Value); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		exemptions, err := svc.ListExemptions(dto.Team.Value)
+		if err != nil {
+			logger.WithField("team", dto.Team.Value).WithError(err).Error("failed to list exemptions")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		transport.WriteJSON(w, apps.CallResponse{
+			Type:     apps.CallResponseTypeOK,
+			Markdown: getExemptionsMarkdown(dto.Team.Label, exemptions),
+		})
+	}
+}
+
+func handleRemoveExemption(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		call, dto, err := parseExemptionDTO(r, false)
+		if err != nil {
+			logger.WithError(err).Error("failed to parse exemption")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.Authorize(call.Context, PermissionTeamAdmin, dto.Team.Value); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		if err := svc.RemoveExemption(call.Context, dto); err != nil {
+			logger.WithField("team", dto.Team.Value).WithError(err).Error("failed to remove exemption")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		transport.WriteJSON(w, apps.CallResponse{
+			Type:     apps.CallResponseTypeOK,
+			Markdown: md.MD(fmt.Sprintf("@%s is back in the rotation of team **%s**", dto.Member.Label, dto.Team.Label)),
+		})
+	}
+}
+
+func parseExemptionDTO(r *http.Request, add bool) (*apps.CallRequest, ExemptionDTO, error) {
+	call, err := apps.CallRequestFromJSONReader(r.Body)
+	if err != nil {
+		return nil, ExemptionDTO{}, err
+	}
+	jsonString, err := json.Marshal(call.Values)
+	if err != nil {
+		return nil, ExemptionDTO{}, err
+	}
+	var dto ExemptionDTO
+	if err := json.Unmarshal(jsonString, &dto); err != nil {
+		return nil, ExemptionDTO{}, err
+	}
+	if err := dto.Validate(add); err != nil {
+		return nil, ExemptionDTO{}, err
+	}
+	return call, dto, nil
+}
+
+func handleGetTeams(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		call, err := apps.CallRequestFromJSONReader(r.Body)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		jsonString, err := json.Marshal(call.Values)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		var dto ListTeamsDTO
+		if err := json.Unmarshal(jsonString, &dto); err != nil {
+			logger.WithError(err).Error("failed to unmarshal json")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.Authorize(call.Context, PermissionUser, ""); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		summaries, err := svc.ListTeamSummaries(call.Context, dto.Name)
+		if err != nil {
+			logger.WithError(err).Error("failed to get teams")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+
+		markdown := getTeamsMarkdown(summaries)
+		if dto.Name != "" && len(summaries) == 1 {
+			markdown = getTeamSummaryMarkdown(summaries[0])
+		}
+		transport.WriteJSON(w, apps.CallResponse{
+			Type:     apps.CallResponseTypeOK,
+			Markdown: markdown,
+		})
+	}
+}
+
+func handleGamedayLookupTeams(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		call, err := apps.CallRequestFromJSONReader(r.Body)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.Authorize(call.Context, PermissionUser, ""); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		if call.SelectedField != "team" {
+			transport.WriteBadRequestError(w, fmt.Errorf("unexpected lookup field: %s", call.SelectedField))
+			return
+		}
+
+		teams, err := svc.LookupTeams()
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		transport.WriteJSON(w, apps.CallResponse{
+			Type: apps.CallResponseTypeOK,
+			Data: map[string]interface{}{
+				"items": teams,
+			},
+		})
+	}
+}
+
+// handleGamedayLookup lookups the teams and the templates for
+// the gameday create form
+func handleGamedayLookup(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		call, err := apps.CallRequestFromJSONReader(r.Body)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.Authorize(call.Context, PermissionUser, ""); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+
+		var items []LookupDTO
+		switch call.SelectedField {
+		case "team":
+			items, err = svc.LookupTeams()
+		case "template":
+			items, err = svc.LookupTemplates()
+		default:
+			transport.WriteBadRequestError(w, fmt.Errorf("unexpected lookup field: %s", call.SelectedField))
+			return
+		}
+		if err != nil {
+			logger.WithField("field", call.SelectedField).WithError(err).Error("failed to lookup")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		transport.WriteJSON(w, apps.CallResponse{
+			Type: apps.CallResponseTypeOK,
+			Data: map[string]interface{}{
+				"items": items,
+			},
+		})
+	}
+}
+
+func handleCreateGameday(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		call, err := apps.CallRequestFromJSONReader(r.Body)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+
+		jsonString, err := json.Marshal(call.Values)
+		if err != nil {
+			logger.WithError(err).Error("failed to unmarshal create gameday request")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		var dto GamedayDTO
+		if err := json.Unmarshal(jsonString, &dto); err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if dto.Template.Value != "" {
+			templated, err := svc.ApplyTemplate(dto)
+			if err != nil {
+				logger.WithField("template", dto.Template.Value).WithError(err).Error("failed to apply gameday template")
+				transport.WriteBadRequestError(w, err)
+				return
+			}
+			dto = templated
+		}
+		if err := dto.Validate(); err != nil {
+			logger.WithError(err).Error("failed to validate request")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		// previewing the nominees doesn't notify anyone
+		permission := PermissionTeamAdmin
+		if dto.DryRun {
+			permission = PermissionMember
+		}
+		if err := svc.Authorize(call.Context, permission, dto.Team.Value); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		if dto.DryRun {
+			preview, err := svc.PreviewGameday(call.Context, dto)
+			if err != nil {
+				logger.WithError(err).Error("failed to preview gameday")
+				transport.WriteBadRequestError(w, err)
+				return
+			}
+			transport.WriteJSON(w, apps.CallResponse{
+				Type:     apps.CallResponseTypeOK,
+				Markdown: getPreviewMarkdown(preview),
+			})
+			return
+		}
+		if err := svc.CreateGameday(call.Context, dto); err != nil {
+			logger.WithError(err).Error("failed to create gameday")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+
+		transport.WriteJSON(w, apps.CallResponse{
+			Type:     apps.CallResponseTypeOK,
+			Markdown: md.MD(fmt.Sprintf("Gameday **%s** scheduled succesfully", dto.Name)),
+		})
+	}
+}
+
+func handleCloneGameday(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		call, err := apps.CallRequestFromJSONReader(r.Body)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		jsonString, err := json.Marshal(call.Values)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		var dto CloneGamedayDTO
+		if err := json.Unmarshal(jsonString, &dto); err != nil {
+			logger.WithError(err).Error("failed to unmarshal json")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := dto.Validate(); err != nil {
+			logger.WithError(err).Error("failed to validate request")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.AuthorizeGameday(call.Context, PermissionTeamAdmin, dto.ID.Value); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		clone, err := svc.CloneGameday(call.Context, dto)
+		if err != nil {
+			logger.WithField("ID", dto.ID.Value).WithError(err).Error("failed to clone gameday")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+
+		transport.WriteJSON(w, apps.CallResponse{
+			Type:     apps.CallResponseTypeOK,
+			Markdown: md.MD(fmt.Sprintf("Gameday **%s** cloned and scheduled for %s", clone.Name, clone.ScheduledAt.String())),
+		})
+	}
+}
+
+func handleListGameDays(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		call, err := apps.CallRequestFromJSONReader(r.Body)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.Authorize(call.Context, PermissionUser, ""); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		gamedays, err := svc.ListGamedays()
+		if err != nil {
+			logger.WithError(err).Error("failed to list gamedays")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+
+		transport.WriteJSON(w, apps.CallResponse{
+			Type:     apps.CallResponseTypeOK,
+			Markdown: getGameDaysMarkdown(gamedays),
+		})
+	}
+}
+
+func handleArchiveGameDays(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		call, err := apps.CallRequestFromJSONReader(r.Body)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.Authorize(call.Context, PermissionUser, ""); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		gamedays, err := svc.ListArchivedGamedays()
+		if err != nil {
+			logger.WithError(err).Error("failed to list archived gamedays")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+
+		transport.WriteJSON(w, apps.CallResponse{
+			Type:     apps.CallResponseTypeOK,
+			Markdown: getGameDaysMarkdown(gamedays),
+		})
+	}
+}
+
+func handleLookupGamedays(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		call, err := apps.CallRequestFromJSONReader(r.Body)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.Authorize(call.Context, PermissionUser, ""); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		if call.SelectedField != "id" {
+			transport.WriteBadRequestError(w, fmt.Errorf("unexpected lookup field: %s", call.SelectedField))
+			return
+		}
+
+		var states []string
+		if strings.Contains(call.Path, "start") || strings.Contains(call.Path, "reschedule") {
+			states = append(states, string(GamedayScheduledState))
+		} else if strings.Contains(call.Path, "complete") || strings.Contains(call.Path, "attendance") {
+			states = append(states, string(GamedayInProgressState))
+		} else if strings.Contains(call.Path, "show") || strings.Contains(call.Path, "clone") || strings.Contains(call.Path, "verify") {
+			states = append(states, string(GamedayScheduledState), string(GamedayInProgressState), string(GamedayCompletedState), string(GamedayCancelledState))
+		} else {
+			states = append(states, string(GamedayScheduledState), string(GamedayInProgressState))
+		}
+		gamedays, err := svc.LookupGamedays(states)
+		if err != nil {
+			logger.WithField("states", states).WithError(err).Error("failed to lookup gamedays by state")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		transport.WriteJSON(w, apps.CallResponse{
+			Type: apps.CallResponseTypeOK,
+			Data: map[string]interface{}{
+				"items": gamedays,
+			},
+		})
+	}
+}
+
+func handleLookupNomineeRoles(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	lookupGamedays := handleLookupGamedays(svc, logger)
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		call, err := apps.CallRequestFromJSONReader(bytes.NewReader(body))
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.Authorize(call.Context, PermissionUser, ""); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		if call.SelectedField != "role" {
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			lookupGamedays(w, r)
+			return
+		}
+
+		var roles []LookupDTO
+		if gameday, ok := call.Values["id"].(map[string]interface{}); ok {
+			gamedayID, _ := gameday["value"].(string)
+			if roles, err = svc.LookupRoles(gamedayID); err != nil {
+				logger.WithField("ID", gamedayID).WithError(err).Error("failed to lookup gameday roles")
+				transport.WriteBadRequestError(w, err)
+				return
+			}
+		}
+		transport.WriteJSON(w, apps.CallResponse{
+			Type: apps.CallResponseTypeOK,
+			Data: map[string]interface{}{
+				"items": roles,
+			},
+		})
+	}
+}
+
+func handleStartGameDay(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dto, ctx, err := parseUpdateGamedayStateDto(r)
+		if err != nil {
+			logger.WithError(err).Error("failed to parse gameday state")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.AuthorizeGameday(ctx, PermissionMember, dto.ID.Value); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		if err := svc.UpdateGamedayState(ctx, dto.ID.Value, GamedayInProgressState, ""); err != nil {
+			logger.WithField("ID", dto.ID.Value).WithError(err).Error("failed to start the gameday")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		transport.WriteJSON(w, apps.CallResponse{
+			Type:     apps.CallResponseTypeOK,
+			Markdown: md.MD("Gameday just started"),
+		})
+	}
+}
+
+func handleCompleteGameDay(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dto, ctx, err := parseUpdateGamedayStateDto(r)
+		if err != nil {
+			logger.WithError(err).Error("failed to parse gameday state")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.AuthorizeGameday(ctx, PermissionMember, dto.ID.Value); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		if err := svc.UpdateGamedayState(ctx, dto.ID.Value, GamedayCompletedState, dto.Reason); err != nil {
+			logger.WithField("ID", dto.ID.Value).WithError(err).Error("failed to complete the gameday")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		transport.WriteJSON(w, apps.CallResponse{
+			Type:     apps.CallResponseTypeOK,
+			Markdown: md.MD("Gameday just completed"),
+		})
+	}
+}
+func handleCancelGameDay(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dto, ctx, err := parseUpdateGamedayStateDto(r)
+		if err != nil {
+			logger.WithError(err).Error("failed to parse gameday state")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.AuthorizeGameday(ctx, PermissionTeamAdmin, dto.ID.Value); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		if err := svc.UpdateGamedayState(ctx, dto.ID.Value, GamedayCancelledState, dto.Reason); err != nil {
+			logger.WithField("ID", dto.ID.Value).WithError(err).Error("failed to cancel the gameday")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		transport.WriteJSON(w, apps.CallResponse{
+			Type:     apps.CallResponseTypeOK,
+			Markdown: md.MD("Gameday just cancelled"),
+		})
+	}
+}
+
+func handleShowGameDay(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dto, ctx, err := parseUpdateGamedayStateDto(r)
+		if err != nil {
+			logger.WithError(err).Error("failed to parse gameday")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.Authorize(ctx, PermissionUser, ""); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		gameday, nominees, err := svc.GetGameday(dto.ID.Value)
+		if err != nil {
+			logger.WithField("ID", dto.ID.Value).WithError(err).Error("failed to get the gameday")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		rsvps, err := svc.ListAttendance(dto.ID.Value)
+		if err != nil {
+			logger.WithField("ID", dto.ID.Value).WithError(err).Error("failed to get the gameday attendance")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		history, err := svc.GetGamedayHistory(dto.ID.Value)
+		if err != nil {
+			logger.WithField("ID", dto.ID.Value).WithError(err).Error("failed to get the gameday history")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		transport.WriteJSON(w, apps.CallResponse{
+			Type:     apps.CallResponseTypeOK,
+			Markdown: getGamedayMarkdown(gameday, nominees, rsvps, history),
+		})
+	}
+}
+
+func handleReschedule(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		call, err := apps.CallRequestFromJSONReader(r.Body)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		jsonString, err := json.Marshal(call.Values)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		var dto RescheduleDTO
+		if err := json.Unmarshal(jsonString, &dto); err != nil {
+			logger.WithError(err).Error("failed to unmarshal json")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := dto.Validate(); err != nil {
+			logger.WithError(err).Error("failed to validate request")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.AuthorizeGameday(call.Context, PermissionTeamAdmin, dto.ID.Value); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		unavailable, err := svc.Reschedule(call.Context, dto)
+		if err != nil {
+			logger.WithField("ID", dto.ID.Value).WithError(err).Error("failed to reschedule gameday")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+
+		msg := fmt.Sprintf("Gameday rescheduled to **%s**", dto.ScheduledAt.String())
+		for _, n := range unavailable {
+			msg += fmt.Sprintf("\n:warning: the **%s** @%s is away, you can draw another member with `gameday renominate`", getNomineeRole(n), n.Label)
+		}
+		transport.WriteJSON(w, apps.CallResponse{
+			Type:     apps.CallResponseTypeOK,
+			Markdown: md.MD(msg),
+		})
+	}
+}
+
+func handleAway(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		call, err := apps.CallRequestFromJSONReader(r.Body)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		jsonString, err := json.Marshal(call.Values)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		var dto AwayDTO
+		if err := json.Unmarshal(jsonString, &dto); err != nil {
+			logger.WithError(err).Error("failed to unmarshal json")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := dto.Validate(); err != nil {
+			logger.WithError(err).Error("failed to validate request")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.Authorize(call.Context, PermissionUser, ""); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		aways, err := svc.SetAway(call.Context, dto)
+		if err != nil {
+			logger.WithError(err).Error("failed to set away period")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		transport.WriteJSON(w, apps.CallResponse{
+			Type:     apps.CallResponseTypeOK,
+			Markdown: getAwayMarkdown(aways),
+		})
+	}
+}
+
+func handleProfile(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		call, err := apps.CallRequestFromJSONReader(r.Body)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		jsonString, err := json.Marshal(call.Values)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		var dto ProfileDTO
+		if err := json.Unmarshal(jsonString, &dto); err != nil {
+			logger.WithError(err).Error("failed to unmarshal json")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := dto.Validate(); err != nil {
+			logger.WithError(err).Error("failed to validate request")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.Authorize(call.Context, PermissionUser, ""); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		members, err := svc.Profile(call.Context, dto)
+		if err != nil {
+			logger.WithError(err).Error("failed to update profile")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		transport.WriteJSON(w, apps.CallResponse{
+			Type:     apps.CallResponseTypeOK,
+			Markdown: getProfileMarkdown(members),
+		})
+	}
+}
+
+func handleRenominate(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		call, err := apps.CallRequestFromJSONReader(r.Body)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		jsonString, err := json.Marshal(call.Values)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		var dto RenominateDTO
+		if err := json.Unmarshal(jsonString, &dto); err != nil {
+			logger.WithError(err).Error("failed to unmarshal json")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := dto.Validate(); err != nil {
+			logger.WithError(err).Error("failed to validate request")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.AuthorizeGameday(call.Context, PermissionTeamAdmin, dto.ID.Value); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		nominee, err := svc.Renominate(call.Context, dto.ID.Value, NomineeRole(dto.Role.Value), dto.Member.UserID)
+		if err != nil {
+			logger.WithField("ID", dto.ID.Value).WithError(err).Error("failed to renominate")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		transport.WriteJSON(w, apps.CallResponse{
+			Type:     apps.CallResponseTypeOK,
+			Markdown: md.MD(fmt.Sprintf("@%s is the new **%s** of the gameday", nominee.Label, getNomineeRole(nominee))),
+		})
+	}
+}
+
+func handleSwapNominee(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		call, err := apps.CallRequestFromJSONReader(r.Body)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		jsonString, err := json.Marshal(call.Values)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		var dto SwapNomineeDTO
+		if err := json.Unmarshal(jsonString, &dto); err != nil {
+			logger.WithError(err).Error("failed to unmarshal json")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := dto.Validate(); err != nil {
+			logger.WithError(err).Error("failed to validate request")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.AuthorizeSwap(call.Context, dto); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		nominee, err := svc.SwapNominee(call.Context, dto.ID.Value, NomineeRole(dto.Role.Value), dto.Member.UserID, dto.With.UserID)
+		if err != nil {
+			logger.WithField("ID", dto.ID.Value).WithError(err).Error("failed to swap nominee")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		transport.WriteJSON(w, apps.CallResponse{
+			Type:     apps.CallResponseTypeOK,
+			Markdown: md.MD(fmt.Sprintf("@%s volunteered as the **%s** of the gameday", nominee.Label, getNomineeRole(nominee))),
+		})
+	}
+}
+
+func handleVerifyNominees(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dto, ctx, err := parseUpdateGamedayStateDto(r)
+		if err != nil {
+			logger.WithError(err).Error("failed to parse gameday")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.Authorize(ctx, PermissionUser, ""); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		verification, err := svc.VerifyNominees(dto.ID.Value)
+		if err != nil {
+			logger.WithField("ID", dto.ID.Value).WithError(err).Error("failed to verify the gameday nominees")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		transport.WriteJSON(w, apps.CallResponse{
+			Type:     apps.CallResponseTypeOK,
+			Markdown: getVerificationMarkdown(verification),
+		})
+	}
+}
+
+func handleRSVPGameDay(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		call, err := apps.CallRequestFromJSONReader(r.Body)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		jsonString, err := json.Marshal(call.State)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		var state gamedayActionState
+		if err := json.Unmarshal(jsonString, &state); err != nil {
+			logger.WithError(err).Error("failed to unmarshal json")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.AuthorizeGameday(call.Context, PermissionMember, state.ID); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		if state.Response != RSVPAttending && state.Response != RSVPDeclined {
+			transport.WriteBadRequestError(w, fmt.Errorf("unexpected response: %s", state.Response))
+			return
+		}
+		gameday, err := svc.RespondRSVP(call.Context, state.ID, state.Response)
+		if err != nil {
+			logger.WithField("ID", state.ID).WithError(err).Error("failed to respond to the gameday")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+
+		msg := fmt.Sprintf("See you at gameday **%s**", gameday.Title)
+		if state.Response == RSVPDeclined {
+			msg = fmt.Sprintf("Thanks for letting us know you can't attend gameday **%s**", gameday.Title)
+		}
+		transport.WriteJSON(w, apps.CallResponse{
+			Type:     apps.CallResponseTypeOK,
+			Markdown: md.MD(msg),
+		})
+	}
+}
+
+func handleAcknowledge(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		call, err := apps.CallRequestFromJSONReader(r.Body)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		jsonString, err := json.Marshal(call.State)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		var state gamedayActionState
+		if err := json.Unmarshal(jsonString, &state); err != nil {
+			logger.WithError(err).Error("failed to unmarshal json")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.AuthorizeGameday(call.Context, PermissionMember, state.ID); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		gameday, nominees, err := svc.Acknowledge(call.Context, state.ID)
+		if err != nil {
+			logger.WithField("ID", state.ID).WithError(err).Error("failed to acknowledge the nomination")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+
+		var roles []string
+		for _, n := range nominees {
+			roles = append(roles, getNomineeRole(n))
+		}
+		transport.WriteJSON(w, apps.CallResponse{
+			Type:     apps.CallResponseTypeOK,
+			Markdown: md.MD(fmt.Sprintf("Thanks, you are confirmed as **%s** for gameday **%s**", strings.Join(roles, ", "), gameday.Title)),
+		})
+	}
+}
+
+// writeAuthorizationError responds that the call isn't permitted when
+// the acting user misses the permission
+func writeAuthorizationError(w http.ResponseWriter, err error) {
+	if IsNotPermitted(err) {
+		transport.WriteNotPermittedError(w, err)
+		return
+	}
+	transport.WriteBadRequestError(w, err)
+}
+
+// calendarFeedRoute the name of the route of the calendar feeds, the
+// calendar apps fetch it without a call so the token of the URL is the
+// only credential
+const calendarFeedRoute = "calendar-feed"
+
+// VerifyCalls rejects the calls which aren't signed by Mattermost with the
+// secret of the app or which are made on behalf of another user, whatever
+// their method, the verified calls reach the configured server with the
+// configured bot token
+func VerifyCalls(app config.App) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if route := mux.CurrentRoute(r); route != nil && route.GetName() == calendarFeedRoute {
+				next.ServeHTTP(w, r)
+				return
+			}
+			claims, err := transport.CheckJWT(r, app.Secret)
+			if err != nil {
+				transport.WriteUnauthorizedError(w, err)
+				return
+			}
+			call, err := apps.CallRequestFromJSONReader(r.Body)
+			if err != nil {
+				transport.WriteBadRequestError(w, err)
+				return
+			}
+			if call.Context == nil || claims.ActingUserID != call.Context.ActingUserID {
+				transport.WriteUnauthorizedError(w, transport.ErrActingUserMismatch)
+				return
+			}
+			trustContext(call.Context, app)
+
+			body, err := json.Marshal(call)
+			if err != nil {
+				transport.WriteBadRequestError(w, err)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			r.ContentLength = int64(len(body))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// trustContext replaces the server and the bot token of the call with the
+// configured ones, the token of the call is kept when none is configured
+func trustContext(ctx *apps.Context, app config.App) {
+	ctx.MattermostSiteURL = strings.TrimSuffix(app.MattermostSiteURL, "/")
+	if app.BotAccessToken != "" {
+		ctx.BotAccessToken = app.BotAccessToken
+	}
+}
+
+func handleAttendanceGameDay(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		call, err := apps.CallRequestFromJSONReader(r.Body)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		jsonString, err := json.Marshal(call.Values)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		var dto AttendanceDTO
+		if err := json.Unmarshal(jsonString, &dto); err != nil {
+			logger.WithError(err).Error("failed to unmarshal json")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := dto.Validate(); err != nil {
+			logger.WithError(err).Error("failed to validate request")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.AuthorizeGameday(call.Context, PermissionMember, dto.ID.Value); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		if err := svc.TakeAttendance(call.Context, dto.ID.Value, dto.Member.UserID); err != nil {
+			logger.WithField("ID", dto.ID.Value).WithError(err).Error("failed to take attendance")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		transport.WriteJSON(w, apps.CallResponse{
+			Type:     apps.CallResponseTypeOK,
+			Markdown: md.MD(fmt.Sprintf("@%s attended the gameday", dto.Member.Label)),
+		})
+	}
+}
+
+func handleSubscribe(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		call, err := apps.CallRequestFromJSONReader(r.Body)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		jsonString, err := json.Marshal(call.Values)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		var dto SubscriptionDTO
+		if err := json.Unmarshal(jsonString, &dto); err != nil {
+			logger.WithError(err).Error("failed to unmarshal json")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := dto.Validate(); err != nil {
+			logger.WithError(err).Error("failed to validate request")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.Authorize(call.Context, PermissionMember, dto.Team.Value); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		if err := svc.Subscribe(call.Context, dto); err != nil {
+			logger.WithField("team", dto.Team.Value).WithError(err).Error("failed to subscribe")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		transport.WriteJSON(w, apps.CallResponse{
+			Type:     apps.CallResponseTypeOK,
+			Markdown: md.MD(fmt.Sprintf("Channel subscribed to **%s** events for team **%s**", strings.Join(dto.ParseEvents(), ", "), dto.Team.Label)),
+		})
+	}
+}
+
+func handleUnsubscribe(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		call, err := apps.CallRequestFromJSONReader(r.Body)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		jsonString, err := json.Marshal(call.Values)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		var dto UnsubscribeDTO
+		if err := json.Unmarshal(jsonString, &dto); err != nil {
+			logger.WithError(err).Error("failed to unmarshal json")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := dto.Validate(); err != nil {
+			logger.WithError(err).Error("failed to validate request")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.Authorize(call.Context, PermissionMember, dto.Team.Value); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		if err := svc.Unsubscribe(call.Context, dto.Team.Value); err != nil {
+			logger.WithField("team", dto.Team.Value).WithError(err).Error("failed to unsubscribe")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		transport.WriteJSON(w, apps.CallResponse{
+			Type:     apps.CallResponseTypeOK,
+			Markdown: md.MD(fmt.Sprintf("Channel unsubscribed from team **%s**", dto.Team.Label)),
+		})
+	}
+}
+
+func handleListSubscriptions(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		call, err := apps.CallRequestFromJSONReader(r.Body)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.Authorize(call.Context, PermissionUser, ""); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		subscriptions, err := svc.ListSubscriptions(call.Context.ChannelID)
+		if err != nil {
+			logger.WithError(err).Error("failed to list subscriptions")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		transport.WriteJSON(w, apps.CallResponse{
+			Type:     apps.CallResponseTypeOK,
+			Markdown: getSubscriptionsMarkdown(subscriptions),
+		})
+	}
+}
+
+func handleCreateTemplate(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return handleSaveTemplate(svc, logger, true, svc.CreateTemplate)
+}
+
+func handleEditTemplate(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return handleSaveTemplate(svc, logger, false, svc.EditTemplate)
+}
+
+// handleSaveTemplate parses and validates the template form and saves
+// the template with the given function
+func handleSaveTemplate(svc *Service, logger logrus.FieldLogger, create bool, save func(TemplateDTO) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		call, err := apps.CallRequestFromJSONReader(r.Body)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		jsonString, err := json.Marshal(call.Values)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		var dto TemplateDTO
+		if err := json.Unmarshal(jsonString, &dto); err != nil {
+			logger.WithError(err).Error("failed to unmarshal json")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := dto.Validate(create); err != nil {
+			logger.WithError(err).Error("failed to validate request")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		// an edited template may be moved to another team administered by the user
+		if !create {
+			if err := svc.AuthorizeTemplate(call.Context, PermissionTeamAdmin, dto.Name); err != nil {
+				writeAuthorizationError(w, err)
+				return
+			}
+		}
+		if dto.Team.Value != "" {
+			if err := svc.Authorize(call.Context, PermissionTeamAdmin, dto.Team.Value); err != nil {
+				writeAuthorizationError(w, err)
+				return
+			}
+		}
+		if err := save(dto); err != nil {
+			logger.WithField("name", dto.Name).WithError(err).Error("failed to save gameday template")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		transport.WriteJSON(w, apps.CallResponse{
+			Type:     apps.CallResponseTypeOK,
+			Markdown: md.MD(fmt.Sprintf("Gameday template **%s** saved succesfully", dto.Name)),
+		})
+	}
+}
+
+func handleListTemplates(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		call, err := apps.CallRequestFromJSONReader(r.Body)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.Authorize(call.Context, PermissionUser, ""); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		templates, err := svc.ListTemplates()
+		if err != nil {
+			logger.WithError(err).Error("failed to list gameday templates")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		transport.WriteJSON(w, apps.CallResponse{
+			Type:     apps.CallResponseTypeOK,
+			Markdown: getTemplatesMarkdown(templates),
+		})
+	}
+}
+
+func handleImport(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		call, err := apps.CallRequestFromJSONReader(r.Body)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		jsonString, err := json.Marshal(call.Values)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		var dto ImportDTO
+		if err := json.Unmarshal(jsonString, &dto); err != nil {
+			logger.WithError(err).Error("failed to unmarshal json")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := dto.Validate(); err != nil {
+			logger.WithError(err).Error("failed to validate request")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := AuthorizeSystemAdmin(call.Context); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		report, err := svc.Import(call.Context, dto)
+		if err != nil {
+			logger.WithField("format", dto.Format.Value).WithError(err).Error("failed to import")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		transport.WriteJSON(w, apps.CallResponse{
+			Type:     apps.CallResponseTypeOK,
+			Markdown: getImportMarkdown(report),
+		})
+	}
+}
+
+func handleCalendarLink(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		call, err := apps.CallRequestFromJSONReader(r.Body)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		jsonString, err := json.Marshal(call.Values)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		var dto CalendarLinkDTO
+		if err := json.Unmarshal(jsonString, &dto); err != nil {
+			logger.WithError(err).Error("failed to unmarshal json")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		// the feed of a team is only linked to its members
+		permission := PermissionUser
+		if dto.Team.Value != "" {
+			permission = PermissionMember
+		}
+		if err := svc.Authorize(call.Context, permission, dto.Team.Value); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		link, err := svc.CalendarLink(call.Context, dto.Team.Value)
+		if err != nil {
+			logger.WithField("team", dto.Team.Value).WithError(err).Error("failed to create calendar link")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+
+		msg := fmt.Sprintf("Subscribe to your gamedays calendar with: %s", link)
+		if dto.Team.Value != "" {
+			msg = fmt.Sprintf("Subscribe to the gamedays calendar of team **%s** with: %s", dto.Team.Label, link)
+		}
+		transport.WriteJSON(w, apps.CallResponse{
+			Type:     apps.CallResponseTypeOK,
+			Markdown: md.MD(msg),
+		})
+	}
+}
+
+func handleCalendarRotate(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		call, err := apps.CallRequestFromJSONReader(r.Body)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		jsonString, err := json.Marshal(call.Values)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		var dto CalendarLinkDTO
+		if err := json.Unmarshal(jsonString, &dto); err != nil {
+			logger.WithError(err).Error("failed to unmarshal json")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.Authorize(call.Context, dto.permission(), dto.Team.Value); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		link, err := svc.RotateCalendarLink(call.Context, dto.Team.Value)
+		if err != nil {
+			logger.WithField("team", dto.Team.Value).WithError(err).Error("failed to rotate calendar link")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+
+		msg := fmt.Sprintf("The previous link stopped working, subscribe to your gamedays calendar with: %s", link)
+		if dto.Team.Value != "" {
+			msg = fmt.Sprintf("The previous link stopped working, subscribe to the gamedays calendar of team **%s** with: %s", dto.Team.Label, link)
+		}
+		transport.WriteJSON(w, apps.CallResponse{
+			Type:     apps.CallResponseTypeOK,
+			Markdown: md.MD(msg),
+		})
+	}
+}
+
+func handleCalendarRevoke(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		call, err := apps.CallRequestFromJSONReader(r.Body)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		jsonString, err := json.Marshal(call.Values)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		var dto CalendarLinkDTO
+		if err := json.Unmarshal(jsonString, &dto); err != nil {
+			logger.WithError(err).Error("failed to unmarshal json")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.Authorize(call.Context, dto.permission(), dto.Team.Value); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		if err := svc.RevokeCalendarLink(call.Context, dto.Team.Value); err != nil {
+			logger.WithField("team", dto.Team.Value).WithError(err).Error("failed to revoke calendar link")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+
+		msg := "The link of your gamedays calendar stopped working"
+		if dto.Team.Value != "" {
+			msg = fmt.Sprintf("The link of the gamedays calendar of team **%s** stopped working", dto.Team.Label)
+		}
+		transport.WriteJSON(w, apps.CallResponse{
+			Type:     apps.CallResponseTypeOK,
+			Markdown: md.MD(msg),
+		})
+	}
+}
+
+func handleNominationStats(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		call, err := apps.CallRequestFromJSONReader(r.Body)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		jsonString, err := json.Marshal(call.Values)
+		if err != nil {
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		var dto StatsDTO
+		if err := json.Unmarshal(jsonString, &dto); err != nil {
+			logger.WithError(err).Error("failed to unmarshal json")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := dto.Validate(); err != nil {
+			logger.WithError(err).Error("failed to validate request")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		if err := svc.Authorize(call.Context, PermissionUser, ""); err != nil {
+			writeAuthorizationError(w, err)
+			return
+		}
+		stats, err := svc.NominationStats(dto.Team.Value)
+		if err != nil {
+			logger.WithField("team", dto.Team.Value).WithError(err).Error("failed to compute nomination stats")
+			transport.WriteBadRequestError(w, err)
+			return
+		}
+		transport.WriteJSON(w, apps.CallResponse{
+			Type:     apps.CallResponseTypeOK,
+			Markdown: getNominationStatsMarkdown(stats),
+		})
+	}
+}
+
+func handleCalendarFeed(svc *Service, logger logrus.FieldLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// the calendar apps don't send a call, the secret token of the
+		// feed identifies the member
+		var b bytes.Buffer
+		if err := svc.WriteCalendar(&b, mux.Vars(r)["token"]); err != nil {
+			if err == ErrCalendarNotFound {
+				http.NotFound(w, r)
+				return
+			}
+			logger.WithError(err).Error("failed to write calendar")
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+		_, _ = b.WriteTo(w)
+	}
+}
+
+// parseUpdateGamedayStateDto parses the gameday ID either from the
+// submitted form or from the state of a gameday post action
+func parseUpdateGamedayStateDto(r *http.Request) (UpdateGameDayStateDTO, *apps.Context, error) {
+	call, err := apps.CallRequestFromJSONReader(r.Body)
+	if err != nil {
+		return UpdateGameDayStateDTO{}, nil, err
+	}
+
+	jsonString, err := json.Marshal(call.Values)
+	if err != nil {
+		return UpdateGameDayStateDTO{}, nil, err
 	}
 	var dto UpdateGameDayStateDTO
 	if err := json.Unmarshal(jsonString, &dto); err != nil {
-		return LookupDTO{}, err
+		return UpdateGameDayStateDTO{}, nil, err
+	}
+	if dto.ID.Value != "" || call.State == nil {
+		return dto, call.Context, nil
 	}
-	return dto.ID, nil
 
+	jsonString, err = json.Marshal(call.State)
+	if err != nil {
+		return UpdateGameDayStateDTO{}, nil, err
+	}
+	var state gamedayActionState
+	if err := json.Unmarshal(jsonString, &state); err != nil {
+		return UpdateGameDayStateDTO{}, nil, err
+	}
+	dto.ID = LookupDTO{Value: state.ID}
+	return dto, call.Context, nil
 }
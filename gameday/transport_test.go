@@ -2,12 +2,24 @@ package gameday
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	jwt "github.com/dgrijalva/jwt-go"
 	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-app-chaosengine/config"
+	"github.com/mattermost/mattermost-app-chaosengine/store"
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-server/v5/model"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -48,10 +60,426 @@ func TestHandleConfigure(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 
 	w := httptest.NewRecorder()
-	handler := http.HandlerFunc(HandleConfigure(router, logger))
+	handler := http.HandlerFunc(HandleConfigure(router, NewRuntime(), logger))
 	handler.ServeHTTP(w, req)
 
 	if w.Code != http.StatusOK {
 		t.Errorf("handler returned wrong status code: got %v want %v", w.Code, http.StatusOK)
 	}
 }
+
+func signedCall(t *testing.T, secret, claimedUserID, actingUserID string) *http.Request {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, apps.JWTClaims{
+		StandardClaims: jwt.StandardClaims{ExpiresAt: time.Now().Add(time.Minute).Unix()},
+		ActingUserID:   claimedUserID,
+	}).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := fmt.Sprintf(`{"context":{"acting_user_id":%q,"mattermost_site_url":"https://evil.example.com","bot_access_token":"forged"}}`, actingUserID)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/teams/list/submit", bytes.NewBufferString(payload))
+	req.Header.Set(apps.OutgoingAuthHeader, "Bearer "+token)
+	return req
+}
+
+func TestVerifyCalls(t *testing.T) {
+	app := config.App{Secret: "secretkey", MattermostSiteURL: "https://chat.example.com/", BotAccessToken: "bot-token"}
+	var received *apps.CallRequest
+	handler := VerifyCalls(app)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = apps.CallRequestFromJSONReader(r.Body)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), signedCall(t, "secretkey", "alice", "alice"))
+	if received == nil {
+		t.Fatal("expected the signed call to reach the handler")
+	}
+	if received.Context.MattermostSiteURL != "https://chat.example.com" || received.Context.BotAccessToken != "bot-token" {
+		t.Errorf("expected the configured server and token, got %s and %s", received.Context.MattermostSiteURL, received.Context.BotAccessToken)
+	}
+
+	for name, req := range map[string]*http.Request{
+		"unsigned":     httptest.NewRequest(http.MethodPost, "/api/v1/teams/list/submit", bytes.NewBufferString(`{"context":{"acting_user_id":"alice"}}`)),
+		"unsigned GET": httptest.NewRequest(http.MethodGet, "/api/v1/teams/list/submit", bytes.NewBufferString(`{"context":{"acting_user_id":"alice","mattermost_site_url":"https://evil.example.com","bot_access_token":"forged"}}`)),
+		"wrong secret": signedCall(t, "guessed", "alice", "alice"),
+		"another user": signedCall(t, "secretkey", "bob", "alice"),
+		"missing user": signedCall(t, "secretkey", "", "alice"),
+	} {
+		received = nil
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if received != nil || w.Code != http.StatusUnauthorized {
+			t.Errorf("%s: expected the call to be rejected, got status %d", name, w.Code)
+		}
+	}
+
+	// the calendar apps fetch the feeds without a call
+	router := mux.NewRouter()
+	router.Use(VerifyCalls(app))
+	var fetched bool
+	router.HandleFunc("/api/v1/calendar/{token:[a-z0-9]+}.ics", func(w http.ResponseWriter, r *http.Request) { fetched = true }).Methods(http.MethodGet).Name(calendarFeedRoute)
+	router.HandleFunc("/api/v1/teams/list/submit", func(w http.ResponseWriter, r *http.Request) { t.Error("expected the unsigned GET to be rejected") })
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/calendar/abc123.ics", nil))
+	if !fetched {
+		t.Error("expected the calendar feed to be served without a call")
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/teams/list/submit", bytes.NewBufferString(`{"context":{"acting_user_id":"alice"}}`)))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected the unsigned GET to be rejected, got status %d", w.Code)
+	}
+}
+
+// newTestService returns a service with an empty database
+func newTestService(t *testing.T) *Service {
+	name := strings.ToLower(t.Name()) + ".db"
+	os.Remove(name)
+	t.Cleanup(func() { os.Remove(name) })
+	s, err := store.New(store.Config{Scheme: "sqlite3", URL: "sqlite3://" + name, MaxOpenConns: 1}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	return NewService(NewRepository(s), "http://localhost:3000", rand.NewSource(1), logger)
+}
+
+// newTestMattermost fakes the Mattermost server, the users are system
+// admins when they are listed and every other request succeeds
+func newTestMattermost(t *testing.T, admins ...string) *httptest.Server {
+	server := httptest.NewServer(mattermostHandler(admins...))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// mattermostHandler answers the requests of the fake Mattermost server
+func mattermostHandler(admins ...string) http.HandlerFunc {
+	isAdmin := map[string]bool{}
+	for _, a := range admins {
+		isAdmin[a] = true
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/api/v4/users/") {
+			id := strings.TrimPrefix(r.URL.Path, "/api/v4/users/")
+			roles := model.SYSTEM_USER_ROLE_ID
+			if isAdmin[id] {
+				roles += " " + model.SYSTEM_ADMIN_ROLE_ID
+			}
+			_ = json.NewEncoder(w).Encode(model.User{Id: id, Username: id, Roles: roles})
+			return
+		}
+		_, _ = w.Write([]byte("{}"))
+	}
+}
+
+// newDirectMessagesRecorder starts a Mattermost server recording the users
+// the direct messages are sent to
+func newDirectMessagesRecorder(t *testing.T) (*httptest.Server, func() []string) {
+	var mu sync.Mutex
+	var recipients []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == "/api/v4/channels/direct" {
+			var ids []string
+			_ = json.NewDecoder(r.Body).Decode(&ids)
+			mu.Lock()
+			recipients = append(recipients, ids[len(ids)-1])
+			mu.Unlock()
+		}
+		mattermostHandler()(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), recipients...)
+	}
+}
+
+// callHandler submits the values to the handler on behalf of the user
+func callHandler(t *testing.T, handler http.HandlerFunc, server *httptest.Server, userID string, values map[string]interface{}) apps.CallResponse {
+	ctx := &apps.Context{ActingUserID: userID, MattermostSiteURL: server.URL}
+	ctx.BotAccessToken = "bot-token"
+	body, err := json.Marshal(apps.CallRequest{Values: values, Context: ctx})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body)))
+	var resp apps.CallResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+// newTestTeam creates the team sre owned by owner with the members and a
+// scheduled gameday where the first member is the On-Call
+func newTestTeam(t *testing.T, svc *Service, members ...string) (string, string) {
+	teamID, err := svc.repo.CreateTeam("sre", "owner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range members {
+		if err := svc.repo.CreateMember(TeamMember{TeamID: teamID, UserID: m, Label: m}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	oncall, err := svc.repo.GetMember(teamID, members[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	gamedayID, err := svc.repo.CreateGamedayWithNominees(Gameday{
+		Title:       "K8s node failures",
+		TeamID:      teamID,
+		ScheduledAt: time.Now().Add(24 * time.Hour).Unix(),
+		State:       GamedayScheduledState,
+	}, []GamedayNominee{{MemberID: oncall.ID, Role: OnCallRole}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return teamID, gamedayID
+}
+
+func TestHandleSwapNominee(t *testing.T) {
+	svc := newTestService(t)
+	server := newTestMattermost(t)
+	_, gamedayID := newTestTeam(t, svc, "alice", "bob", "carol")
+	swap := func(userID, with string) apps.CallResponse {
+		return callHandler(t, handleSwapNominee(svc, logger), server, userID, map[string]interface{}{
+			"id":   map[string]string{"value": gamedayID},
+			"role": map[string]string{"value": string(OnCallRole)},
+			"with": map[string]string{"label": with, "value": with},
+		})
+	}
+
+	if resp := swap("bob", "bob"); !strings.HasPrefix(resp.ErrorText, "Not permitted") {
+		t.Errorf("expected a member not to swap the nomination of another member, got %+v", resp)
+	}
+	if resp := swap("alice", "carol"); resp.Type != apps.CallResponseTypeOK {
+		t.Errorf("expected a member to hand over their own nomination, got %+v", resp)
+	}
+	if resp := swap("owner", "bob"); resp.Type != apps.CallResponseTypeOK {
+		t.Errorf("expected the owner to swap any nomination, got %+v", resp)
+	}
+	_, nominees, err := svc.GetGameday(gamedayID)
+	if err != nil || len(nominees) != 1 || nominees[0].UserID != "bob" {
+		t.Errorf("wrong nominees: got %v (%v)", nominees, err)
+	}
+}
+
+func TestHandleDeleteTeam(t *testing.T) {
+	svc := newTestService(t)
+	var updated []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			updated = append(updated, r.URL.Path)
+		}
+		mattermostHandler()(w, r)
+	}))
+	t.Cleanup(server.Close)
+	teamID, gamedayID := newTestTeam(t, svc, "alice", "bob")
+	if err := svc.repo.UpdateGamedayPost(gamedayID, "channel", "post"); err != nil {
+		t.Fatal(err)
+	}
+	team := map[string]string{"label": "sre", "value": teamID}
+
+	resp := callHandler(t, handleDeleteTeam(svc, logger), server, "owner", map[string]interface{}{"team": team})
+	if !strings.Contains(resp.ErrorText, "--force true") {
+		t.Errorf("expected the team with a scheduled gameday not to be deleted, got %+v", resp)
+	}
+	if len(updated) != 0 {
+		t.Errorf("expected the gameday post not to change, got %v", updated)
+	}
+
+	resp = callHandler(t, handleDeleteTeam(svc, logger), server, "owner", map[string]interface{}{"team": team, "force": true})
+	if resp.Type != apps.CallResponseTypeOK {
+		t.Fatalf("expected the team to be deleted, got %+v", resp)
+	}
+	if len(updated) != 1 || updated[0] != "/api/v4/posts/post" {
+		t.Errorf("expected the post of the cancelled gameday to be updated, got %v", updated)
+	}
+	if g, _ := svc.repo.GetGameday(gamedayID); g != nil {
+		t.Errorf("expected the gameday to be deleted, got %+v", g)
+	}
+}
+
+func TestHandleRenameTeam(t *testing.T) {
+	svc := newTestService(t)
+	server := newTestMattermost(t)
+	teamID, _ := newTestTeam(t, svc, "alice")
+
+	resp := callHandler(t, handleRenameTeam(svc, logger), server, "owner", map[string]interface{}{
+		"team": map[string]string{"label": "sre", "value": teamID},
+		"name": "  Site Reliability ",
+	})
+	if resp.Type != apps.CallResponseTypeOK {
+		t.Fatalf("expected the team to be renamed, got %+v", resp)
+	}
+	team, err := svc.repo.GetTeamByID(teamID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if team.Name != "Site Reliability" || team.Slug != "site-reliability" {
+		t.Errorf("expected the name to keep its case, got %+v", team)
+	}
+}
+
+func TestHandleCalendarRotateAndRevoke(t *testing.T) {
+	svc := newTestService(t)
+	server := newTestMattermost(t)
+	teamID, _ := newTestTeam(t, svc, "alice")
+	team := map[string]string{"label": "sre", "value": teamID}
+	token := func() string {
+		calendarToken, err := svc.repo.GetCalendarToken(teamID, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if calendarToken == nil {
+			return ""
+		}
+		return calendarToken.Token
+	}
+
+	if resp := callHandler(t, handleCalendarLink(svc, logger), server, "alice", map[string]interface{}{"team": team}); resp.Type != apps.CallResponseTypeOK {
+		t.Fatalf("expected a member to get the link of the team, got %+v", resp)
+	}
+	previous := token()
+	resp := callHandler(t, handleCalendarRotate(svc, logger), server, "owner", map[string]interface{}{"team": team})
+	if resp.Type != apps.CallResponseTypeOK {
+		t.Fatalf("expected the owner to rotate the link, got %+v", resp)
+	}
+	rotated := token()
+	if rotated == "" || rotated == previous || !strings.Contains(resp.Markdown.String(), rotated) {
+		t.Errorf("expected a new link, got %q after %q", resp.Markdown, previous)
+	}
+	if err := svc.WriteCalendar(&bytes.Buffer{}, previous); err != ErrCalendarNotFound {
+		t.Errorf("expected the previous link to stop working, got %v", err)
+	}
+
+	if resp := callHandler(t, handleCalendarRevoke(svc, logger), server, "owner", map[string]interface{}{"team": team}); resp.Type != apps.CallResponseTypeOK {
+		t.Fatalf("expected the owner to revoke the link, got %+v", resp)
+	}
+	if token() != "" {
+		t.Error("expected the token to be deleted")
+	}
+	if err := svc.WriteCalendar(&bytes.Buffer{}, rotated); err != ErrCalendarNotFound {
+		t.Errorf("expected the revoked link to stop working, got %v", err)
+	}
+}
+
+func TestHandleCancelGamedayWhenThePostFails(t *testing.T) {
+	svc := newTestService(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/api/v4/posts") {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"message": "unavailable"}`))
+			return
+		}
+		mattermostHandler()(w, r)
+	}))
+	t.Cleanup(server.Close)
+	_, gamedayID := newTestTeam(t, svc, "alice")
+	if err := svc.repo.UpdateGamedayPost(gamedayID, "channel", "post"); err != nil {
+		t.Fatal(err)
+	}
+
+	resp := callHandler(t, handleCancelGameDay(svc, logger), server, "owner", map[string]interface{}{"id": map[string]string{"value": gamedayID}, "reason": "no reason"})
+	if resp.Type != apps.CallResponseTypeOK {
+		t.Errorf("expected the cancellation to succeed when the post can't be refreshed, got %+v", resp)
+	}
+	g, err := svc.repo.GetGameday(gamedayID)
+	if err != nil || g == nil || g.State != GamedayCancelledState {
+		t.Errorf("expected the gameday to be cancelled, got %+v (%v)", g, err)
+	}
+}
+
+func TestHandleRSVPGameDayAlertsTheAdmins(t *testing.T) {
+	svc := newTestService(t)
+	server, recipients := newDirectMessagesRecorder(t)
+	teamID, gamedayID := newTestTeam(t, svc, "alice")
+	if err := svc.repo.CreateTeamAdmin(TeamAdmin{TeamID: teamID, UserID: "bob", Label: "bob"}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := &apps.Context{ActingUserID: "alice", MattermostSiteURL: server.URL}
+	ctx.BotAccessToken = "bot-token"
+	body, err := json.Marshal(apps.CallRequest{Call: apps.Call{State: map[string]interface{}{"id": gamedayID, "response": RSVPDeclined}}, Context: ctx})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	handleRSVPGameDay(svc, logger).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body)))
+	var resp apps.CallResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil || resp.Type != apps.CallResponseTypeOK {
+		t.Fatalf("expected the response to be saved, got %+v (%v)", resp, err)
+	}
+	if got, want := recipients(), []string{"owner", "bob"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the owner and the admins to be alerted, got %v want %v", got, want)
+	}
+}
+
+func TestHandleRemoveMember(t *testing.T) {
+	svc := newTestService(t)
+	server, recipients := newDirectMessagesRecorder(t)
+	teamID, gamedayID := newTestTeam(t, svc, "alice", "bob")
+
+	resp := callHandler(t, handleRemoveMember(svc, logger), server, "owner", map[string]interface{}{
+		"team":   map[string]string{"label": "sre", "value": teamID},
+		"member": map[string]string{"label": "alice", "value": "alice"},
+	})
+	if resp.Type != apps.CallResponseTypeOK {
+		t.Fatalf("expected the member to be removed, got %+v", resp)
+	}
+	if m, err := svc.repo.GetMember(teamID, "alice"); err != nil || m != nil {
+		t.Errorf("expected alice to be removed, got %+v (%v)", m, err)
+	}
+	_, nominees, err := svc.GetGameday(gamedayID)
+	if err != nil || len(nominees) != 1 || nominees[0].UserID != "bob" {
+		t.Errorf("expected bob to replace alice, got %v (%v)", nominees, err)
+	}
+	if got, want := recipients(), []string{"alice", "bob", "alice"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the replacement and the removal to be notified, got %v want %v", got, want)
+	}
+}
+
+func TestHandleCompleteGameDayWhenCancelled(t *testing.T) {
+	svc := newTestService(t)
+	server := newTestMattermost(t)
+	_, gamedayID := newTestTeam(t, svc, "alice")
+	values := map[string]interface{}{"id": map[string]string{"value": gamedayID}, "reason": "no reason"}
+
+	if resp := callHandler(t, handleCancelGameDay(svc, logger), server, "owner", values); resp.Type != apps.CallResponseTypeOK {
+		t.Fatalf("expected the gameday to be cancelled, got %+v", resp)
+	}
+	if resp := callHandler(t, handleCompleteGameDay(svc, logger), server, "owner", values); resp.Type != apps.CallResponseTypeError {
+		t.Errorf("expected a cancelled gameday not to be completed, got %+v", resp)
+	}
+	g, err := svc.repo.GetGameday(gamedayID)
+	if err != nil || g == nil || g.State != GamedayCancelledState {
+		t.Errorf("expected the gameday to stay cancelled, got %+v (%v)", g, err)
+	}
+}
+
+func TestWriteCalendarOfTeamWithoutGamedays(t *testing.T) {
+	svc := newTestService(t)
+	server := newTestMattermost(t)
+	teamID, err := svc.repo.CreateTeam("sre", "owner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp := callHandler(t, handleCalendarLink(svc, logger), server, "owner", map[string]interface{}{"team": map[string]string{"label": "sre", "value": teamID}}); resp.Type != apps.CallResponseTypeOK {
+		t.Fatalf("expected the owner to get the link of the team, got %+v", resp)
+	}
+	calendarToken, err := svc.repo.GetCalendarToken(teamID, "")
+	if err != nil || calendarToken == nil {
+		t.Fatal(err, calendarToken)
+	}
+
+	var buf bytes.Buffer
+	if err := svc.WriteCalendar(&buf, calendarToken.Token); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "X-WR-CALNAME:Chaos Gamedays: sre") {
+		t.Errorf("expected the feed to be named after the team, got %s", buf.String())
+	}
+}
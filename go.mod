@@ -12,6 +12,7 @@ require (
 	github.com/jmoiron/sqlx v1.3.4
 	github.com/lib/pq v1.10.0
 	github.com/mattermost/mattermost-plugin-apps v0.7.0
+	github.com/mattermost/mattermost-server/v5 v5.3.2-0.20210503144558-5c16de58a020
 	github.com/mattn/go-sqlite3 v2.0.3+incompatible
 	github.com/oklog/oklog v0.3.2
 	github.com/pborman/uuid v1.2.1
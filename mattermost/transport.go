@@ -1,22 +1,14 @@
 package mattermost
 
 import (
-	"fmt"
 	"io/fs"
 	"net/http"
-	"strings"
 
-	jwt "github.com/dgrijalva/jwt-go"
 	"github.com/gorilla/mux"
 	"github.com/mattermost/mattermost-app-chaosengine/transport"
 	"github.com/mattermost/mattermost-plugin-apps/apps"
-	"github.com/pkg/errors"
 )
 
-var ErrUnexpectedSignMethod = errors.New("unexpected signing method")
-var ErrMissingHeader = errors.Errorf("missing %s: Bearer header", apps.OutgoingAuthHeader)
-var ErrActingUserMismatch = errors.New("JWT claim doesn't match actingUserID in context")
-
 type requestHandler func(http.ResponseWriter, *http.Request, *apps.CallRequest)
 
 func AddRoutes(router *mux.Router, m *apps.Manifest, staticAssets fs.FS, secretToken string, localMode bool) {
@@ -36,7 +28,7 @@ func handleBindings(w http.ResponseWriter, r *http.Request, c *apps.CallRequest)
 		Label:       "chaos-engine",
 		Icon:        "icon.png",
 		Description: "Chaos engine will help teams to run Chaos Gamedays",
-		Hint:        "[configure gameday team]",
+		Hint:        "[configure gameday team template calendar stats import away profile subscribe unsubscribe subscriptions]",
 	}
 
 	configureCommand := &apps.Binding{
@@ -55,7 +47,7 @@ func handleBindings(w http.ResponseWriter, r *http.Request, c *apps.CallRequest)
 		Label:       "gameday",
 		Icon:        "icon.png",
 		Description: "Create and list GameDays",
-		Hint:        "[create list start]",
+		Hint:        "[create clone list archive start complete cancel attendance show reschedule renominate swap nominees]",
 		Bindings: []*apps.Binding{
 			{
 				Location: "create",
@@ -63,15 +55,51 @@ func handleBindings(w http.ResponseWriter, r *http.Request, c *apps.CallRequest)
 				Form: &apps.Form{
 					Fields: []*apps.Field{
 						{
-							Type:       "text",
-							Name:       "name",
-							Label:      "name",
-							IsRequired: true,
+							Type:        "text",
+							Name:        "name",
+							Label:       "name",
+							Description: "Required unless a template is selected",
+						},
+						{
+							Type:        "dynamic_select",
+							Name:        "team",
+							Label:       "team",
+							Description: "Required unless a template is selected",
+						},
+						{
+							Type:        "text",
+							Name:        "schedule_at",
+							Label:       "schedule_at",
+							Description: "Format [YYYY-DD-MM HH:MM:SS]",
+							IsRequired:  true,
+						},
+						{
+							Type:        "dynamic_select",
+							Name:        "template",
+							Label:       "template",
+							Description: "Gameday template to copy the configuration from",
+						},
+						{
+							Type:        "bool",
+							Name:        "dry_run",
+							Label:       "dry-run",
+							Description: "Preview the nominees without creating the gameday",
 						},
+					},
+				},
+				Call: &apps.Call{
+					Path: "/api/v1/gamedays/create",
+				},
+			},
+			{
+				Location: "clone",
+				Label:    "clone",
+				Form: &apps.Form{
+					Fields: []*apps.Field{
 						{
 							Type:       "dynamic_select",
-							Name:       "team",
-							Label:      "team",
+							Name:       "id",
+							Label:      "id",
 							IsRequired: true,
 						},
 						{
@@ -84,7 +112,7 @@ func handleBindings(w http.ResponseWriter, r *http.Request, c *apps.CallRequest)
 					},
 				},
 				Call: &apps.Call{
-					Path: "/api/v1/gamedays/create",
+					Path: "/api/v1/gamedays/clone",
 				},
 			},
 			{
@@ -95,6 +123,14 @@ func handleBindings(w http.ResponseWriter, r *http.Request, c *apps.CallRequest)
 					Path: "/api/v1/gamedays/list",
 				},
 			},
+			{
+				Location: "archive",
+				Label:    "archive",
+				Form:     &apps.Form{},
+				Call: &apps.Call{
+					Path: "/api/v1/gamedays/archive",
+				},
+			},
 			{
 				Location: "start",
 				Label:    "start",
@@ -123,6 +159,12 @@ func handleBindings(w http.ResponseWriter, r *http.Request, c *apps.CallRequest)
 							Label:      "id",
 							IsRequired: true,
 						},
+						{
+							Type:        "text",
+							Name:        "reason",
+							Label:       "reason",
+							Description: "Summary of the gameday",
+						},
 					},
 				},
 				Call: &apps.Call{
@@ -140,20 +182,182 @@ func handleBindings(w http.ResponseWriter, r *http.Request, c *apps.CallRequest)
 							Label:      "id",
 							IsRequired: true,
 						},
+						{
+							Type:        "text",
+							Name:        "reason",
+							Label:       "reason",
+							Description: "Reason of the cancellation",
+						},
 					},
 				},
 				Call: &apps.Call{
 					Path: "/api/v1/gamedays/cancel",
 				},
 			},
+			{
+				Location: "attendance",
+				Label:    "attendance",
+				Form: &apps.Form{
+					Fields: []*apps.Field{
+						{
+							Type:       "dynamic_select",
+							Name:       "id",
+							Label:      "id",
+							IsRequired: true,
+						},
+						{
+							Type:       "user",
+							Name:       "member",
+							Label:      "member",
+							IsRequired: true,
+						},
+					},
+				},
+				Call: &apps.Call{
+					Path: "/api/v1/gamedays/attendance",
+				},
+			},
+			{
+				Location: "show",
+				Label:    "show",
+				Form: &apps.Form{
+					Fields: []*apps.Field{
+						{
+							Type:       "dynamic_select",
+							Name:       "id",
+							Label:      "id",
+							IsRequired: true,
+						},
+					},
+				},
+				Call: &apps.Call{
+					Path: "/api/v1/gamedays/show",
+				},
+			},
+			{
+				Location:    "reschedule",
+				Label:       "reschedule",
+				Description: "Move a scheduled gameday to another time",
+				Form: &apps.Form{
+					Fields: []*apps.Field{
+						{
+							Type:       "dynamic_select",
+							Name:       "id",
+							Label:      "id",
+							IsRequired: true,
+						},
+						{
+							Type:        "text",
+							Name:        "schedule_at",
+							Label:       "schedule_at",
+							Description: "Format [YYYY-DD-MM HH:MM:SS]",
+							IsRequired:  true,
+						},
+					},
+				},
+				Call: &apps.Call{
+					Path: "/api/v1/gamedays/reschedule",
+				},
+			},
+			{
+				Location:    "renominate",
+				Label:       "renominate",
+				Description: "Draw a new member for a role of the gameday",
+				Form: &apps.Form{
+					Fields: []*apps.Field{
+						{
+							Type:       "dynamic_select",
+							Name:       "id",
+							Label:      "id",
+							IsRequired: true,
+						},
+						{
+							Type:       "dynamic_select",
+							Name:       "role",
+							Label:      "role",
+							IsRequired: true,
+						},
+						{
+							Type:        "user",
+							Name:        "member",
+							Label:       "member",
+							Description: "The nominee to replace when several members hold the role",
+						},
+					},
+				},
+				Call: &apps.Call{
+					Path: "/api/v1/gamedays/renominate",
+				},
+			},
+			{
+				Location:    "swap",
+				Label:       "swap",
+				Description: "Replace the nominee of a role with a volunteer, team admins only unless it is your own role",
+				Form: &apps.Form{
+					Fields: []*apps.Field{
+						{
+							Type:       "dynamic_select",
+							Name:       "id",
+							Label:      "id",
+							IsRequired: true,
+						},
+						{
+							Type:       "dynamic_select",
+							Name:       "role",
+							Label:      "role",
+							IsRequired: true,
+						},
+						{
+							Type:        "user",
+							Name:        "member",
+							Label:       "member",
+							Description: "The nominee to replace when several members hold the role",
+						},
+						{
+							Type:       "user",
+							Name:       "with",
+							Label:      "with",
+							IsRequired: true,
+						},
+					},
+				},
+				Call: &apps.Call{
+					Path: "/api/v1/gamedays/swap",
+				},
+			},
+			{
+				Location: "nominees",
+				Label:    "nominees",
+				Hint:     "[verify]",
+				Bindings: []*apps.Binding{
+					{
+						Location:    "verify",
+						Label:       "verify",
+						Description: "Replay the nomination of a gameday with its recorded seed",
+						Form: &apps.Form{
+							Fields: []*apps.Field{
+								{
+									Type:       "dynamic_select",
+									Name:       "id",
+									Label:      "id",
+									IsRequired: true,
+								},
+							},
+						},
+						Call: &apps.Call{
+							Path: "/api/v1/gamedays/nominees/verify",
+						},
+					},
+				},
+			},
 		},
 	}
 	teamCommand := &apps.Binding{
 		Location:    "team",
 		Label:       "team",
 		Icon:        "icon.png",
-		Description: "Create and list teams",
-		Hint:        "[create list]",
+		Description: "Create, list and configure teams",
+		Hint:        "[create list show configure rename delete link unlink sync admin member role exemption]",
 		Bindings: []*apps.Binding{
 			{
 				Location: "create",
@@ -167,10 +371,10 @@ func handleBindings(w http.ResponseWriter, r *http.Request, c *apps.CallRequest)
 							IsRequired: true,
 						},
 						{
-							Type:       "user",
-							Name:       "member",
-							Label:      "member",
-							IsRequired: true,
+							Type:        "text",
+							Name:        "members",
+							Label:       "members",
+							Description: "Usernames separated by spaces or commas, e.g. @alice @bob",
 						},
 					},
 				},
@@ -180,16 +384,898 @@ func handleBindings(w http.ResponseWriter, r *http.Request, c *apps.CallRequest)
 			}, {
 				Location: "list",
 				Label:    "list",
-				Form:     &apps.Form{},
+				Form: &apps.Form{
+					Fields: []*apps.Field{
+						{
+							Type:        "text",
+							Name:        "name",
+							Label:       "name",
+							Description: "Show the details of a single team",
+						},
+					},
+				},
 				Call: &apps.Call{
 					Path: "/api/v1/teams/list",
 				},
+			}, {
+				Location: "show",
+				Label:    "show",
+				Form: &apps.Form{
+					Fields: []*apps.Field{
+						{
+							Type:       "text",
+							Name:       "name",
+							Label:      "name",
+							IsRequired: true,
+						},
+					},
+				},
+				Call: &apps.Call{
+					Path: "/api/v1/teams/show",
+				},
+			}, {
+				Location:    "link",
+				Label:       "link",
+				Description: "Sync the members of a team from a channel, a user group or a Mattermost team, team admins only",
+				Form: &apps.Form{
+					Fields: []*apps.Field{
+						{
+							Type:       "dynamic_select",
+							Name:       "team",
+							Label:      "team",
+							IsRequired: true,
+						},
+						{
+							Type:        "channel",
+							Name:        "channel",
+							Label:       "channel",
+							Description: "The channel the members are synced from",
+						},
+						{
+							Type:        "text",
+							Name:        "group",
+							Label:       "group",
+							Description: "The user group the members are synced from",
+						},
+						{
+							Type:        "text",
+							Name:        "mattermost_team",
+							Label:       "mattermost_team",
+							Description: "The Mattermost team the members are synced from",
+						},
+					},
+				},
+				Call: &apps.Call{
+					Path: "/api/v1/teams/link",
+				},
+			}, {
+				Location:    "unlink",
+				Label:       "unlink",
+				Description: "Stop syncing the members of a team, team admins only",
+				Form: &apps.Form{
+					Fields: []*apps.Field{
+						{
+							Type:       "dynamic_select",
+							Name:       "team",
+							Label:      "team",
+							IsRequired: true,
+						},
+					},
+				},
+				Call: &apps.Call{
+					Path: "/api/v1/teams/unlink",
+				},
+			}, {
+				Location:    "sync",
+				Label:       "sync",
+				Description: "Sync the members of a team now, team admins only",
+				Form: &apps.Form{
+					Fields: []*apps.Field{
+						{
+							Type:       "text",
+							Name:       "name",
+							Label:      "name",
+							IsRequired: true,
+						},
+					},
+				},
+				Call: &apps.Call{
+					Path: "/api/v1/teams/sync",
+				},
+			}, {
+				Location: "configure",
+				Label:    "configure",
+				Form: &apps.Form{
+					Fields: []*apps.Field{
+						{
+							Type:       "dynamic_select",
+							Name:       "team",
+							Label:      "team",
+							IsRequired: true,
+						},
+						{
+							Type:        "static_select",
+							Name:        "strategy",
+							Label:       "strategy",
+							Description: "How the nominees of the gamedays are picked",
+							SelectStaticOptions: []apps.SelectOption{
+								{Label: "least-recent", Value: "least-recent"},
+								{Label: "round-robin", Value: "round-robin"},
+								{Label: "random", Value: "random"},
+								{Label: "weighted", Value: "weighted"},
+							},
+						},
+						{
+							Type:        "text",
+							Name:        "remind_after",
+							Label:       "remind_after",
+							Description: "Hours after the nomination to remind the nominees who didn't acknowledge, 24 by default",
+						},
+						{
+							Type:        "text",
+							Name:        "escalate_before",
+							Label:       "escalate_before",
+							Description: "Hours before the gameday to escalate the nominees who didn't acknowledge, 24 by default",
+						},
+						{
+							Type:        "static_select",
+							Name:        "escalation",
+							Label:       "escalation",
+							Description: "Alert the team admin or renominate, admin by default",
+							SelectStaticOptions: []apps.SelectOption{
+								{Label: "admin", Value: "admin"},
+								{Label: "renominate", Value: "renominate"},
+							},
+						},
+					},
+				},
+				Call: &apps.Call{
+					Path: "/api/v1/teams/configure",
+				},
+			}, {
+				Location:    "rename",
+				Label:       "rename",
+				Description: "Rename a team, team admins only",
+				Form: &apps.Form{
+					Fields: []*apps.Field{
+						{
+							Type:       "dynamic_select",
+							Name:       "team",
+							Label:      "team",
+							IsRequired: true,
+						},
+						{
+							Type:       "text",
+							Name:       "name",
+							Label:      "name",
+							IsRequired: true,
+						},
+					},
+				},
+				Call: &apps.Call{
+					Path: "/api/v1/teams/rename",
+				},
+			}, {
+				Location:    "delete",
+				Label:       "delete",
+				Description: "Delete a team with its gamedays, team admins only",
+				Form: &apps.Form{
+					Fields: []*apps.Field{
+						{
+							Type:       "dynamic_select",
+							Name:       "team",
+							Label:      "team",
+							IsRequired: true,
+						},
+						{
+							Type:        "bool",
+							Name:        "force",
+							Label:       "force",
+							Description: "Delete the team even when it has scheduled gamedays",
+						},
+					},
+				},
+				Call: &apps.Call{
+					Path: "/api/v1/teams/delete",
+				},
+			}, {
+				Location:    "admin",
+				Label:       "admin",
+				Description: "Manage the admins of a team, team admins only",
+				Hint:        "[add remove]",
+				Bindings: []*apps.Binding{
+					{
+						Location:    "add",
+						Label:       "add",
+						Description: "Make a user an admin of a team",
+						Form: &apps.Form{
+							Fields: []*apps.Field{
+								{
+									Type:       "dynamic_select",
+									Name:       "team",
+									Label:      "team",
+									IsRequired: true,
+								},
+								{
+									Type:       "user",
+									Name:       "member",
+									Label:      "member",
+									IsRequired: true,
+								},
+							},
+						},
+						Call: &apps.Call{
+							Path: "/api/v1/teams/admins/add",
+						},
+					},
+					{
+						Location:    "remove",
+						Label:       "remove",
+						Description: "Revoke the administration of a team from a user",
+						Form: &apps.Form{
+							Fields: []*apps.Field{
+								{
+									Type:       "dynamic_select",
+									Name:       "team",
+									Label:      "team",
+									IsRequired: true,
+								},
+								{
+									Type:       "user",
+									Name:       "member",
+									Label:      "member",
+									IsRequired: true,
+								},
+							},
+						},
+						Call: &apps.Call{
+							Path: "/api/v1/teams/admins/remove",
+						},
+					},
+				},
+			}, {
+				Location:    "member",
+				Label:       "member",
+				Description: "Manage the members of a team",
+				Hint:        "[add set remove]",
+				Bindings: []*apps.Binding{
+					{
+						Location:    "add",
+						Label:       "add",
+						Description: "Add one or more users to a team",
+						Form: &apps.Form{
+							Fields: []*apps.Field{
+								{
+									Type:       "dynamic_select",
+									Name:       "team",
+									Label:      "team",
+									IsRequired: true,
+								},
+								{
+									Type:        "text",
+									Name:        "members",
+									Label:       "members",
+									Description: "Usernames separated by spaces or commas, e.g. @alice @bob",
+									IsRequired:  true,
+								},
+							},
+						},
+						Call: &apps.Call{
+							Path: "/api/v1/teams/members/add",
+						},
+					},
+					{
+						Location:    "set",
+						Label:       "set",
+						Description: "Set the experience level and the skills of a member",
+						Form: &apps.Form{
+							Fields: []*apps.Field{
+								{
+									Type:       "dynamic_select",
+									Name:       "team",
+									Label:      "team",
+									IsRequired: true,
+								},
+								{
+									Type:       "user",
+									Name:       "member",
+									Label:      "member",
+									IsRequired: true,
+								},
+								{
+									Type:  "static_select",
+									Name:  "level",
+									Label: "level",
+									SelectStaticOptions: []apps.SelectOption{
+										{Label: "junior", Value: "junior"},
+										{Label: "intermediate", Value: "intermediate"},
+										{Label: "senior", Value: "senior"},
+									},
+								},
+								{
+									Type:        "text",
+									Name:        "skills",
+									Label:       "skills",
+									Description: "Comma separated skills, e.g. kubernetes,postgres",
+								},
+							},
+						},
+						Call: &apps.Call{
+							Path: "/api/v1/teams/members/set",
+						},
+					},
+					{
+						Location:    "remove",
+						Label:       "remove",
+						Description: "Remove a member from a team, team admins only",
+						Form: &apps.Form{
+							Fields: []*apps.Field{
+								{
+									Type:       "dynamic_select",
+									Name:       "team",
+									Label:      "team",
+									IsRequired: true,
+								},
+								{
+									Type:       "user",
+									Name:       "member",
+									Label:      "member",
+									IsRequired: true,
+								},
+							},
+						},
+						Call: &apps.Call{
+							Path: "/api/v1/teams/members/remove",
+						},
+					},
+				},
+			}, {
+				Location:    "role",
+				Label:       "role",
+				Description: "Add, list and remove the roles nominated on the gamedays of a team",
+				Hint:        "[add list remove]",
+				Bindings: []*apps.Binding{
+					{
+						Location:    "add",
+						Label:       "add",
+						Description: "Add a role to the team or update it",
+						Form: &apps.Form{
+							Fields: []*apps.Field{
+								{
+									Type:       "dynamic_select",
+									Name:       "team",
+									Label:      "team",
+									IsRequired: true,
+								},
+								{
+									Type:        "text",
+									Name:        "name",
+									Label:       "name",
+									Description: "Lowercase name of the role, e.g. scribe",
+									IsRequired:  true,
+								},
+								{
+									Type:        "text",
+									Name:        "label",
+									Label:       "label",
+									Description: "Displayed name of the role, e.g. Scribe",
+								},
+								{
+									Type:        "text",
+									Name:        "count",
+									Label:       "count",
+									Description: "Members to nominate for the role, 1 by default",
+								},
+								{
+									Type:        "text",
+									Name:        "rules",
+									Label:       "rules",
+									Description: "Space separated eligibility rules, e.g. level:senior cooldown:3",
+								},
+							},
+						},
+						Call: &apps.Call{
+							Path: "/api/v1/teams/roles/add",
+						},
+					},
+					{
+						Location: "list",
+						Label:    "list",
+						Form: &apps.Form{
+							Fields: []*apps.Field{
+								{
+									Type:       "dynamic_select",
+									Name:       "team",
+									Label:      "team",
+									IsRequired: true,
+								},
+							},
+						},
+						Call: &apps.Call{
+							Path: "/api/v1/teams/roles/list",
+						},
+					},
+					{
+						Location: "remove",
+						Label:    "remove",
+						Form: &apps.Form{
+							Fields: []*apps.Field{
+								{
+									Type:       "dynamic_select",
+									Name:       "team",
+									Label:      "team",
+									IsRequired: true,
+								},
+								{
+									Type:       "text",
+									Name:       "name",
+									Label:      "name",
+									IsRequired: true,
+								},
+							},
+						},
+						Call: &apps.Call{
+							Path: "/api/v1/teams/roles/remove",
+						},
+					},
+				},
+			}, {
+				Location:    "exemption",
+				Label:       "exemption",
+				Description: "Take members out of the rotation of a team until a date",
+				Hint:        "[add list remove]",
+				Bindings: []*apps.Binding{
+					{
+						Location:    "add",
+						Label:       "add",
+						Description: "Exempt a member from the nominations, team admins only",
+						Form: &apps.Form{
+							Fields: []*apps.Field{
+								{
+									Type:       "dynamic_select",
+									Name:       "team",
+									Label:      "team",
+									IsRequired: true,
+								},
+								{
+									Type:       "user",
+									Name:       "member",
+									Label:      "member",
+									IsRequired: true,
+								},
+								{
+									Type:        "text",
+									Name:        "reason",
+									Label:       "reason",
+									Description: "Why the member is exempted, e.g. parental leave",
+									IsRequired:  true,
+								},
+								{
+									Type:        "text",
+									Name:        "until",
+									Label:       "until",
+									Description: "Format [YYYY-DD-MM HH:MM:SS]",
+									IsRequired:  true,
+								},
+							},
+						},
+						Call: &apps.Call{
+							Path: "/api/v1/teams/exemptions/add",
+						},
+					},
+					{
+						Location: "list",
+						Label:    "list",
+						Form: &apps.Form{
+							Fields: []*apps.Field{
+								{
+									Type:       "dynamic_select",
+									Name:       "team",
+									Label:      "team",
+									IsRequired: true,
+								},
+							},
+						},
+						Call: &apps.Call{
+							Path: "/api/v1/teams/exemptions/list",
+						},
+					},
+					{
+						Location:    "remove",
+						Label:       "remove",
+						Description: "Put a member back in the rotation, team admins only",
+						Form: &apps.Form{
+							Fields: []*apps.Field{
+								{
+									Type:       "dynamic_select",
+									Name:       "team",
+									Label:      "team",
+									IsRequired: true,
+								},
+								{
+									Type:       "user",
+									Name:       "member",
+									Label:      "member",
+									IsRequired: true,
+								},
+							},
+						},
+						Call: &apps.Call{
+							Path: "/api/v1/teams/exemptions/remove",
+						},
+					},
+				},
 			},
 		},
 	}
 
+	templateCommand := &apps.Binding{
+		Location:    "template",
+		Label:       "template",
+		Icon:        "icon.png",
+		Description: "Create, edit and list gameday templates",
+		Hint:        "[create edit list]",
+		Bindings: []*apps.Binding{
+			{
+				Location: "create",
+				Label:    "create",
+				Form: &apps.Form{
+					Fields: []*apps.Field{
+						{
+							Type:       "text",
+							Name:       "name",
+							Label:      "name",
+							IsRequired: true,
+						},
+						{
+							Type:        "text",
+							Name:        "title",
+							Label:       "title",
+							Description: "Title pattern, `{team}` and `{date}` are replaced",
+							IsRequired:  true,
+						},
+						{
+							Type:       "dynamic_select",
+							Name:       "team",
+							Label:      "team",
+							IsRequired: true,
+						},
+						{
+							Type:  "text",
+							Name:  "scenarios",
+							Label: "scenarios",
+						},
+						{
+							Type:  "text",
+							Name:  "checklist",
+							Label: "checklist",
+						},
+						{
+							Type:        "text",
+							Name:        "duration",
+							Label:       "duration",
+							Description: "Duration in minutes, 60 by default",
+						},
+						{
+							Type:        "text",
+							Name:        "roles",
+							Label:       "roles",
+							Description: "Comma separated list of [mod,oncall], all by default",
+						},
+					},
+				},
+				Call: &apps.Call{
+					Path: "/api/v1/templates/create",
+				},
+			},
+			{
+				Location: "edit",
+				Label:    "edit",
+				Form: &apps.Form{
+					Fields: []*apps.Field{
+						{
+							Type:       "text",
+							Name:       "name",
+							Label:      "name",
+							IsRequired: true,
+						},
+						{
+							Type:        "text",
+							Name:        "title",
+							Label:       "title",
+							Description: "Title pattern, `{team}` and `{date}` are replaced",
+						},
+						{
+							Type:  "dynamic_select",
+							Name:  "team",
+							Label: "team",
+						},
+						{
+							Type:  "text",
+							Name:  "scenarios",
+							Label: "scenarios",
+						},
+						{
+							Type:  "text",
+							Name:  "checklist",
+							Label: "checklist",
+						},
+						{
+							Type:        "text",
+							Name:        "duration",
+							Label:       "duration",
+							Description: "Duration in minutes, 60 by default",
+						},
+						{
+							Type:        "text",
+							Name:        "roles",
+							Label:       "roles",
+							Description: "Comma separated list of [mod,oncall], all by default",
+						},
+					},
+				},
+				Call: &apps.Call{
+					Path: "/api/v1/templates/edit",
+				},
+			},
+			{
+				Location: "list",
+				Label:    "list",
+				Form:     &apps.Form{},
+				Call: &apps.Call{
+					Path: "/api/v1/templates/list",
+				},
+			},
+		},
+	}
+	calendarCommand := &apps.Binding{
+		Location:    "calendar",
+		Label:       "calendar",
+		Icon:        "icon.png",
+		Description: "iCalendar feeds of the gamedays",
+		Hint:        "[link rotate revoke]",
+		Bindings: []*apps.Binding{
+			{
+				Location: "link",
+				Label:    "link",
+				Form: &apps.Form{
+					Fields: []*apps.Field{
+						{
+							Type:        "dynamic_select",
+							Name:        "team",
+							Label:       "team",
+							Description: "The calendar of the team, your own calendar by default",
+						},
+					},
+				},
+				Call: &apps.Call{
+					Path: "/api/v1/calendar/link",
+				},
+			},
+			{
+				Location:    "rotate",
+				Label:       "rotate",
+				Description: "Replace the link of the calendar, the previous link stops working",
+				Form: &apps.Form{
+					Fields: []*apps.Field{
+						{
+							Type:        "dynamic_select",
+							Name:        "team",
+							Label:       "team",
+							Description: "The calendar of the team, your own calendar by default",
+						},
+					},
+				},
+				Call: &apps.Call{
+					Path: "/api/v1/calendar/rotate",
+				},
+			},
+			{
+				Location:    "revoke",
+				Label:       "revoke",
+				Description: "Revoke the link of the calendar, `calendar link` creates a new one",
+				Form: &apps.Form{
+					Fields: []*apps.Field{
+						{
+							Type:        "dynamic_select",
+							Name:        "team",
+							Label:       "team",
+							Description: "The calendar of the team, your own calendar by default",
+						},
+					},
+				},
+				Call: &apps.Call{
+					Path: "/api/v1/calendar/revoke",
+				},
+			},
+		},
+	}
+	statsCommand := &apps.Binding{
+		Location:    "stats",
+		Label:       "stats",
+		Icon:        "icon.png",
+		Description: "Statistics of the gamedays",
+		Hint:        "[nominations]",
+		Bindings: []*apps.Binding{
+			{
+				Location:    "nominations",
+				Label:       "nominations",
+				Description: "Nominations of the members of a team and the fairness of the rotation",
+				Form: &apps.Form{
+					Fields: []*apps.Field{
+						{
+							Type:       "dynamic_select",
+							Name:       "team",
+							Label:      "team",
+							IsRequired: true,
+						},
+					},
+				},
+				Call: &apps.Call{
+					Path: "/api/v1/stats/nominations",
+				},
+			},
+		},
+	}
+	importCommand := &apps.Binding{
+		Location:    "import",
+		Label:       "import",
+		Icon:        "icon.png",
+		Description: "Import teams, members and gamedays from CSV or iCalendar",
+		Form: &apps.Form{
+			Fields: []*apps.Field{
+				{
+					Type:       "static_select",
+					Name:       "format",
+					Label:      "format",
+					IsRequired: true,
+					SelectStaticOptions: []apps.SelectOption{
+						{Label: "CSV", Value: "csv"},
+						{Label: "iCalendar", Value: "ics"},
+					},
+				},
+				{
+					Type:        "text",
+					TextSubtype: "textarea",
+					Name:        "content",
+					Label:       "content",
+					Description: "CSV rows of team, member, title, date or the content of an .ics file",
+					IsRequired:  true,
+				},
+				{
+					Type:        "bool",
+					Name:        "dry_run",
+					Label:       "dry-run",
+					Description: "Report what would be created without creating it",
+				},
+			},
+		},
+		Call: &apps.Call{
+			Path: "/api/v1/import",
+		},
+	}
+	awayCommand := &apps.Binding{
+		Location:    "away",
+		Label:       "away",
+		Icon:        "icon.png",
+		Description: "Register a period when you can't be nominated",
+		Form: &apps.Form{
+			Fields: []*apps.Field{
+				{
+					Type:        "text",
+					Name:        "from",
+					Label:       "from",
+					Description: "Format [YYYY-DD-MM HH:MM:SS]",
+					IsRequired:  true,
+				},
+				{
+					Type:        "text",
+					Name:        "to",
+					Label:       "to",
+					Description: "Format [YYYY-DD-MM HH:MM:SS]",
+					IsRequired:  true,
+				},
+			},
+		},
+		Call: &apps.Call{
+			Path: "/api/v1/away",
+		},
+	}
+	profileCommand := &apps.Binding{
+		Location:    "profile",
+		Label:       "profile",
+		Icon:        "icon.png",
+		Description: "Show or update your timezone and notifications in every team",
+		Form: &apps.Form{
+			Fields: []*apps.Field{
+				{
+					Type:        "text",
+					Name:        "timezone",
+					Label:       "timezone",
+					Description: "IANA timezone, e.g. Europe/Paris",
+				},
+				{
+					Type:        "static_select",
+					Name:        "notifications",
+					Label:       "notifications",
+					Description: "The direct messages you receive, only the ones about your nominations or all",
+					SelectStaticOptions: []apps.SelectOption{
+						{Label: "all", Value: "all"},
+						{Label: "nominations", Value: "nominations"},
+					},
+				},
+			},
+		},
+		Call: &apps.Call{
+			Path: "/api/v1/profile",
+		},
+	}
+	subscribeCommand := &apps.Binding{
+		Location:    "subscribe",
+		Label:       "subscribe",
+		Icon:        "icon.png",
+		Description: "Subscribe the channel to the gameday events of a team",
+		Form: &apps.Form{
+			Fields: []*apps.Field{
+				{
+					Type:       "dynamic_select",
+					Name:       "team",
+					Label:      "team",
+					IsRequired: true,
+				},
+				{
+					Type:        "text",
+					Name:        "events",
+					Label:       "events",
+					Description: "Comma separated list of [created,started,completed,cancelled], all by default",
+				},
+			},
+		},
+		Call: &apps.Call{
+			Path: "/api/v1/subscriptions/create",
+		},
+	}
+	unsubscribeCommand := &apps.Binding{
+		Location:    "unsubscribe",
+		Label:       "unsubscribe",
+		Icon:        "icon.png",
+		Description: "Unsubscribe the channel from the gameday events of a team",
+		Form: &apps.Form{
+			Fields: []*apps.Field{
+				{
+					Type:       "dynamic_select",
+					Name:       "team",
+					Label:      "team",
+					IsRequired: true,
+				},
+			},
+		},
+		Call: &apps.Call{
+			Path: "/api/v1/subscriptions/delete",
+		},
+	}
+	subscriptionsCommand := &apps.Binding{
+		Location:    "subscriptions",
+		Label:       "subscriptions",
+		Icon:        "icon.png",
+		Description: "List the subscriptions of the channel",
+		Form:        &apps.Form{},
+		Call: &apps.Call{
+			Path: "/api/v1/subscriptions/list",
+		},
+	}
+
 	baseCommand.Bindings = append(baseCommand.Bindings, gamedayCommand)
 	baseCommand.Bindings = append(baseCommand.Bindings, teamCommand)
+	baseCommand.Bindings = append(baseCommand.Bindings, templateCommand)
+	baseCommand.Bindings = append(baseCommand.Bindings, calendarCommand)
+	baseCommand.Bindings = append(baseCommand.Bindings, statsCommand)
+	baseCommand.Bindings = append(baseCommand.Bindings, importCommand)
+	baseCommand.Bindings = append(baseCommand.Bindings, awayCommand)
+	baseCommand.Bindings = append(baseCommand.Bindings, profileCommand)
+	baseCommand.Bindings = append(baseCommand.Bindings, subscribeCommand)
+	baseCommand.Bindings = append(baseCommand.Bindings, unsubscribeCommand)
+	baseCommand.Bindings = append(baseCommand.Bindings, subscriptionsCommand)
 	baseCommand.Bindings = append(baseCommand.Bindings, configureCommand)
 
 	commands := &apps.Binding{
@@ -217,14 +1303,14 @@ func decodeRequest(f requestHandler, secretToken string, localMode bool) http.Ha
 		}
 
 		if localMode {
-			claims, err := checkJWT(r, secretToken)
+			claims, err := transport.CheckJWT(r, secretToken)
 			if err != nil {
 				transport.WriteBadRequestError(rw, err)
 				return
 			}
 
 			if data.Context.ActingUserID != "" && data.Context.ActingUserID != claims.ActingUserID {
-				transport.WriteBadRequestError(rw, ErrActingUserMismatch)
+				transport.WriteBadRequestError(rw, transport.ErrActingUserMismatch)
 				return
 			}
 		}
@@ -232,24 +1318,3 @@ func decodeRequest(f requestHandler, secretToken string, localMode bool) http.Ha
 		f(rw, r, nil)
 	}
 }
-func checkJWT(req *http.Request, secretToken string) (*apps.JWTClaims, error) {
-	authValue := req.Header.Get(apps.OutgoingAuthHeader)
-	if !strings.HasPrefix(authValue, "Bearer ") {
-		return nil, ErrMissingHeader
-	}
-
-	jwtoken := strings.TrimPrefix(authValue, "Bearer ")
-	claims := apps.JWTClaims{}
-	_, err := jwt.ParseWithClaims(jwtoken, &claims, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("%w: %v", ErrUnexpectedSignMethod, token.Header["alg"])
-		}
-		return []byte(secretToken), nil
-	})
-
-	if err != nil {
-		return nil, err
-	}
-
-	return &claims, nil
-}
@@ -1,6 +1,12 @@
 package store
 
-import "github.com/blang/semver"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/jmoiron/sqlx"
+)
 
 type migration struct {
 	fromVersion   semver.Version
@@ -107,4 +113,545 @@ var migrations = []migration{
 		}
 		return nil
 	}},
+	{semver.MustParse("0.1.0"), semver.MustParse("0.2.0"), func(e execer) error {
+		// keep track of the interactive post of each gameday so it can be
+		// updated in place whenever the state changes
+		_, err := e.Exec(`
+			ALTER TABLE gameday ADD COLUMN channel_id VARCHAR(26) NOT NULL DEFAULT '';
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = e.Exec(`
+			ALTER TABLE gameday ADD COLUMN post_id VARCHAR(26) NOT NULL DEFAULT '';
+		`)
+		if err != nil {
+			return err
+		}
+		return nil
+	}},
+	{semver.MustParse("0.2.0"), semver.MustParse("0.3.0"), func(e execer) error {
+		_, err := e.Exec(`
+			CREATE TABLE subscription (
+				id CHAR(26) PRIMARY KEY,
+				team_id CHAR(26) NOT NULL,
+				channel_id VARCHAR(26) NOT NULL,
+				events VARCHAR(256) NOT NULL,
+				created_at BIGINT NOT NULL,
+				updated_at BIGINT NOT NULL
+			);
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = e.Exec(`
+			CREATE UNIQUE INDEX subscription_team_channel ON subscription (team_id, channel_id);
+		`)
+		if err != nil {
+			return err
+		}
+		return nil
+	}},
+	{semver.MustParse("0.3.0"), semver.MustParse("0.4.0"), func(e execer) error {
+		_, err := e.Exec(`
+			ALTER TABLE team ADD COLUMN owner_id VARCHAR(26) NOT NULL DEFAULT '';
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = e.Exec(`
+			CREATE TABLE gameday_rsvp (
+				id CHAR(26) PRIMARY KEY,
+				gameday_id CHAR(26) NOT NULL,
+				member_id CHAR(26) NOT NULL,
+				response VARCHAR(16) NOT NULL,
+				attended BOOLEAN DEFAULT FALSE,
+				created_at BIGINT NOT NULL,
+				updated_at BIGINT NOT NULL
+			);
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = e.Exec(`
+			CREATE UNIQUE INDEX gameday_rsvp_member ON gameday_rsvp (gameday_id, member_id);
+		`)
+		if err != nil {
+			return err
+		}
+		return nil
+	}},
+	{semver.MustParse("0.4.0"), semver.MustParse("0.5.0"), func(e execer) error {
+		// the reason a gameday was cancelled or the summary of a completed one
+		_, err := e.Exec(`
+			ALTER TABLE gameday ADD COLUMN reason VARCHAR(1024) NOT NULL DEFAULT '';
+		`)
+		if err != nil {
+			return err
+		}
+		return nil
+	}},
+	{semver.MustParse("0.5.0"), semver.MustParse("0.6.0"), func(e execer) error {
+		// the configuration of a gameday which can be copied by templates
+		// and clones
+		_, err := e.Exec(`
+			ALTER TABLE gameday ADD COLUMN scenarios VARCHAR(1024) NOT NULL DEFAULT '';
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = e.Exec(`
+			ALTER TABLE gameday ADD COLUMN checklist VARCHAR(1024) NOT NULL DEFAULT '';
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = e.Exec(`
+			ALTER TABLE gameday ADD COLUMN duration BIGINT NOT NULL DEFAULT 60;
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = e.Exec(`
+			ALTER TABLE gameday ADD COLUMN roles VARCHAR(256) NOT NULL DEFAULT 'mod,oncall';
+		`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`
+			CREATE TABLE gameday_template (
+				id CHAR(26) PRIMARY KEY,
+				name VARCHAR(32) NOT NULL,
+				title VARCHAR(64) NOT NULL,
+				team_id CHAR(26) NOT NULL,
+				scenarios VARCHAR(1024) NOT NULL,
+				checklist VARCHAR(1024) NOT NULL,
+				duration BIGINT NOT NULL,
+				roles VARCHAR(256) NOT NULL,
+				created_at BIGINT NOT NULL,
+				updated_at BIGINT NOT NULL
+			);
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = e.Exec(`
+			CREATE UNIQUE INDEX gameday_template_name ON gameday_template (name);
+		`)
+		if err != nil {
+			return err
+		}
+		return nil
+	}},
+	{semver.MustParse("0.6.0"), semver.MustParse("0.7.0"), func(e execer) error {
+		// the revision of the gameday for the calendar feeds
+		_, err := e.Exec(`
+			ALTER TABLE gameday ADD COLUMN sequence INTEGER NOT NULL DEFAULT 0;
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = e.Exec(`
+			CREATE TABLE calendar_token (
+				id CHAR(26) PRIMARY KEY,
+				token VARCHAR(64) NOT NULL,
+				team_id VARCHAR(26) NOT NULL,
+				user_id VARCHAR(26) NOT NULL,
+				created_at BIGINT NOT NULL
+			);
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = e.Exec(`
+			CREATE UNIQUE INDEX calendar_token_token ON calendar_token (token);
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = e.Exec(`
+			CREATE UNIQUE INDEX calendar_token_team_user ON calendar_token (team_id, user_id);
+		`)
+		if err != nil {
+			return err
+		}
+		return nil
+	}},
+	{semver.MustParse("0.7.0"), semver.MustParse("0.8.0"), func(e execer) error {
+		_, err := e.Exec(`
+			ALTER TABLE team ADD COLUMN nomination_strategy VARCHAR(32) NOT NULL DEFAULT 'least-recent';
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = e.Exec(`
+			ALTER TABLE gameday_nominee ADD COLUMN strategy VARCHAR(32) NOT NULL DEFAULT '';
+		`)
+		if err != nil {
+			return err
+		}
+		return nil
+	}},
+	{semver.MustParse("0.8.0"), semver.MustParse("0.9.0"), func(e execer) error {
+		// the seed of the random source which nominated the members
+		_, err := e.Exec(`
+			ALTER TABLE gameday ADD COLUMN nomination_seed BIGINT NOT NULL DEFAULT 0;
+		`)
+		if err != nil {
+			return err
+		}
+		return nil
+	}},
+	{semver.MustParse("0.9.0"), semver.MustParse("0.10.0"), func(e execer) error {
+		_, err := e.Exec(`
+			CREATE TABLE gameday_history (
+				id CHAR(26) PRIMARY KEY,
+				gameday_id CHAR(26) NOT NULL,
+				user_id VARCHAR(26) NOT NULL,
+				action VARCHAR(32) NOT NULL,
+				message VARCHAR(1024) NOT NULL,
+				created_at BIGINT NOT NULL
+			);
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = e.Exec(`
+			CREATE INDEX gameday_history_gameday_id ON gameday_history (gameday_id);
+		`)
+		if err != nil {
+			return err
+		}
+		return nil
+	}},
+	{semver.MustParse("0.10.0"), semver.MustParse("0.11.0"), func(e execer) error {
+		_, err := e.Exec(`
+			CREATE TABLE member_away (
+				id CHAR(26) PRIMARY KEY,
+				user_id VARCHAR(26) NOT NULL,
+				starts_at BIGINT NOT NULL,
+				ends_at BIGINT NOT NULL,
+				created_at BIGINT NOT NULL
+			);
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = e.Exec(`
+			CREATE INDEX member_away_user_id ON member_away (user_id);
+		`)
+		if err != nil {
+			return err
+		}
+		return nil
+	}},
+	{semver.MustParse("0.11.0"), semver.MustParse("0.12.0"), func(e execer) error {
+		_, err := e.Exec(`
+			CREATE TABLE team_role (
+				id CHAR(26) PRIMARY KEY,
+				team_id CHAR(26) NOT NULL,
+				name VARCHAR(32) NOT NULL,
+				label VARCHAR(64) NOT NULL,
+				count INTEGER NOT NULL DEFAULT 1,
+				rules VARCHAR(256) NOT NULL DEFAULT '',
+				created_at BIGINT NOT NULL,
+				updated_at BIGINT NOT NULL
+			);
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = e.Exec(`
+			CREATE UNIQUE INDEX team_role_name ON team_role (team_id, name);
+		`)
+		if err != nil {
+			return err
+		}
+
+		// the existing teams keep the Master of Disaster and On-Call roles
+		rows, err := e.Query(`SELECT id, created_at FROM team;`)
+		if err != nil {
+			return err
+		}
+		teams := map[string]int64{}
+		for rows.Next() {
+			var id string
+			var createdAt int64
+			if err = rows.Scan(&id, &createdAt); err != nil {
+				rows.Close()
+				return err
+			}
+			teams[id] = createdAt
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return err
+		}
+		for id, createdAt := range teams {
+			_, err = e.Exec(sqlx.Rebind(sqlx.BindType(e.DriverName()), `
+				INSERT INTO team_role (id, team_id, name, label, count, rules, created_at, updated_at)
+				VALUES (?, ?, 'mod', 'Master of Disaster', 1, '', ?, 0), (?, ?, 'oncall', 'On-Call', 1, '', ?, 0);
+			`), NewID(), id, createdAt, NewID(), id, createdAt)
+			if err != nil {
+				return err
+			}
+		}
+
+		// the nominee booleans are replaced by the name of the role, the table is
+		// recreated since sqlite can't drop columns
+		_, err = e.Exec(`
+			CREATE TABLE gameday_nominee_role (
+				id CHAR(26) PRIMARY KEY,
+				gameday_id CHAR(26) NOT NULL,
+				member_id CHAR(26) NOT NULL,
+				role VARCHAR(32) NOT NULL DEFAULT '',
+				strategy VARCHAR(32) NOT NULL DEFAULT '',
+				created_at BIGINT NOT NULL,
+				updated_at BIGINT NOT NULL
+			);
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = e.Exec(`
+			INSERT INTO gameday_nominee_role (id, gameday_id, member_id, role, strategy, created_at, updated_at)
+			SELECT id, gameday_id, member_id,
+				CASE WHEN is_mod THEN 'mod' WHEN is_on_call THEN 'oncall' ELSE '' END,
+				strategy, created_at, updated_at
+			FROM gameday_nominee;
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = e.Exec(`
+			DROP TABLE gameday_nominee;
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = e.Exec(`
+			ALTER TABLE gameday_nominee_role RENAME TO gameday_nominee;
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = e.Exec(`
+			CREATE UNIQUE INDEX gameday_nominee_member ON gameday_nominee (gameday_id, member_id);
+		`)
+		if err != nil {
+			return err
+		}
+		return nil
+	}},
+	{semver.MustParse("0.12.0"), semver.MustParse("0.13.0"), func(e execer) error {
+		_, err := e.Exec(`
+			ALTER TABLE team_member ADD COLUMN level VARCHAR(16) NOT NULL DEFAULT '';
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = e.Exec(`
+			ALTER TABLE team_member ADD COLUMN skills VARCHAR(256) NOT NULL DEFAULT '';
+		`)
+		if err != nil {
+			return err
+		}
+		// why the member was picked for the role
+		_, err = e.Exec(`
+			ALTER TABLE gameday_nominee ADD COLUMN reason VARCHAR(1024) NOT NULL DEFAULT '';
+		`)
+		if err != nil {
+			return err
+		}
+		return nil
+	}},
+	{semver.MustParse("0.13.0"), semver.MustParse("0.14.0"), func(e execer) error {
+		_, err := e.Exec(`
+			CREATE TABLE member_exemption (
+				id CHAR(26) PRIMARY KEY,
+				member_id CHAR(26) NOT NULL,
+				reason VARCHAR(256) NOT NULL,
+				expires_at BIGINT NOT NULL,
+				created_by VARCHAR(26) NOT NULL,
+				created_at BIGINT NOT NULL
+			);
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = e.Exec(`
+			CREATE INDEX member_exemption_member_id ON member_exemption (member_id);
+		`)
+		if err != nil {
+			return err
+		}
+		return nil
+	}},
+	{semver.MustParse("0.14.0"), semver.MustParse("0.15.0"), func(e execer) error {
+		// how the nominees who don't acknowledge their role are chased, in hours
+		_, err := e.Exec(`
+			ALTER TABLE team ADD COLUMN ack_remind_after INTEGER NOT NULL DEFAULT 24;
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = e.Exec(`
+			ALTER TABLE team ADD COLUMN ack_escalate_before INTEGER NOT NULL DEFAULT 24;
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = e.Exec(`
+			ALTER TABLE team ADD COLUMN ack_escalation VARCHAR(16) NOT NULL DEFAULT 'admin';
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = e.Exec(`
+			ALTER TABLE gameday_nominee ADD COLUMN acknowledged_at BIGINT NOT NULL DEFAULT 0;
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = e.Exec(`
+			ALTER TABLE gameday_nominee ADD COLUMN reminded_at BIGINT NOT NULL DEFAULT 0;
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = e.Exec(`
+			ALTER TABLE gameday_nominee ADD COLUMN escalated_at BIGINT NOT NULL DEFAULT 0;
+		`)
+		if err != nil {
+			return err
+		}
+		return nil
+	}},
+	{semver.MustParse("0.15.0"), semver.MustParse("0.16.0"), func(e execer) error {
+		// the teams are identified by the normalized slug of their name
+		_, err := e.Exec(`
+			ALTER TABLE team ADD COLUMN slug VARCHAR(64) NOT NULL DEFAULT '';
+		`)
+		if err != nil {
+			return err
+		}
+		rows, err := e.Query(`SELECT id, name FROM team ORDER BY created_at, id;`)
+		if err != nil {
+			return err
+		}
+		var ids, slugs []string
+		taken := map[string]bool{}
+		for rows.Next() {
+			var id, name string
+			if err = rows.Scan(&id, &name); err != nil {
+				rows.Close()
+				return err
+			}
+			// the teams which only differed by case or spacing get a suffix
+			base := strings.Join(strings.Fields(strings.ToLower(name)), "-")
+			slug := base
+			for i := 2; taken[slug]; i++ {
+				slug = fmt.Sprintf("%s-%d", base, i)
+			}
+			taken[slug] = true
+			ids = append(ids, id)
+			slugs = append(slugs, slug)
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return err
+		}
+		for i, id := range ids {
+			_, err = e.Exec(sqlx.Rebind(sqlx.BindType(e.DriverName()), `
+				UPDATE team SET slug = ? WHERE id = ?;
+			`), slugs[i], id)
+			if err != nil {
+				return err
+			}
+		}
+		_, err = e.Exec(`
+			CREATE UNIQUE INDEX team_slug ON team (slug);
+		`)
+		if err != nil {
+			return err
+		}
+		return nil
+	}},
+	{semver.MustParse("0.16.0"), semver.MustParse("0.17.0"), func(e execer) error {
+		// the channel, group or Mattermost team the members are synced from
+		_, err := e.Exec(`
+			ALTER TABLE team ADD COLUMN sync_source VARCHAR(16) NOT NULL DEFAULT '';
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = e.Exec(`
+			ALTER TABLE team ADD COLUMN sync_source_id VARCHAR(26) NOT NULL DEFAULT '';
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = e.Exec(`
+			ALTER TABLE team ADD COLUMN sync_source_name VARCHAR(64) NOT NULL DEFAULT '';
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = e.Exec(`
+			ALTER TABLE team ADD COLUMN synced_at BIGINT NOT NULL DEFAULT 0;
+		`)
+		if err != nil {
+			return err
+		}
+		return nil
+	}},
+	{semver.MustParse("0.17.0"), semver.MustParse("0.18.0"), func(e execer) error {
+		// the admins of a team besides its owner
+		_, err := e.Exec(`
+			CREATE TABLE team_admin (
+				id CHAR(26) PRIMARY KEY,
+				team_id CHAR(26) NOT NULL,
+				user_id VARCHAR(26) NOT NULL,
+				label VARCHAR(64) NOT NULL,
+				created_by VARCHAR(26) NOT NULL,
+				created_at BIGINT NOT NULL
+			);
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = e.Exec(`
+			CREATE UNIQUE INDEX team_admin_team_id_user_id ON team_admin (team_id, user_id);
+		`)
+		if err != nil {
+			return err
+		}
+		return nil
+	}},
+	{semver.MustParse("0.18.0"), semver.MustParse("0.19.0"), func(e execer) error {
+		// the timezone and the notification preference of the member profiles
+		_, err := e.Exec(`
+			ALTER TABLE team_member ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT '';
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = e.Exec(`
+			ALTER TABLE team_member ADD COLUMN notifications VARCHAR(16) NOT NULL DEFAULT 'all';
+		`)
+		if err != nil {
+			return err
+		}
+		return nil
+	}},
+	{semver.MustParse("0.19.0"), semver.MustParse("0.20.0"), func(e execer) error {
+		_, err := e.Exec(`
+			ALTER TABLE gameday ADD COLUMN nomination_snapshot TEXT NOT NULL DEFAULT '';
+		`)
+		if err != nil {
+			return err
+		}
+		return nil
+	}},
 }
@@ -0,0 +1,39 @@
+package transport
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/pkg/errors"
+)
+
+var ErrUnexpectedSignMethod = errors.New("unexpected signing method")
+var ErrMissingHeader = errors.Errorf("missing %s: Bearer header", apps.OutgoingAuthHeader)
+var ErrActingUserMismatch = errors.New("JWT claim doesn't match actingUserID in context")
+
+// CheckJWT returns the claims of the JWT Mattermost signed the call with,
+// an error when the JWT is missing, expired or not signed with the secret
+func CheckJWT(req *http.Request, secretToken string) (*apps.JWTClaims, error) {
+	authValue := req.Header.Get(apps.OutgoingAuthHeader)
+	if !strings.HasPrefix(authValue, "Bearer ") {
+		return nil, ErrMissingHeader
+	}
+
+	jwtoken := strings.TrimPrefix(authValue, "Bearer ")
+	claims := apps.JWTClaims{}
+	_, err := jwt.ParseWithClaims(jwtoken, &claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("%w: %v", ErrUnexpectedSignMethod, token.Header["alg"])
+		}
+		return []byte(secretToken), nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &claims, nil
+}
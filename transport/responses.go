@@ -12,6 +12,16 @@ func WriteBadRequestError(w http.ResponseWriter, err error) {
 	WriteJSON(w, newCallErrorResponse(fmt.Sprintf("Invalid request. Error: %s", err.Error())))
 }
 
+func WriteNotPermittedError(w http.ResponseWriter, err error) {
+	WriteJSON(w, newCallErrorResponse(fmt.Sprintf("Not permitted: %s", err.Error())))
+}
+
+func WriteUnauthorizedError(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnauthorized)
+	_ = json.NewEncoder(w).Encode(newCallErrorResponse(fmt.Sprintf("Unauthorized: %s", err.Error())))
+}
+
 func newCallErrorResponse(message string) apps.CallResponse {
 	return apps.CallResponse{
 		Type:      apps.CallResponseTypeError,